@@ -29,6 +29,12 @@ type Node struct {
 	markerTests tests[Node]
 	Children    []*Node
 	Sniffs      map[string]*SniffResult `yaml:"-"`
+	// every content type that matched the data, in the order they were sniffed,
+	// which is only recorded with WithAllContentTypes
+	ContentTypes []SniffResult `yaml:"-"`
+	// the group the node was made from, used for decoding
+	// the properties when searching
+	source *groupSource
 }
 
 // Nodes are the different nodes in the Abstract syntax tree
@@ -109,6 +115,11 @@ type PartitionNode struct {
 	Tests              tests[PartitionNode]
 	markerTests        tests[PartitionNode]
 	PartitionPos       int
+	// the indexes of the essence and metadata,
+	// used when searching the partition
+	essenceIndex, metadataIndex *nodeIndex
+	// the metadata nodes by their InstanceUID bytes
+	instances map[string]*Node
 }
 
 // Position contains the start and end position
@@ -185,6 +196,18 @@ type PartitionProperties struct {
 	PartitionType  string
 	Primer         map[string]string
 	EssenceOrder   []string
+	// the open/closed and complete/incomplete status of the partition
+	Status string
+	// the UL and register symbol of the operational pattern
+	OperationalPattern, OperationalPatternName string
+	EssenceContainers                          []string
+	// the stream IDs of the partition contents
+	BodySID, IndexSID uint32
+	// the remaining fields of the partition pack
+	MajorVersion, MinorVersion                        uint16
+	KAGSize                                           uint32
+	ThisPartition, PreviousPartition, FooterPartition uint64
+	HeaderByteCount, IndexByteCount, BodyOffset       uint64
 }
 
 // ID returns the ID associated with a partition,
@@ -207,290 +230,6 @@ func (p PartitionProperties) Symbol() string {
 	return p.PartitionType
 }
 
-/*
-Search follows SQL syntax for nodes within a nodes children
-
-e.g. select * where UL = 060e2b34.027f0101.0d010101.01010f00
-
-"from" is not used as there are no tables within a node,
-the node is a table unto itself.
-
-Available fields are:
-
-  - ul
-  - sniff:{field name} - e.g. sniff:/root searches the sniff value of root
-
-The search command is not case sensitive
-*/
-func (n Node) Search(searchfield string) ([]*Node, error) {
-
-	command := strings.Split(searchfield, " ")
-
-	if len(command) < 6 {
-		return nil, fmt.Errorf("malformed command of %s expected \"select * where header = property\" as a minimum command", searchfield)
-	}
-
-	if strings.ToLower(command[0]) != "select" {
-		return nil, fmt.Errorf("first word not select")
-	}
-
-	out := make([]*Node, 0)
-	for _, search := range n.Children {
-		founds, err := recurseSearch(search, command[3], command[4], command[5])
-		if err != nil {
-			return nil, err
-		}
-		// search through the children as well
-		out = append(out, founds...)
-	}
-
-	return out, nil
-}
-
-/*
-Search follows SQL syntax for nodes within a partition
-
-e.g. select * from essence where UL <> 060e2b34.01020105.0e090502.017f017f
-
-Available tables are:
-
-  - essence
-  - metadata
-
-Available fields are:
-
-  - ul
-  - sniff:{field name} - e.g. sniff:/root searches the sniff value of root
-
-The search command is not case sensitive
-*/
-func (p PartitionNode) Search(searchfield string) ([]*Node, error) {
-	// lowercase as ULs are lower case when searching
-	command := strings.Split(searchfield, " ")
-
-	if len(command) < 4 {
-		return nil, fmt.Errorf("malformed command of %s expected \"select * from field\" as a minimum command", searchfield)
-	}
-
-	if command[0] != "select" {
-		return nil, fmt.Errorf("first word not select")
-	}
-
-	// worry about this later
-	// if command[1] != "*"
-
-	var searchFields []*Node
-	switch command[3] {
-	case strings.ToLower("essence"):
-		searchFields = p.Essence
-	case strings.ToLower("metadata"):
-		searchFields = p.HeaderMetadata
-	default:
-		return nil, fmt.Errorf("invalid field of \"%s\"", command[3])
-	}
-
-	switch len(command) {
-	case 4:
-		return searchFields, nil
-	case 8:
-		// keep on trucking
-	default:
-		return nil, fmt.Errorf("malformed command of %s expected \"select * from field where x = y\" as a minimum command", searchfield)
-	}
-
-	out := make([]*Node, 0)
-	for _, search := range searchFields {
-		founds, err := recurseSearch(search, command[5], command[6], command[7])
-		if err != nil {
-			return nil, err
-		}
-		// search through the children as well
-		out = append(out, founds...)
-	}
-	return out, nil
-}
-
-func recurseSearch(node *Node, field, equate, target string) ([]*Node, error) {
-
-	if node == nil {
-		return nil, nil
-	}
-	out := make([]*Node, 0)
-
-	// search through the children as well
-	var compareField string
-
-	switch {
-	case strings.ToLower(field) == "ul":
-
-		compareField = node.Properties.UL()
-	case strings.Contains(field, "sniff:"):
-
-		if len(node.Sniffs) != 0 {
-			if field, ok := node.Sniffs[field[6:]]; ok {
-				compareField = field.Field
-			}
-		}
-
-	default:
-
-		return nil, fmt.Errorf("unknown field \"%v\"", field)
-	}
-
-	var pass bool
-	switch equate {
-	case "=":
-		pass = (compareField == target)
-	case "<>":
-
-		pass = (compareField != target)
-	default:
-		return nil, fmt.Errorf("unknown comparison operator \"%v\"", equate)
-	}
-
-	if pass {
-		out = append(out, node)
-	}
-
-	for _, child := range node.Children {
-		founds, err := recurseSearch(child, field, equate, target)
-		if err != nil {
-			return nil, err
-		}
-		// search through the children as well
-		out = append(out, founds...)
-	}
-
-	return out, nil
-}
-
-/*
-Search follows SQL for finding things within a partition
-e.g. select * from essence where partition <> header
-
-Available tables are:
-
-  - partition
-
-Available fields are:
-
-  - essence - the count of essence
-  - type - the partition types
-  - metadata - the count of metadata
-
-Available operators are:
-
-  - AND - all conditions have to be true
-
-The search command is not case sensitive
-*/
-func (m MXFNode) Search(searchfield string) ([]*PartitionNode, error) {
-	// lowercase as ULs are lower case when searching
-	command := strings.Split(searchfield, " ")
-
-	if len(command) < 4 {
-		return nil, fmt.Errorf("malformed command of %s expected \"select * from field\" as a minimum command", searchfield)
-	}
-
-	if command[0] != "select" {
-		return nil, fmt.Errorf("first word is not select, please use the correct formatting")
-	}
-
-	// worry about this later
-	// if command[1] != "*"
-
-	var searchFields []*PartitionNode
-	switch strings.ToLower(command[3]) {
-	case "partition", "partitions":
-		searchFields = m.Partitions
-	default:
-		return nil, fmt.Errorf("invalid field of \"%s\"", command[3])
-	}
-
-	switch len(command) {
-	case 4:
-		return searchFields, nil
-
-	default:
-		if len(command)%4 != 0 {
-			return nil, fmt.Errorf("malformed command of %s expected \"select * from field where x = y\" as a minimum command", searchfield)
-		}
-	}
-
-	// break the command into several bits
-
-	testFields := make([][4]string, len(command[4:])/4)
-
-	// set the commands
-
-	for i := 0; i < len(command[4:])/4; i++ {
-		if i == 0 {
-			testFields[i] = [4]string{"and", command[5], command[6], command[7]}
-		} else {
-			testFields[i] = [4]string{command[4+i*4], command[5+i*4], command[6+i*4], command[7+i*4]}
-		}
-	}
-
-	out := make([]*PartitionNode, 0)
-	for _, search := range searchFields {
-		GlobalPass := true
-		for _, tf := range testFields {
-			pass, err := compare(search, tf[1], tf[2], tf[3])
-
-			if err != nil {
-				return nil, err
-			}
-
-			switch strings.ToLower(tf[0]) {
-			case "and":
-				GlobalPass = (GlobalPass && pass)
-			default:
-				return nil, fmt.Errorf("unknown comparison %v", tf[0])
-			}
-
-			// skip the search
-			if !GlobalPass {
-				break
-			}
-
-		}
-
-		if GlobalPass {
-			out = append(out, search)
-		}
-	}
-	return out, nil
-}
-
-func compare(search *PartitionNode, field, operator, target string) (bool, error) {
-
-	var compareField string
-
-	switch strings.ToLower(field) {
-	case "type":
-		compareField = search.Props.PartitionType
-	case "essence":
-		compareField = fmt.Sprintf("%v", len(search.Essence))
-	case "metadata":
-		compareField = fmt.Sprintf("%v", len(search.HeaderMetadata))
-	default:
-		return false, fmt.Errorf("unknown field \"%v\"", field)
-	}
-
-	var pass bool
-	switch operator {
-	case "=":
-		pass = (compareField == target)
-	case "<>":
-		pass = (compareField != target)
-	default:
-		return pass, fmt.Errorf("unknown comparison operator \"%v\"", operator)
-
-	}
-
-	return pass, nil
-}
-
 type refAndChild struct {
 	child bool
 	ref   [][]byte
@@ -502,6 +241,11 @@ type refAndChild struct {
 // declared as specifications.
 func MakeAST(stream io.Reader, buffer chan *klv.KLV, size int, specs Specifications) (*MXFNode, error) { // wg *sync.WaitGroup, buffer chan packet, errChan chan error) {
 
+	// invalid specifications are not run
+	if err := specs.Err(); err != nil {
+		return nil, err
+	}
+
 	// use errs to handle errors while runnig concurrently
 	errs, _ := errgroup.WithContext(context.Background())
 
@@ -543,15 +287,14 @@ func MakeAST(stream io.Reader, buffer chan *klv.KLV, size int, specs Specificati
 					mxf.Partitions = append(mxf.Partitions, currentPartitionNode)
 				}
 
+				partitionLayout := PartitionExtract(klvItem)
 				// extract the partition
-				currentPartitionNode = extractPartition(klvItem, mxf, &patternTally, primer, specs, offset)
+				currentPartitionNode = extractPartition(klvItem, partitionLayout, mxf, &patternTally, primer, specs, offset)
 
 				// create a reference map for every node that is found
 				refMap := make(map[*Node]refAndChild)
 				offset += klvItem.TotalLength()
 
-				partitionLayout := PartitionExtract(klvItem)
-
 				metaByteCount := 0
 				idMap := make(map[string]*Node) // assign the ids of the map
 				for metaByteCount < int(partitionLayout.HeaderByteCount) {
@@ -588,6 +331,9 @@ func MakeAST(stream io.Reader, buffer chan *klv.KLV, size int, specs Specificati
 						// want to loop through them all?
 
 					} else {
+						// keep the key before it is masked, so the group
+						// can be decoded when searching
+						mdNode.source = &groupSource{group: &klv.KLV{Key: slices.Clone(metadata.Key), Length: metadata.Length, Value: metadata.Value}, primer: primer}
 						// extract the metadata form the klv
 						metadataNodeExtraction(metadata, mdNode, refMap, idMap, primer, specs)
 
@@ -627,6 +373,8 @@ func MakeAST(stream io.Reader, buffer chan *klv.KLV, size int, specs Specificati
 					}
 				}
 
+				currentPartitionNode.instances = idMap
+
 				// order the map by appearance order
 				slices.SortFunc(currentPartitionNode.HeaderMetadata, func(a, b *Node) int {
 					return a.Key.Start - b.Key.Start
@@ -660,8 +408,7 @@ func MakeAST(stream io.Reader, buffer chan *klv.KLV, size int, specs Specificati
 				essNode := extractEssenceNode(klvItem, currentPartitionNode, offset, &patternTally)
 				// sniff the data based on the specifications
 				// n := time.Now()
-				out := Sniff(klvItem.Value, specs.sniffTests)
-				essNode.Sniffs = out
+				essNode.Sniffs, essNode.ContentTypes = sniffData(klvItem.Value, specs.sniffTests, specs.sniffAll)
 
 				currentPartitionNode.Essence = append(currentPartitionNode.Essence, essNode)
 				offset += klvItem.TotalLength()
@@ -696,6 +443,9 @@ func MakeAST(stream io.Reader, buffer chan *klv.KLV, size int, specs Specificati
 	// assign after the yaml to stop endless recursion
 	for _, p := range mxf.Partitions {
 		p.Parent = mxf
+		// index the partition now the nodes are complete
+		p.essenceIndex = newNodeIndex(p.Essence)
+		p.metadataIndex = newNodeIndex(p.HeaderMetadata)
 	}
 	return mxf, nil
 }
@@ -703,7 +453,7 @@ func MakeAST(stream io.Reader, buffer chan *klv.KLV, size int, specs Specificati
 // extract the essence as a Node
 func extractEssenceNode(klvItem *klv.KLV, currentPartitionNode *PartitionNode, offset int, patternTally *bool) *Node {
 	name := fullName(klvItem.Key)
-	_, ok := mxf2go.EssenceLookUp["urn:smpte:ul:"+name]
+	_, ok := EssenceLookUp(name)
 
 	if len(currentPartitionNode.Props.EssenceOrder) != 0 {
 		if currentPartitionNode.Props.EssenceOrder[0] == name {
@@ -718,11 +468,11 @@ func extractEssenceNode(klvItem *klv.KLV, currentPartitionNode *PartitionNode, o
 	if !ok {
 		// check for a 7f masked version at the final byte
 		klvItem.Key[15] = 0x7f
-		_, ok = mxf2go.EssenceLookUp["urn:smpte:ul:"+fullName(klvItem.Key)]
+		_, ok = EssenceLookUp(fullName(klvItem.Key))
 		if !ok {
 			// check for a 7f masked version at the final byte and the 14th byte
 			klvItem.Key[13] = 0x7f
-			_, ok = mxf2go.EssenceLookUp["urn:smpte:ul:"+fullName(klvItem.Key)]
+			_, ok = EssenceLookUp(fullName(klvItem.Key))
 			if ok {
 				name = fullName(klvItem.Key)
 			}
@@ -744,7 +494,7 @@ func extractEssenceNode(klvItem *klv.KLV, currentPartitionNode *PartitionNode, o
 
 }
 
-func extractPartition(klvItem *klv.KLV, mxf *MXFNode, patternTally *bool, primer map[string]string, specs Specifications, offset int) *PartitionNode {
+func extractPartition(klvItem *klv.KLV, layout Partition, mxf *MXFNode, patternTally *bool, primer map[string]string, specs Specifications, offset int) *PartitionNode {
 	partition := &PartitionNode{
 
 		Key:            Position{Start: offset, End: offset + len(klvItem.Key)},
@@ -764,7 +514,12 @@ func extractPartition(klvItem *klv.KLV, mxf *MXFNode, patternTally *bool, primer
 	// test the previous partitions essence as the final step
 	// if len(contents.RipLayout) == 0 and the cache length !=0 emit an error that essence was found first
 
-	partProps := PartitionProperties{PartitionCount: len(mxf.Partitions), EssenceOrder: make([]string, 0)}
+	partProps := PartitionProperties{PartitionCount: len(mxf.Partitions), EssenceOrder: make([]string, 0),
+		Status: layout.Status, OperationalPattern: layout.OperationalPattern, OperationalPatternName: layout.OperationalPatternName,
+		EssenceContainers: layout.EssenceContainers, BodySID: layout.BodySID, IndexSID: layout.IndexSID,
+		MajorVersion: layout.MajorVersion, MinorVersion: layout.MinorVersion, KAGSize: layout.SizeKAG,
+		ThisPartition: layout.ThisPartition, PreviousPartition: layout.PreviousPartition, FooterPartition: layout.FooterPartition,
+		HeaderByteCount: layout.HeaderByteCount, IndexByteCount: layout.IndexByteCount, BodyOffset: layout.BodyOffset}
 
 	switch klvItem.Key[13] {
 	case 17:
@@ -806,19 +561,20 @@ func metadataNodeExtraction(metadata *klv.KLV, mdNode *Node, refMap map[*Node]re
 
 	dec, _ := decodeBuilder(metadata.Key[5])
 
-	decoders, ok := mxf2go.Groups["urn:smpte:ul:"+fullName(metadata.Key)]
+	decoders, ok := groupDefinition(fullName(metadata.Key))
 
 	if !ok {
 		metadata.Key[5] = 0x7f
-		decoders, ok = mxf2go.Groups["urn:smpte:ul:"+fullName(metadata.Key)]
+		decoders, ok = groupDefinition(fullName(metadata.Key))
 	}
 	if !ok {
 		metadata.Key[13] = 0x7f
-		decoders, ok = mxf2go.Groups["urn:smpte:ul:"+fullName(metadata.Key)]
+		decoders, ok = groupDefinition(fullName(metadata.Key))
 	}
 
 	// assign the generic name as the key
 	key := fullName(metadata.Key)
+	groupUL := key
 	mdNode.Properties = GroupProperties{UniversalLabel: key}
 	// find the groups first
 
@@ -837,7 +593,7 @@ func metadataNodeExtraction(metadata *klv.KLV, mdNode *Node, refMap map[*Node]re
 		key, klength := dec.keyFunc(metadata.Value[pos : pos+dec.keyLen])
 		length, lenlength := dec.lengthFunc(metadata.Value[pos+dec.keyLen : pos+dec.keyLen+dec.lengthLen])
 		if klength != 16 {
-			key = primer[key]
+			key = localTagUL(groupUL, key, primer)
 		}
 
 		// @TODO inlude the key for other AUIDs and ObjectIDs as part of the process
@@ -1082,54 +838,89 @@ func DecodeGroupNode(doc io.ReadSeeker, node *Node, primer map[string]string) (m
 // DecodeGroup decodes a group KLV into a map[string]any,
 // where the key of the map is the name of the field and the any is the decoded value.
 // Any unknown fields will not be decoded and are skipped from the returned values.
+// Fields that fail to decode are kept, with the value returned by their decoder.
+// Use DecodeGroupResult for the reasons why any fields were skipped or failed to decode.
 //
 // The primer is a map of map[shorthandKey]fullUL
 func DecodeGroup(group *klv.KLV, primer map[string]string) (map[string]any, error) {
-	dec, skip := decodeBuilder(group.Key[5])
-
-	if skip {
-		return nil, fmt.Errorf("unable to decode essence, unknown decode method byte %0x", group.Key[5])
+	items, err := DecodeLocalSet(group, primer)
+	if items == nil {
+		return nil, err
 	}
 
-	decoders, ok := mxf2go.Groups["urn:smpte:ul:"+fullName(group.Key)]
-
+	decoders, ok := GroupLookUp(group.Key)
 	if !ok {
-		decoders, ok = mxf2go.Groups["urn:smpte:ul:"+FullNameMask(group.Key, 5)]
+		return nil, fmt.Errorf("no group for the key %s was found", fullName(group.Key))
 	}
-	if !ok {
-		decoders, ok = mxf2go.Groups["urn:smpte:ul:"+FullNameMask(group.Key, 5, 13)]
+
+	output := make(map[string]any)
+	for _, item := range items {
+		decodeF, ok := decoders.Group["urn:smpte:ul:"+item.UL]
+		if ok {
+			output[decodeF.UL], _ = safeDecode(decodeF, item.Value)
+		}
 	}
 
-	if !ok {
-		return nil, fmt.Errorf("no group for the key %s was found", fullName(group.Key))
+	return output, err
+}
+
+// LocalSetItem is a single property of a group, as it is
+// found in the local set.
+type LocalSetItem struct {
+	// LocalTag is the key of the property as written in the file,
+	// for 16 byte keys this is the full UL.
+	LocalTag string
+	// UL is the full Universal Label of the property,
+	// it is empty if the local tag is not in the primer.
+	UL string
+	// Value is the undecoded value of the property
+	Value []byte
+}
+
+// DecodeLocalSet splits a group KLV into its properties, without decoding them.
+// The properties are returned in the order they are found in the group.
+//
+// The primer is a map of map[shorthandKey]fullUL
+func DecodeLocalSet(group *klv.KLV, primer map[string]string) ([]LocalSetItem, error) {
+	dec, skip := decodeBuilder(group.Key[5])
+
+	if skip {
+		return nil, fmt.Errorf("unable to decode group, unknown decode method byte %0x", group.Key[5])
 	}
 
-	output := make(map[string]any)
+	_, groupUL, _ := groupLookUp(group.Key)
+	items := make([]LocalSetItem, 0)
 	pos := 0
 
 	for pos < len(group.Value) {
-		key, klength := dec.keyFunc(group.Value[pos : pos+dec.keyLen])
-
-		end := pos + dec.keyLen + dec.lengthLen
-		if end > len(group.Value) {
-			end = len(group.Value)
+		if pos+dec.keyLen >= len(group.Value) {
+			return items, fmt.Errorf("the property at byte %v of the group runs past the end of the group", pos)
 		}
+
+		tag, klength := dec.keyFunc(group.Value[pos : pos+dec.keyLen])
+
+		// BER lengths can be shorter than the maximum length
+		end := min(pos+dec.keyLen+dec.lengthLen, len(group.Value))
 		length, lenlength := dec.lengthFunc(group.Value[pos+dec.keyLen : end])
-		if klength != 16 {
-			key = primer[key]
+		if lenlength == 0 {
+			return items, fmt.Errorf("the length of the property %s can not be decoded", tag)
 		}
+		start := pos + dec.keyLen + lenlength
 
-		decodeF, ok := decoders.Group["urn:smpte:ul:"+key]
+		if start+length > len(group.Value) {
+			return items, fmt.Errorf("the value of the property %s runs past the end of the group", tag)
+		}
 
-		if ok {
-			b, _ := decodeF.Decode(group.Value[pos+dec.keyLen+lenlength : pos+dec.keyLen+lenlength+length])
-			output[decodeF.UL] = b
+		ul := tag
+		if klength != 16 {
+			ul = localTagUL(groupUL, tag, primer)
 		}
 
-		pos += klength + length + lenlength
+		items = append(items, LocalSetItem{LocalTag: tag, UL: ul, Value: group.Value[start : start+length]})
+		pos = start + length
 	}
 
-	return output, nil
+	return items, nil
 }
 
 // NodeToKLV converts a node to a KLV object
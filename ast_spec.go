@@ -1,6 +1,8 @@
 package mxftest
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
@@ -13,6 +15,9 @@ import (
 MRXTest tests an MRX file against the specifications given to it, if no
 specifications are passed then no tests are run.
 These test results are then logged as an yaml file to the io.Writer.
+
+The baseline ST 377-1 checks are found in the specs/st377 package,
+and are run by including them in the specifications.
 */
 func MRXTest(doc io.ReadSeeker, w io.Writer, testspecs ...Specifications) error {
 
@@ -39,7 +44,7 @@ func MRXTest(doc io.ReadSeeker, w io.Writer, testspecs ...Specifications) error
 
 	validTests := validTestCount(ast.Tests.tests)
 	// only test the structure id there's any tests
-		// load in default of 377 checker etc
+	if validTests > 0 {
 		tc.Header("testing mxf file structure", func(t Test) {
 			for _, structure := range ast.Tests.tests {
 				if *structure.runTest {
@@ -279,7 +284,7 @@ func generateSpecifications(testspecs ...Specifications) (base, skips Specificat
 		markerNode: make(map[string][]markedTestWithPrimer[Node]),
 		markerPart: make(map[string][]markedTest[PartitionNode]),
 		markerMXF:  make([]markedTest[MXFNode], 0),
-		sniffTests: map[*DataIdentifier][]Sniffer{},
+		sniffTests: make([]SniffTest, 0),
 	}
 
 	for _, ts := range testspecs {
@@ -317,15 +322,9 @@ func generateSpecifications(testspecs ...Specifications) (base, skips Specificat
 		// add the sniff tests
 		// avoid replications of sniff tests
 		if !reflect.DeepEqual(ts.SniffTests, SniffTest{}) {
-
-			for _, sniffTests := range ts.SniffTests.Sniffs {
-				// check each sniffer and make sure its not duplicated for that data test
-				if !slices.Contains(base.sniffTests[&ts.SniffTests.DataID], sniffTests) {
-					base.sniffTests[&ts.SniffTests.DataID] = append(base.sniffTests[&ts.SniffTests.DataID], sniffTests)
-				}
-			}
-
+			base.sniffTests = addSniffTest(base.sniffTests, ts.SniffTests)
 		}
+		base.sniffAll = base.sniffAll || ts.sniffAll
 
 		// COPY STRUCTUAL TESTS
 		for i, n := range ts.MXF {
@@ -337,8 +336,15 @@ func generateSpecifications(testspecs ...Specifications) (base, skips Specificat
 			ts.markerMXF[i] = markedTest[MXFNode]{test: n.test, runTest: &markTrue}
 		}
 		base.markerMXF = append(base.markerMXF, ts.markerMXF...)
+
+		base.errs = append(base.errs, ts.errs...)
 	}
 
+	// sniff by priority, keeping the order the specifications were given in for equal priorities
+	slices.SortStableFunc(base.sniffTests, func(a, b SniffTest) int {
+		return cmp.Compare(b.DataID.Priority, a.DataID.Priority)
+	})
+
 	skips = cloneSpeciifcation(base)
 
 	return base, skips
@@ -450,7 +456,12 @@ type Specifications struct {
 	markerMXF []markedTest[MXFNode]
 	// Sniff Tests to check the data
 	SniffTests SniffTest
-	sniffTests map[*DataIdentifier][]Sniffer
+	// the sniff tests of every specification, in the order they are sniffed
+	sniffTests []SniffTest
+	// record every content type that matches the data
+	sniffAll bool
+	// errors found while making the specification
+	errs []error
 }
 
 // NewSpecification generates a new Specifications object.
@@ -565,6 +576,15 @@ func WithSniffTest(sniffTest SniffTest) func(s *Specifications) {
 	}
 }
 
+// WithAllContentTypes records every content type that matches the data
+// found within the file, with the certainty of each match, in the ContentTypes of the node.
+// Only the sniff tests of the first content type that matches are run.
+func WithAllContentTypes() func(s *Specifications) {
+	return func(s *Specifications) {
+		s.sniffAll = true
+	}
+}
+
 // WithNodeTags adds the Node tests to the specifications object
 func WithNodeTags(nodeTags ...NodeTest) func(s *Specifications) {
 	return func(s *Specifications) {
@@ -573,3 +593,23 @@ func WithNodeTags(nodeTags ...NodeTest) func(s *Specifications) {
 		}
 	}
 }
+
+// WithQueries checks the queries used by the tests of the specification.
+// Any queries that can not be compiled stop the specification from being run,
+// with the errors returned before the file is read.
+func WithQueries(queries ...string) func(s *Specifications) {
+	return func(s *Specifications) {
+		for _, text := range queries {
+			if _, err := CompileQuery(text); err != nil {
+				s.errs = append(s.errs, fmt.Errorf("invalid specification query %q: %w", text, err))
+			}
+		}
+	}
+}
+
+// Err returns any errors found while making the specification,
+// such as queries that could not be compiled.
+// Specifications with errors can not be run.
+func (s *Specifications) Err() error {
+	return errors.Join(s.errs...)
+}
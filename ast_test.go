@@ -212,7 +212,7 @@ func TestSearches(t *testing.T) {
 	badSearches := []string{"selec * where UL = 060e2b34.027f0101.0d010101.01010f00",
 		"select * where unknownField = testfield",
 		"select * where UL <=> anInvalidField"}
-	badError := []string{"first word not select", "unknown field \"unknownField\"", "unknown comparison operator \"<=>\""}
+	badError := []string{"expected \"select\" but found \"selec\" at column 1", "unknown field \"unknownField\" at column 16", "unknown comparison operator \"<=>\" at column 19"}
 
 	for i, bs := range badSearches {
 		found, err := parentNode.Search(bs)
@@ -220,7 +220,8 @@ func TestSearches(t *testing.T) {
 		Convey("Checking the Node search functions return the correct errors", t, func() {
 			Convey(fmt.Sprintf("running a search of %s", bs), func() {
 				Convey("An error is returned with no nodes", func() {
-					So(err, ShouldResemble, fmt.Errorf(badError[i]))
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldEqual, badError[i])
 					So(len(found), ShouldEqual, 0)
 				})
 			})
@@ -258,7 +259,7 @@ func TestSearches(t *testing.T) {
 	badPartSearches := []string{"selec * where UL = 060e2b34.027f0101.0d010101.01010f00",
 		"select * from essence where unknownField = testfield",
 		"select * from essence where UL <=> anInvalidField"}
-	badPartError := []string{"first word not select", "unknown field \"unknownField\"", "unknown comparison operator \"<=>\""}
+	badPartError := []string{"expected \"select\" but found \"selec\" at column 1", "unknown field \"unknownField\" at column 29", "unknown comparison operator \"<=>\" at column 32"}
 
 	for i, bs := range badPartSearches {
 		found, err := dummyPart.Search(bs)
@@ -266,7 +267,8 @@ func TestSearches(t *testing.T) {
 		Convey("Checking the partition search functions return the correct errors", t, func() {
 			Convey(fmt.Sprintf("running a search of %s", bs), func() {
 				Convey("An error is returned with no nodes", func() {
-					So(err, ShouldResemble, fmt.Errorf(badPartError[i]))
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldEqual, badPartError[i])
 					So(len(found), ShouldEqual, 0)
 				})
 			})
@@ -302,7 +304,7 @@ func TestSearches(t *testing.T) {
 	badMXFSearches := []string{"selec * where UL = 060e2b34.027f0101.0d010101.01010f00",
 		"select * from partition where unknownField = testfield",
 		"select * from partition where metadata <=> anInvalidField"}
-	badMXFError := []string{"first word is not select, please use the correct formatting", "unknown field \"unknownField\"", "unknown comparison operator \"<=>\""}
+	badMXFError := []string{"expected \"select\" but found \"selec\" at column 1", "unknown field \"unknownField\" at column 31", "unknown comparison operator \"<=>\" at column 40"}
 
 	for i, bs := range badMXFSearches {
 		found, err := dummyMXF.Search(bs)
@@ -310,7 +312,8 @@ func TestSearches(t *testing.T) {
 		Convey("Checking the partition search functions return the correct errors", t, func() {
 			Convey(fmt.Sprintf("running a search of %s", bs), func() {
 				Convey("An error is returned with no nodes", func() {
-					So(err, ShouldResemble, fmt.Errorf(badMXFError[i]))
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldEqual, badMXFError[i])
 					So(len(found), ShouldEqual, 0)
 				})
 			})
@@ -318,6 +321,80 @@ func TestSearches(t *testing.T) {
 	}
 }
 
+func TestPartitionPacks(t *testing.T) {
+
+	doc, docErr := os.Open("./testdata/demoReports/goodISXD.mxf")
+	ast, genErr := MakeAST(doc, make(chan *klv.KLV, 1000), 10, *NewSpecification())
+
+	Convey("Checking the partition packs are fully decoded", t, func() {
+		Convey("generating an AST of goodISXD.mxf, an open incomplete OP1a file", func() {
+			Convey("The status, operational pattern and essence containers are decoded for every partition", func() {
+				So(docErr, ShouldBeNil)
+				So(genErr, ShouldBeNil)
+				// the RIP is the final partition
+				So(len(ast.Partitions), ShouldEqual, 4)
+				for i, status := range []string{OpenIncomplete, OpenIncomplete, ClosedComplete} {
+					So(ast.Partitions[i].Props.Status, ShouldEqual, status)
+					So(ast.Partitions[i].Props.OperationalPattern, ShouldEqual, "060e2b34.04010101.0d010201.01010500")
+					So(ast.Partitions[i].Props.OperationalPatternName, ShouldEqual, "MXFOP1aSingleItemSinglePackageUniTrackNonStreamInternal")
+					So(ast.Partitions[i].Props.EssenceContainers, ShouldResemble, []string{"060e2b34.04010105.0e090607.01010103"})
+				}
+			})
+		})
+	})
+
+	searches := []string{"select * from partition where status = closedcomplete",
+		"select * from partition where status = openincomplete",
+		"select * from partition where op = MXFOP1aSingleItemSinglePackageUniTrackNonStreamInternal AND type = footer",
+		"select * from partition where essencecontainer = 060e2b34.04010105.0e090607.01010103",
+		"select * from partition where essencecontainer <> 060e2b34.04010105.0e090607.01010103 AND type <> rip",
+		"select * from partition where operationalpattern = 060e2b34.04010101.0d010201.01010500",
+		"select * from partitions where bodysid = 1 and essence > 0",
+		"select * from partitions where headerbytecount > 0 and kag = 1",
+		"select * from partitions where thispartition = 2499 and previouspartition < 2499",
+		"select * from partitions where position >= 1 and offset < 11294",
+		"select * from partitions where majorversion = 1 and minorversion >= 3 and footerpartition = 11294 and indexsid = 0"}
+	expectedCounts := []int{1, 2, 1, 3, 0, 3, 1, 2, 1, 1, 1}
+
+	for i, s := range searches {
+		found, err := ast.Search(s)
+
+		Convey("Checking the mxf search functions with the partition pack fields", t, func() {
+			Convey(fmt.Sprintf("running a search of %s", s), func() {
+				Convey("No error is returned and the expected partitions are returned", func() {
+					So(err, ShouldBeNil)
+					So(len(found), ShouldEqual, expectedCounts[i])
+				})
+			})
+		})
+	}
+
+	labelChecks := []string{"060e2b34.04010101.0d010201.01010500", "060e2b34.0401010d.0d010201.01010500", "060e2b34.04010101.0d010201.01010100"}
+	expectedLabels := []string{"MXFOP1aSingleItemSinglePackageUniTrackNonStreamInternal", "MXFOP1aSingleItemSinglePackageUniTrackNonStreamInternal", "MXFOP1aSingleItemSinglePackageUniTrackStreamInternal"}
+
+	for i, ul := range labelChecks {
+		label, found := LabelLookUp(ul)
+
+		Convey("Checking labels are found in the registers", t, func() {
+			Convey(fmt.Sprintf("looking up the label %s", ul), func() {
+				Convey("The label is found, with the version byte ignored", func() {
+					So(found, ShouldBeTrue)
+					So(label.Symbol, ShouldEqual, expectedLabels[i])
+				})
+			})
+		})
+	}
+
+	_, found := LabelLookUp("not a ul")
+	Convey("Checking invalid labels are not found in the registers", t, func() {
+		Convey("looking up the label \"not a ul\"", func() {
+			Convey("No label is found", func() {
+				So(found, ShouldBeFalse)
+			})
+		})
+	})
+}
+
 func TestDecodes(t *testing.T) {
 
 	// set up dummy data to be encoded/decoded
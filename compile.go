@@ -0,0 +1,148 @@
+package mxftest
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+CompiledQuery is a query that has been parsed and checked once,
+so it can be run any number of times without being parsed again.
+
+The query is checked against the fields of the level it is run at, so unknown fields,
+tables and invalid numbers are found when the query is compiled,
+rather than when it is run.
+
+  - queries without a table are run on nodes
+  - the essence and metadata tables are searched on partitions,
+    and are tables of partitions or the whole file
+  - the partitions table is run on the whole file, or joined to
+    the selected essence or metadata with SearchNodes
+
+Queries that select * are used for searches, any other columns
+are used for tables, apart from SearchNodes which selects essence or metadata.
+*/
+type CompiledQuery struct {
+	q *query
+}
+
+// CompileQuery parses and checks a query, returning a *SyntaxError
+// if there are any mistakes in the query.
+func CompileQuery(text string) (*CompiledQuery, error) {
+	q, err := parseQuery(text)
+	if err != nil {
+		return nil, err
+	}
+
+	switch q.table {
+	case "":
+		err = validateQuery(q, nodeField)
+	case "essence", "metadata":
+		// searches are run on partitions, tables are
+		// run on partitions or the whole file
+		err = validateQuery(q, nodeField)
+		if err != nil && q.selectAll() != nil {
+			if joinErr := validateQuery(q, joinField(q.table, true)); joinErr == nil {
+				err = nil
+			}
+		}
+	case "partition", "partitions":
+		// tables of the partitions or SearchNodes, which
+		// joins the partitions to the selected table
+		err = validateQuery(q, partitionField)
+		if table := strings.ToLower(q.columns[0].name); err != nil && len(q.columns) == 1 && (table == "essence" || table == "metadata") {
+			if _, joinErr := compileCondition(q, q.where, joinField(table, false)); joinErr == nil {
+				err = nil
+			}
+		}
+	default:
+		err = &SyntaxError{Query: q.text, Column: q.tableColumn, Msg: fmt.Sprintf("unknown table %q", q.table)}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &CompiledQuery{q: q}, nil
+}
+
+// MustCompileQuery is like CompileQuery but panics if the query can not be compiled.
+// It is for queries that are fixed when a specification is written,
+// e.g. var staticTracks = MustCompileQuery("select * from metadata where ul = StaticTrack")
+func MustCompileQuery(text string) *CompiledQuery {
+	c, err := CompileQuery(text)
+	if err != nil {
+		panic(fmt.Sprintf("mxftest: compiling query: %v", err))
+	}
+
+	return c
+}
+
+// validateQuery checks the conditions and columns of a query
+// against the fields of the items it is run on.
+func validateQuery[T any](q *query, fields func(name string) (queryField[T], bool)) error {
+	if _, err := compileCondition(q, q.where, fields); err != nil {
+		return err
+	}
+
+	if q.selectAll() == nil {
+		return nil
+	}
+
+	// check the columns by making an empty table
+	_, err := runQuery(q, nil, fields)
+
+	return err
+}
+
+// String returns the text of the query
+func (c *CompiledQuery) String() string {
+	return c.q.text
+}
+
+// SearchNode runs the query as a Node Search of the children of n.
+func (c *CompiledQuery) SearchNode(n *Node) ([]*Node, error) {
+	if err := c.q.selectAll(); err != nil {
+		return nil, err
+	}
+
+	return n.search(c.q)
+}
+
+// SearchPartition runs the query as a PartitionNode Search of p.
+func (c *CompiledQuery) SearchPartition(p *PartitionNode) ([]*Node, error) {
+	if err := c.q.selectAll(); err != nil {
+		return nil, err
+	}
+
+	return p.search(c.q)
+}
+
+// SearchMXF runs the query as an MXFNode Search of the partitions of m.
+func (c *CompiledQuery) SearchMXF(m *MXFNode) ([]*PartitionNode, error) {
+	if err := c.q.selectAll(); err != nil {
+		return nil, err
+	}
+
+	return m.search(c.q)
+}
+
+// SearchNodes runs the query as an MXFNode SearchNodes of m.
+func (c *CompiledQuery) SearchNodes(m *MXFNode) ([]NodeResult, error) {
+	return m.joinSearch(c.q)
+}
+
+// QueryNode runs the query as a Node Query of the children of n.
+func (c *CompiledQuery) QueryNode(n *Node) (Table, error) {
+	return n.tabulate(c.q)
+}
+
+// QueryPartition runs the query as a PartitionNode Query of p.
+func (c *CompiledQuery) QueryPartition(p *PartitionNode) (Table, error) {
+	return p.tabulate(c.q)
+}
+
+// QueryMXF runs the query as an MXFNode Query of m.
+func (c *CompiledQuery) QueryMXF(m *MXFNode) (Table, error) {
+	return m.tabulate(c.q)
+}
@@ -0,0 +1,460 @@
+package mxftest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/metarex-media/mrx-tool/klv"
+	mxf2go "github.com/metarex-media/mxf-to-go"
+)
+
+// DecodeIssueType is the type of problem found when decoding a property
+type DecodeIssueType string
+
+const (
+	// UnknownProperty is a property that is not defined for the group
+	UnknownProperty DecodeIssueType = "unknown property"
+	// MissingPrimerTag is a local tag that is not in the primer
+	MissingPrimerTag DecodeIssueType = "tag missing from the primer"
+	// DecodeFailure is a property value that could not be decoded
+	DecodeFailure DecodeIssueType = "decode failure"
+	// LengthMismatch is a property value with a different length to the register
+	LengthMismatch DecodeIssueType = "length mismatch"
+	// RepeatedProperty is a property found more than once in the group
+	RepeatedProperty DecodeIssueType = "repeated property"
+)
+
+// DecodeIssue is a problem found with a single property when decoding a group.
+type DecodeIssue struct {
+	// LocalTag is the key of the property as written in the file
+	LocalTag string
+	// UL is the Universal Label of the property, it is empty
+	// if the tag is not in the primer
+	UL string
+	// Name is the register name of the property, it is empty
+	// if the property is unknown
+	Name string
+	// Type is the type of issue
+	Type DecodeIssueType
+	// Reason gives the details of the issue
+	Reason string
+}
+
+// String returns the issue in the form "name: type, reason"
+func (d DecodeIssue) String() string {
+	name := d.Name
+	switch {
+	case name != "":
+	case d.UL != "":
+		name = d.UL
+	default:
+		name = d.LocalTag
+	}
+
+	if d.Reason == "" {
+		return fmt.Sprintf("%s: %s", name, d.Type)
+	}
+
+	return fmt.Sprintf("%s: %s, %s", name, d.Type, d.Reason)
+}
+
+// DecodeResult is the result of decoding a group, with the decoded
+// values and any issues found with the properties.
+type DecodeResult struct {
+	// Values are the decoded properties, where the key of the map is the name of the field
+	Values map[string]any
+	// Issues are the problems found with the properties,
+	// in the order they are found in the group
+	Issues []DecodeIssue
+}
+
+// Err returns the issues as a single error,
+// nil is returned if there are no issues.
+func (d DecodeResult) Err() error {
+	errs := make([]error, len(d.Issues))
+	for i, issue := range d.Issues {
+		errs[i] = errors.New(issue.String())
+	}
+
+	return errors.Join(errs...)
+}
+
+// DecodeGroupNodeResult decodes a Node into a DecodeResult,
+// which contains the decoded values and the issues with any properties
+// that could not be decoded.
+//
+// The primer is a map of map[shorthandKey]fullUL
+func DecodeGroupNodeResult(doc io.ReadSeeker, node *Node, primer map[string]string) (DecodeResult, error) {
+	groupKLV, err := NodeToKLV(doc, node)
+	if err != nil {
+		return DecodeResult{}, err
+	}
+
+	return DecodeGroupResult(groupKLV, primer)
+}
+
+// DecodeGroupResult decodes a group KLV into a DecodeResult,
+// which contains the decoded values and the issues with any properties
+// that could not be decoded.
+// An error is only returned if the group can not be decoded at all.
+//
+// The primer is a map of map[shorthandKey]fullUL
+func DecodeGroupResult(group *klv.KLV, primer map[string]string) (DecodeResult, error) {
+	result := DecodeResult{Values: make(map[string]any), Issues: make([]DecodeIssue, 0)}
+
+	decoders, ok := GroupLookUp(group.Key)
+	if !ok {
+		return result, fmt.Errorf("no group for the key %s was found", fullName(group.Key))
+	}
+
+	items, err := DecodeLocalSet(group, primer)
+	seen := make(map[string]bool)
+
+	for _, item := range items {
+		issue := DecodeIssue{LocalTag: item.LocalTag, UL: item.UL}
+
+		if item.UL == "" {
+			issue.Type = MissingPrimerTag
+			result.Issues = append(result.Issues, issue)
+			continue
+		}
+
+		decodeF, ok := decoders.Group["urn:smpte:ul:"+item.UL]
+		if !ok {
+			issue.Type = UnknownProperty
+			issue.Reason = fmt.Sprintf("not defined for the %s group", decoders.Name)
+			result.Issues = append(result.Issues, issue)
+			continue
+		}
+
+		issue.Name = decodeF.UL
+		if seen[decodeF.UL] {
+			repeat := issue
+			repeat.Type = RepeatedProperty
+			result.Issues = append(result.Issues, repeat)
+		}
+		seen[decodeF.UL] = true
+
+		lengthErr := decodeF.Length > 0 && len(item.Value) != decodeF.Length
+		if lengthErr {
+			mismatch := issue
+			mismatch.Type = LengthMismatch
+			mismatch.Reason = fmt.Sprintf("%v bytes instead of %v", len(item.Value), decodeF.Length)
+			result.Issues = append(result.Issues, mismatch)
+		}
+
+		value, decErr := safeDecode(decodeF, item.Value)
+		if decErr != nil {
+			// a length mismatch is the reason the decode failed
+			if !lengthErr {
+				issue.Type = DecodeFailure
+				issue.Reason = decErr.Error()
+				result.Issues = append(result.Issues, issue)
+			}
+			continue
+		}
+
+		result.Values[decodeF.UL] = value
+	}
+
+	return result, err
+}
+
+// DecodeGroupNodeStrict decodes a Node into a map[string]any,
+// where the key of the map is the name of the field and the any is the decoded value.
+// Unlike DecodeGroupNode, an error is returned if any property is unknown,
+// is not in the primer, fails to decode, has the wrong length or is repeated.
+// The error describes every property that was not decoded.
+//
+// The primer is a map of map[shorthandKey]fullUL
+func DecodeGroupNodeStrict(doc io.ReadSeeker, node *Node, primer map[string]string) (map[string]any, error) {
+	groupKLV, err := NodeToKLV(doc, node)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeGroupStrict(groupKLV, primer)
+}
+
+// DecodeGroupStrict decodes a group KLV into a map[string]any,
+// returning an error if any property could not be decoded.
+// See DecodeGroupNodeStrict for more details.
+//
+// The primer is a map of map[shorthandKey]fullUL
+func DecodeGroupStrict(group *klv.KLV, primer map[string]string) (map[string]any, error) {
+	result, err := DecodeGroupResult(group, primer)
+	if err != nil {
+		return result.Values, err
+	}
+
+	return result.Values, result.Err()
+}
+
+// groupSource is the group KLV and partition primer of a node,
+// so that the properties can be decoded on demand.
+type groupSource struct {
+	group  *klv.KLV
+	primer map[string]string
+
+	once   sync.Once
+	values map[string]any
+}
+
+// decodedProperties returns the decoded properties of a node,
+// the properties are decoded the first time they are used.
+// Nil is returned if the node is not a group.
+func (n *Node) decodedProperties() map[string]any {
+	if n.source == nil {
+		return nil
+	}
+
+	n.source.once.Do(func() {
+		result, _ := DecodeGroupResult(n.source.group, n.source.primer)
+		n.source.values = result.Values
+	})
+
+	return n.source.values
+}
+
+// PropertyValues returns the decoded properties of a metadata node,
+// formatted as the values they are searched with.
+// e.g. ULs as 060e2b34.04010105.0e090606.00000000, rationals as 25/1
+// and arrays with a value for each item.
+//
+// Nil is returned if the node is not a group.
+func (n *Node) PropertyValues() map[string][]string {
+	values := n.decodedProperties()
+	if values == nil {
+		return nil
+	}
+
+	out := make(map[string][]string, len(values))
+	for name, value := range values {
+		out[name] = propertyValues(value)
+	}
+
+	return out
+}
+
+// safeDecode decodes a property value, returning any panics
+// from malformed values as an error.
+func safeDecode(prop mxf2go.Group, value []byte) (out any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("unable to decode the value: %v", r)
+		}
+	}()
+
+	return prop.Decode(value)
+}
+
+/*
+DecodeGroupNodeAs decodes a Node into the struct T, such as an
+mxf2go group struct like mxf2go.GISXDStruct, or a user defined struct.
+
+The fields of T are matched to the properties of the group by their name,
+or by the name given in an `mxf` tag. The tag can also mark a
+field as optional, or skip the field.
+
+	type isxd struct {
+		Coding    mxf2go.TAUID  `mxf:"DataEssenceCoding"`
+		Namespace string        `mxf:"NamespaceURIUTF8"`
+		ID        *mxf2go.TUUID `mxf:"InstanceID"`
+		Local     string        `mxf:"-"`
+	}
+
+Pointer fields and fields tagged `mxf:",optional"` are optional,
+as are the fields of the mxf2go group structs that are optional in the register,
+every other field is required. An error is returned for every required field
+that is missing, for fields that do not match a property of the group and
+for properties that can not be assigned to the type of their field.
+The decoded value of T is still returned when there are errors.
+
+The primer is a map of map[shorthandKey]fullUL
+*/
+func DecodeGroupNodeAs[T any](doc io.ReadSeeker, node *Node, primer map[string]string) (T, error) {
+	groupKLV, err := NodeToKLV(doc, node)
+	if err != nil {
+		var out T
+		return out, err
+	}
+
+	return DecodeGroupAs[T](groupKLV, primer)
+}
+
+// DecodeGroupAs decodes a group KLV into the struct T.
+// See DecodeGroupNodeAs for how the properties are assigned to the fields of T.
+//
+// The primer is a map of map[shorthandKey]fullUL
+func DecodeGroupAs[T any](group *klv.KLV, primer map[string]string) (T, error) {
+	var out T
+
+	target := reflect.ValueOf(&out).Elem()
+	if target.Kind() != reflect.Struct {
+		return out, fmt.Errorf("unable to decode into %v, only structs can be decoded into", target.Type())
+	}
+
+	decoded, err := DecodeGroup(group, primer)
+	if err != nil {
+		return out, err
+	}
+
+	// the names of the properties the group can have
+	definition, _ := GroupLookUp(group.Key)
+	// the register optionality is only used for the mxf2go group structs,
+	// other structs use the optional tag or pointer fields
+	register := target.Type().PkgPath() == reflect.TypeOf(mxf2go.GroupID{}).PkgPath()
+	names := make(map[string]bool)
+	for _, prop := range definition.Group {
+		names[prop.UL] = register && prop.IsOpt
+	}
+
+	fieldErrs := make([]error, 0)
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, optional, skip := fieldTag(field)
+		if skip {
+			continue
+		}
+
+		registerOpt, known := names[name]
+		optional = optional || registerOpt || field.Type.Kind() == reflect.Pointer
+
+		if !known {
+			fieldErrs = append(fieldErrs, fmt.Errorf("the field %s does not match a property of the %s group", field.Name, definition.Name))
+			continue
+		}
+
+		value, ok := decoded[name]
+		if !ok {
+			if !optional {
+				fieldErrs = append(fieldErrs, fmt.Errorf("the required property %s is missing", name))
+			}
+			continue
+		}
+
+		if err := assignField(target.Field(i), value); err != nil {
+			fieldErrs = append(fieldErrs, fmt.Errorf("the property %s can not be decoded into the field %s: %w", name, field.Name, err))
+		}
+	}
+
+	return out, errors.Join(fieldErrs...)
+}
+
+// fieldTag returns the property name of a struct field
+// and whether it is optional or skipped, from its `mxf` tag.
+func fieldTag(field reflect.StructField) (name string, optional, skip bool) {
+	tag, ok := field.Tag.Lookup("mxf")
+	if !ok {
+		return field.Name, false, false
+	}
+
+	if tag == "-" {
+		return "", false, true
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, options == "optional", false
+}
+
+// assignField sets a struct field to a decoded value.
+// Values are converted if they have the same kind as the field,
+// or are strings for rune and byte slice fields and vice versa.
+// Integers are converted to any integer field that can hold the value,
+// values that would be truncated return an error.
+func assignField(field reflect.Value, value any) error {
+	target := field
+	if field.Kind() == reflect.Pointer {
+		target = reflect.New(field.Type().Elem()).Elem()
+	}
+
+	val := reflect.ValueOf(value)
+	if !val.IsValid() {
+		return fmt.Errorf("no value was decoded")
+	}
+
+	switch {
+	case val.Type().AssignableTo(target.Type()):
+		target.Set(val)
+	case isInteger(val.Type()) && isInteger(target.Type()):
+		if overflows(val, target) {
+			return fmt.Errorf("the value %v of %v overflows %v", val, val.Type(), target.Type())
+		}
+		target.Set(val.Convert(target.Type()))
+	case convertible(val.Type(), target.Type()):
+		target.Set(val.Convert(target.Type()))
+	default:
+		return fmt.Errorf("%v is not assignable to %v", val.Type(), target.Type())
+	}
+
+	if field.Kind() == reflect.Pointer {
+		field.Set(target.Addr())
+	}
+
+	return nil
+}
+
+// convertible checks a value can be converted to the type of the field,
+// without changing what the value represents, e.g. an int to a string.
+func convertible(from, to reflect.Type) bool {
+	if !from.ConvertibleTo(to) {
+		return false
+	}
+
+	if from.Kind() == to.Kind() {
+		return true
+	}
+
+	isText := func(t reflect.Type) bool {
+		return t.Kind() == reflect.String ||
+			(t.Kind() == reflect.Slice && (t.Elem().Kind() == reflect.Int32 || t.Elem().Kind() == reflect.Uint8))
+	}
+
+	return isText(from) && isText(to)
+}
+
+// isInteger checks if a type is a signed or unsigned integer
+func isInteger(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
+
+// overflows checks if an integer value can not be held by
+// the integer target without changing its value.
+func overflows(val, target reflect.Value) bool {
+	targetSigned := target.CanInt()
+
+	if val.CanInt() {
+		v := val.Int()
+		if targetSigned {
+			return target.OverflowInt(v)
+		}
+
+		return v < 0 || target.OverflowUint(uint64(v))
+	}
+
+	v := val.Uint()
+	if targetSigned {
+		return v > math.MaxInt64 || target.OverflowInt(int64(v))
+	}
+
+	return target.OverflowUint(v)
+}
@@ -0,0 +1,240 @@
+package mxftest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/metarex-media/mrx-tool/klv"
+	mxf2go "github.com/metarex-media/mxf-to-go"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type isxdNamespace struct {
+	Namespace string           `mxf:"NamespaceURIUTF8"`
+	Rate      mxf2go.TRational `mxf:"SampleRate"`
+	Length    *int64           `mxf:"EssenceLength"`
+	Coding    mxf2go.TAUID     `mxf:"DataEssenceCoding,optional"`
+	Local     string           `mxf:"-"`
+}
+
+type isxdMissing struct {
+	EssenceLength mxf2go.TLengthType
+}
+
+type isxdTypo struct {
+	NamespaceURI string
+}
+
+type isxdMistyped struct {
+	SampleRate string
+}
+
+type isxdWideLength struct {
+	EssenceLength uint64
+}
+
+type isxdNarrowLength struct {
+	EssenceLength int16
+}
+
+type isxdTruncatedLength struct {
+	EssenceLength uint8
+}
+
+func TestDecodeAs(t *testing.T) {
+	primer := mxf2go.NewPrimer()
+	isxd := mxf2go.GISXDStruct{
+		ContainerFormat:  []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		InstanceID:       [16]uint8{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		NamespaceURIUTF8: []rune("example.com/test"),
+		SampleRate:       mxf2go.TRational{Numerator: 1, Denominator: 24}}
+
+	isxdBytes, _ := isxd.Encode(primer)
+
+	decodePrimer := make(map[string]string)
+	for full, short := range primer.Tags {
+		decodePrimer[fmt.Sprintf("%02x%02x", short[0], short[1])] = FullNameMask([]byte(full))
+	}
+
+	node := &Node{Key: Position{End: 16}, Length: Position{Start: 16, End: 17}, Value: Position{Start: 17, End: len(isxdBytes)}}
+
+	// decode into the mxf2go struct
+	fullDecode, fullErr := DecodeGroupNodeAs[mxf2go.GISXDStruct](bytes.NewReader(isxdBytes), node, decodePrimer)
+	Convey("Checking nodes can be decoded into mxf2go group structs", t, func() {
+		Convey("decoding an ISXD descriptor into a mxf2go.GISXDStruct", func() {
+			Convey("The decoded struct matches the encoded struct", func() {
+				So(fullErr, ShouldBeNil)
+				So(fullDecode, ShouldResemble, isxd)
+			})
+		})
+	})
+
+	// decode a descriptor without the optional instance ID into the mxf2go struct
+	var instanceTag []byte
+	for short, full := range decodePrimer {
+		if full == "060e2b34.01010101.01011502.00000000" {
+			instanceTag, _ = hex.DecodeString(short)
+		}
+	}
+	instancePos := bytes.Index(isxdBytes[17:], append(instanceTag, 0x00, 0x10)) + 17
+	optionalBytes := append(bytes.Clone(isxdBytes[:instancePos]), isxdBytes[instancePos+20:]...)
+	optionalBytes[16] -= 20
+	optionalNode := &Node{Key: Position{End: 16}, Length: Position{Start: 16, End: 17}, Value: Position{Start: 17, End: len(optionalBytes)}}
+
+	optionalDecode, optionalErr := DecodeGroupNodeAs[mxf2go.GISXDStruct](bytes.NewReader(optionalBytes), optionalNode, decodePrimer)
+	Convey("Checking optional register properties can be missing from mxf2go group structs", t, func() {
+		Convey("decoding an ISXD descriptor without an instance ID into a mxf2go.GISXDStruct", func() {
+			Convey("The struct is decoded with an empty instance ID", func() {
+				So(optionalErr, ShouldBeNil)
+				So(optionalDecode.InstanceID, ShouldResemble, mxf2go.TUUID{})
+				So(optionalDecode.SampleRate, ShouldResemble, isxd.SampleRate)
+			})
+		})
+	})
+
+	// decode into a tagged struct
+	tagDecode, tagErr := DecodeGroupNodeAs[isxdNamespace](bytes.NewReader(isxdBytes), node, decodePrimer)
+	Convey("Checking nodes can be decoded into tagged structs", t, func() {
+		Convey("decoding an ISXD descriptor into a struct with renamed, optional and skipped fields", func() {
+			Convey("The fields are decoded from their tagged properties", func() {
+				So(tagErr, ShouldBeNil)
+				So(tagDecode.Namespace, ShouldEqual, "example.com/test")
+				So(tagDecode.Rate, ShouldResemble, isxd.SampleRate)
+				So(tagDecode.Length, ShouldBeNil)
+				So(tagDecode.Local, ShouldBeEmpty)
+			})
+		})
+	})
+
+	_, missingErr := DecodeGroupNodeAs[isxdMissing](bytes.NewReader(isxdBytes), node, decodePrimer)
+	_, typoErr := DecodeGroupNodeAs[isxdTypo](bytes.NewReader(isxdBytes), node, decodePrimer)
+	_, mistypedErr := DecodeGroupNodeAs[isxdMistyped](bytes.NewReader(isxdBytes), node, decodePrimer)
+	_, notStructErr := DecodeGroupNodeAs[string](bytes.NewReader(isxdBytes), node, decodePrimer)
+
+	// decode an essence length of 300 into other integer fields
+	lengthPrimer := maps.Clone(decodePrimer)
+	lengthPrimer["3002"] = "060e2b34.01010101.04060102.00000000"
+	lengthBytes := append(bytes.Clone(isxdBytes), 0x30, 0x02, 0x00, 0x08, 0, 0, 0, 0, 0, 0, 0x01, 0x2c)
+	lengthBytes[16] += 12
+	lengthNode := &Node{Key: Position{End: 16}, Length: Position{Start: 16, End: 17}, Value: Position{Start: 17, End: len(lengthBytes)}}
+
+	wideDecode, wideErr := DecodeGroupNodeAs[isxdWideLength](bytes.NewReader(lengthBytes), lengthNode, lengthPrimer)
+	narrowDecode, narrowErr := DecodeGroupNodeAs[isxdNarrowLength](bytes.NewReader(lengthBytes), lengthNode, lengthPrimer)
+	Convey("Checking integer properties can be decoded into other integer fields", t, func() {
+		Convey("decoding an ISXD descriptor with an essence length of 300 into uint64 and int16 fields", func() {
+			Convey("The essence length is 300 in both fields", func() {
+				So(wideErr, ShouldBeNil)
+				So(wideDecode.EssenceLength, ShouldEqual, 300)
+				So(narrowErr, ShouldBeNil)
+				So(narrowDecode.EssenceLength, ShouldEqual, 300)
+			})
+		})
+	})
+
+	_, truncatedErr := DecodeGroupNodeAs[isxdTruncatedLength](bytes.NewReader(lengthBytes), lengthNode, lengthPrimer)
+	Convey("Checking integer properties are not truncated when decoded", t, func() {
+		Convey("decoding an ISXD descriptor with an essence length of 300 into a uint8 field", func() {
+			Convey("An overflow error is returned", func() {
+				So(truncatedErr, ShouldNotBeNil)
+				So(truncatedErr.Error(), ShouldContainSubstring, "the value 300 of mxf2go.TLengthType overflows uint8")
+			})
+		})
+	})
+
+	errs := []error{missingErr, typoErr, mistypedErr, notStructErr}
+	descs := []string{"a required field that is not in the descriptor", "a field that is not an ISXD property",
+		"a field with a different type to the property", "a string instead of a struct"}
+	for i, err := range errs {
+		Convey("Checking invalid structs can not be decoded into", t, func() {
+			Convey(fmt.Sprintf("decoding an ISXD descriptor into a struct with %s", descs[i]), func() {
+				Convey("An error is returned", func() {
+					So(err, ShouldNotBeNil)
+				})
+			})
+		})
+	}
+}
+
+func TestDecodeResult(t *testing.T) {
+	isxdKey := []byte{0x06, 0x0e, 0x2b, 0x34, 0x02, 0x53, 0x01, 0x05, 0x0e, 0x09, 0x05, 0x02, 0x00, 0x00, 0x00, 0x00}
+	primer := map[string]string{
+		"3001": "060e2b34.01010101.04060101.00000000", // SampleRate
+		"3c0a": "060e2b34.01010101.01011502.00000000", // InstanceID
+		"3203": "060e2b34.01010101.04010502.02000000", // StoredWidth
+	}
+
+	// a valid sample rate, then a stored width, a tag not in the primer,
+	// a repeated sample rate and a short instance ID
+	value := []byte{0x30, 0x01, 0x00, 0x08, 0, 0, 0, 25, 0, 0, 0, 1,
+		0x32, 0x03, 0x00, 0x04, 0, 0, 0, 1,
+		0x80, 0x01, 0x00, 0x01, 1,
+		0x30, 0x01, 0x00, 0x08, 0, 0, 0, 50, 0, 0, 0, 1,
+		0x3c, 0x0a, 0x00, 0x02, 0, 1}
+	group := &klv.KLV{Key: isxdKey, Length: []byte{byte(len(value))}, Value: value}
+
+	result, resultErr := DecodeGroupResult(group, primer)
+	strict, strictErr := DecodeGroupStrict(group, primer)
+	lenient, lenientErr := DecodeGroup(group, primer)
+
+	Convey("Checking the issues with each property are found when decoding a group", t, func() {
+		Convey("decoding an ISXD descriptor with an unknown property, a tag missing from the primer, a repeated property and a short property", func() {
+			Convey("Every issue is reported, with the valid values still decoded", func() {
+				So(resultErr, ShouldBeNil)
+				So(result.Values, ShouldResemble, map[string]any{"SampleRate": mxf2go.TRational{Numerator: 50, Denominator: 1}})
+				So(result.Issues, ShouldResemble, []DecodeIssue{
+					{LocalTag: "3203", UL: primer["3203"], Type: UnknownProperty, Reason: "not defined for the ISXD group"},
+					{LocalTag: "8001", Type: MissingPrimerTag},
+					{LocalTag: "3001", UL: primer["3001"], Name: "SampleRate", Type: RepeatedProperty},
+					{LocalTag: "3c0a", UL: primer["3c0a"], Name: "InstanceID", Type: LengthMismatch, Reason: "2 bytes instead of 16"},
+				})
+				So(result.Issues[3].String(), ShouldEqual, "InstanceID: length mismatch, 2 bytes instead of 16")
+			})
+		})
+	})
+
+	Convey("Checking the strict decoder fails on any property issues", t, func() {
+		Convey("decoding an ISXD descriptor with an unknown property, a tag missing from the primer, a repeated property and a short property", func() {
+			Convey("An error describing each issue is returned, the lenient decoder returns no error and keeps the short property", func() {
+				So(strictErr, ShouldNotBeNil)
+				So(strictErr.Error(), ShouldContainSubstring, "8001: tag missing from the primer")
+				So(strictErr.Error(), ShouldContainSubstring, "SampleRate: repeated property")
+				So(strict, ShouldResemble, result.Values)
+				So(lenientErr, ShouldBeNil)
+				So(lenient, ShouldResemble, map[string]any{"SampleRate": mxf2go.TRational{Numerator: 50, Denominator: 1}, "InstanceID": nil})
+			})
+		})
+	})
+}
+
+func TestPropertyValues(t *testing.T) {
+	doc, docErr := os.Open("./testdata/demoReports/goodISXD.mxf")
+	ast, genErr := MakeAST(doc, make(chan *klv.KLV, 1000), 10, *NewSpecification())
+	doc.Close()
+
+	Convey("Checking the decoded properties of a node are formatted as their search values", t, func() {
+		Convey("getting the property values of the timeline tracks of goodISXD.mxf", func() {
+			Convey("the edit rate is a rational and the instance UID is formatted as a UL", func() {
+				So(docErr, ShouldBeNil)
+				So(genErr, ShouldBeNil)
+
+				tracks, err := ast.Partitions[0].Search("select * from metadata where ul = TimelineTrack")
+				So(err, ShouldBeNil)
+				So(len(tracks), ShouldBeGreaterThan, 0)
+
+				for _, track := range tracks {
+					values := track.PropertyValues()
+					So(values["EditRate"], ShouldResemble, []string{"24/1"})
+					So(values["InstanceID"], ShouldHaveLength, 1)
+					So(strings.ReplaceAll(values["InstanceID"][0], ".", ""), ShouldEqual, track.Properties.ID())
+				}
+
+				So((&Node{}).PropertyValues(), ShouldBeNil)
+			})
+		})
+	})
+}
@@ -16,7 +16,10 @@ import (
 const ISXDDoc = "RDD47:2018"
 
 // ISXDSpecifications returns all the specifications
-// associated with ISXD
+// associated with ISXD.
+//
+// This is an example of writing specifications, the supported
+// ISXD specifications are found in the specs/rdd47 package.
 func ISXDSpecifications(sc mxftest.SniffContext) mxftest.Specifications {
 
 	return *mxftest.NewSpecification(
@@ -134,32 +137,27 @@ func testFrameWrapped(doc io.ReadSeeker, header *mxftest.PartitionNode) func(t m
 				t.Expect(len(badKeys)).Shall(Equal(0), fmt.Sprintf("%v other essence keys found", len(badKeys))),
 			)
 
-			if len(badKeys) != 0 {
-
-				fwPattern := header.Props.EssenceOrder
-				breakPoint := 0
-				// check each header against the pattern.
-				var extractErr error
-				//
-				for i, e := range header.Essence {
-					ess, err := mxftest.NodeToKLV(doc, e)
-					if mxftest.FullNameMask(ess.Key) != fwPattern[i%len(fwPattern)] {
-						breakPoint = e.Key.Start
-						break
-					}
-
-					if err != nil {
-						extractErr = err
-						break
-					}
-
+			fwPattern := header.Props.EssenceOrder
+			breakPoint := 0
+			// check each header against the pattern.
+			var extractErr error
+			for i, e := range header.Essence {
+				ess, err := mxftest.NodeToKLV(doc, &mxftest.Node{Key: e.Key})
+				if err != nil {
+					extractErr = err
+					break
 				}
 
-				t.Test("Checking that the content package order are regular throughout the essence stream", mxftest.NewSpecificationDetails(ISXDDoc, "7.5", "shall", 1),
-					t.Expect(extractErr).Shall(BeNil()),
-					t.Expect(breakPoint).Shall(Equal(0), fmt.Sprintf("irregular key found at byte offset %v", breakPoint)),
-				)
+				if mxftest.FullNameMask(ess.Key) != fwPattern[i%len(fwPattern)] {
+					breakPoint = e.Key.Start
+					break
+				}
 			}
+
+			t.Test("Checking that the content package order are regular throughout the essence stream", mxftest.NewSpecificationDetails(ISXDDoc, "7.5", "shall", 1),
+				t.Expect(extractErr).Shall(BeNil()),
+				t.Expect(breakPoint).Shall(Equal(0), fmt.Sprintf("irregular key found at byte offset %v", breakPoint)),
+			)
 		}
 	}
 }
@@ -190,9 +188,8 @@ func testGenericPartition(doc io.ReadSeeker, header *mxftest.PartitionNode) func
 		// 060e2b34.0101010c.0d010509.01000000 as the value is not used in the registers (yet?)
 		gpEssKey := "060e2b34.0101010c.0d010509.01000000"
 		invalidKeys, err := header.Search("select * from essence where ul <> " + gpEssKey)
-		// 09.01 - 1001 -little endin & 01 - makrer bit
-		// can be shown as this but is not in the essence
-		// 060e2b34.0101010c.0d01057f.7f000000
+		// the 09.01 flags of the key are decoded by mxftest.GenericStreamKeyExtract,
+		// as little endian, frame wrapped with the multi KLV marker bit set
 
 		t.Test("checking the essence keys all have the value of "+gpEssKey, mxftest.NewSpecificationDetails(ISXDDoc, "7.5", "shall", 1),
 			t.Expect(err).Shall(BeNil()),
@@ -239,46 +236,23 @@ func checkStructure(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test
 
 func checkDataTypes(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
 	return func(t mxftest.Test) {
-		nonXMLCount := 0
-
-		//
-
-		// loop through all the essence checking what data was found when
+		// search all the essence checking what data was found when
 		// we had a peak at it
-		partitions, bErr := mxf.Search("select * from partitions where essence <> 0")
-		var xmlSearchErr error
-		for _, parts := range partitions {
-			nonXml, xmlSearchErr := parts.Search(fmt.Sprintf("select * from essence where sniff:%s <> %s", mxftest.ContentTypeKey, xmlhandle.Content))
-			if xmlSearchErr != nil {
-				break
-			}
-			nonXMLCount += len(nonXml)
-		}
+		nonXML, xmlSearchErr := mxf.SearchNodes(fmt.Sprintf("select essence from partitions where essence.sniff:%s <> %s", mxftest.ContentTypeKey, xmlhandle.Content))
+		nonXMLCount := len(nonXML)
 
 		t.Test("Checking only xml data is contained in the ISXD file", mxftest.NewSpecificationDetails(ISXDDoc, "5.3", "shall", 1),
-			t.Expect(bErr).Shall(BeNil()),
 			t.Expect(xmlSearchErr).Shall(BeNil()),
 			t.Expect(nonXMLCount).Shall(Equal(0), fmt.Sprintf("%v non xml entries found", nonXMLCount)),
 		)
 
 		if nonXMLCount == 0 {
-			roots := make(map[string]bool)
-
-			// loop through all the essence checking what data was found when
-			// we had a peak at it
-			bodyParts, bErr := mxf.Search("select * from partitions where essence <> 0 and type <> " + mxftest.GenericStreamPartition)
-			for _, parts := range bodyParts {
-				for _, ess := range parts.Essence {
-					// checking the root
-					if snif, ok := ess.Sniffs["/*"]; ok {
-						roots[snif.Field] = true
-					}
-				}
-			}
+			// find every different root of the xml essence
+			roots, rErr := mxf.Query(fmt.Sprintf("select distinct sniff:/* from essence where type <> %s and sniff:/* like '*'", mxftest.GenericStreamPartition))
 
 			t.Test("Checking every XML file has the same root element", mxftest.NewSpecificationDetails(ISXDDoc, "5.3", "shall", 2),
-				t.Expect(bErr).Shall(BeNil()),
-				t.Expect(len(roots)).To(Equal(1), fmt.Sprintf("%v xml roots found, wanted 1", len(roots))),
+				t.Expect(rErr).Shall(BeNil()),
+				t.Expect(len(roots.Rows)).To(Equal(1), fmt.Sprintf("%v xml roots found, wanted 1", len(roots.Rows))),
 			)
 		}
 	}
@@ -322,7 +296,8 @@ func checkNameSpaces(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Tes
 				bodies, bErr := mxf.Search("select * from partitions where essence <> 0 AND type <> " + mxftest.GenericStreamPartition)
 				for _, b := range bodies {
 					// check each partition for
-					invalidNS, nsErr := b.Search("select * from essence where sniff:namespace-uri(/*) <> " + ns.(string))
+					var invalidNS []*mxftest.Node
+					invalidNS, nsErr = b.Search("select * from essence where sniff:namespace-uri(/*) <> " + mxftest.QuoteValue(ns.(string)))
 					if nsErr != nil {
 						break
 					}
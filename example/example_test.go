@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	mxftest "github.com/metarex-media/mxf-test"
@@ -15,7 +16,9 @@ func TestAST(t *testing.T) {
 
 	mxfToTest := []string{"../testdata/demoReports/goodISXD.mxf",
 		"../testdata/demoReports/veryBadISXD.mxf", "../testdata/demoReports/badISXD.mxf"}
-	for _, mxf := range mxfToTest {
+	// the number of essence namespaces that do not match the ISXD descriptors
+	invalidNamespaces := []int{0, 12, 24}
+	for i, mxf := range mxfToTest {
 		sc := mxftest.NewSniffContext()
 		doc, docErr := os.Open(mxf)
 		var buf bytes.Buffer
@@ -25,12 +28,24 @@ func TestAST(t *testing.T) {
 		f.Write(buf.Bytes())
 		//	fmt.Println(buf.String())
 
+		var report mxftest.Report
+		yamErr := yaml.Unmarshal(buf.Bytes(), &report)
+		nsFails := namespaceFailures(report)
+
 		Convey("Checking AST maps are consistent and are in the expected form", t, func() {
 			Convey(fmt.Sprintf("generating an AST of %s, which is saved as a yaml", mxf), func() {
 				Convey("The generated yaml matches the expected yaml", func() {
 
 					So(docErr, ShouldBeNil)
 					So(cre, ShouldBeNil)
+					So(yamErr, ShouldBeNil)
+					if invalidNamespaces[i] == 0 {
+						So(nsFails, ShouldBeEmpty)
+					} else {
+						So(nsFails, ShouldHaveLength, 1)
+						So(nsFails[0], ShouldStartWith, "expected 0 invalid namespaces")
+						So(nsFails[0], ShouldContainSubstring, fmt.Sprintf("} got %v\n", invalidNamespaces[i]))
+					}
 					//			So(yamErr, ShouldBeNil)
 					//			So(expecErr, ShouldBeNil)
 					//			So(fmt.Sprintf("%x", htest.Sum(nil)), ShouldResemble, fmt.Sprintf("%x", hnormal.Sum(nil)))
@@ -41,6 +56,27 @@ func TestAST(t *testing.T) {
 
 }
 
+// namespaceFailures returns the error messages of the
+// RDD47 5.3 namespace checks that failed.
+func namespaceFailures(report mxftest.Report) []string {
+	var fails []string
+	for _, section := range report.Tests {
+		for _, test := range section.Tests {
+			if !strings.Contains(test.Message, "RDD47:2018,5.3,shall,2") {
+				continue
+			}
+
+			for _, c := range test.Checks {
+				if !c.Pass {
+					fails = append(fails, c.ErrMessage)
+				}
+			}
+		}
+	}
+
+	return fails
+}
+
 func TestGPS(t *testing.T) {
 
 	mxfToTest := []string{"./testdata/gpsdemo.mxf"}
@@ -9,7 +9,10 @@ import (
 )
 
 // R133Specifications returns all the specifications
-// associated with 5.1 R133
+// associated with 5.1 R133.
+//
+// This is an example of writing specifications, the supported
+// R133 specifications are found in the specs/r133 package.
 func R133Specifications(sc mxftest.SniffContext) mxftest.Specifications {
 	return *mxftest.NewSpecification(
 		// Assign a sniff test
@@ -0,0 +1,93 @@
+package mxftest
+
+// GenericStreamKey contains the flags of an ST 410
+// generic stream data element key,
+// e.g. 060e2b34.0101010c.0d010509.01000000.
+//
+// The flags are found in bytes 12 and 13 of the key.
+type GenericStreamKey struct {
+	// The byte order of the data in the stream
+	ByteOrder string
+	// How the data is wrapped in the KLVs
+	Wrapping string
+	// Is the data split across multiple KLVs
+	MultiKLV bool
+	// Is the stream synchronised with the essence
+	EssenceSync bool
+}
+
+const (
+	// the byte order of a generic stream
+	ByteOrderUnknown = "unknown"
+	LittleEndian     = "littleendian"
+	BigEndian        = "bigendian"
+	ByteOrderInvalid = "invalid"
+
+	// the wrapping of a generic stream
+	WrappingUnknown = "unknown"
+	WrappingInvalid = "invalid"
+	FrameWrapped    = "frame"
+	ClipWrapped     = "clip"
+)
+
+// genericStreamKeyMask is the generic stream data element key,
+// with the version and flag bytes masked.
+const genericStreamKeyMask = "060e2b34.0101017f.0d01057f.7f000000"
+
+// IsGenericStreamKey checks if a key is an ST 410
+// generic stream data element key.
+func IsGenericStreamKey(key []byte) bool {
+	if len(key) != 16 {
+		return false
+	}
+
+	return FullNameMask(key, 7, 11, 12) == genericStreamKeyMask
+}
+
+/*
+GenericStreamKeyExtract decodes the flags of a generic stream data element key.
+False is returned if the key is not a generic stream data element key.
+
+The flags of byte 12 are:
+
+  - bits 0 and 1 - the byte order, 01 little endian and 10 big endian
+  - bits 2 and 3 - the wrapping, 10 frame wrapped and 11 clip wrapped
+
+The flags of byte 13 are:
+
+  - bit 0 - the multi KLV marker bit
+  - bit 1 - the essence sync bit
+*/
+func GenericStreamKeyExtract(key []byte) (GenericStreamKey, bool) {
+	if !IsGenericStreamKey(key) {
+		return GenericStreamKey{}, false
+	}
+
+	var gsk GenericStreamKey
+	switch key[11] & 0x03 {
+	case 0x00:
+		gsk.ByteOrder = ByteOrderUnknown
+	case 0x01:
+		gsk.ByteOrder = LittleEndian
+	case 0x02:
+		gsk.ByteOrder = BigEndian
+	default:
+		gsk.ByteOrder = ByteOrderInvalid
+	}
+
+	switch (key[11] >> 2) & 0x03 {
+	case 0x00:
+		gsk.Wrapping = WrappingUnknown
+	case 0x02:
+		gsk.Wrapping = FrameWrapped
+	case 0x03:
+		gsk.Wrapping = ClipWrapped
+	default:
+		gsk.Wrapping = WrappingInvalid
+	}
+
+	gsk.MultiKLV = key[12]&0x01 == 0x01
+	gsk.EssenceSync = key[12]&0x02 == 0x02
+
+	return gsk, true
+}
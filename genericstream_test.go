@@ -0,0 +1,54 @@
+package mxftest
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGenericStreamKeys(t *testing.T) {
+
+	keys := [][]byte{
+		{0x06, 0x0e, 0x2b, 0x34, 0x01, 0x01, 0x01, 0x0c, 0x0d, 0x01, 0x05, 0x09, 0x01, 0x00, 0x00, 0x00},
+		{0x06, 0x0e, 0x2b, 0x34, 0x01, 0x01, 0x01, 0x0c, 0x0d, 0x01, 0x05, 0x0d, 0x00, 0x00, 0x00, 0x00},
+		{0x06, 0x0e, 0x2b, 0x34, 0x01, 0x01, 0x01, 0x0c, 0x0d, 0x01, 0x05, 0x02, 0x03, 0x00, 0x00, 0x00},
+		{0x06, 0x0e, 0x2b, 0x34, 0x01, 0x01, 0x01, 0x0c, 0x0d, 0x01, 0x05, 0x07, 0x00, 0x00, 0x00, 0x00},
+	}
+	expected := []GenericStreamKey{
+		{ByteOrder: LittleEndian, Wrapping: FrameWrapped, MultiKLV: true},
+		{ByteOrder: LittleEndian, Wrapping: ClipWrapped},
+		{ByteOrder: BigEndian, Wrapping: WrappingUnknown, MultiKLV: true, EssenceSync: true},
+		{ByteOrder: ByteOrderInvalid, Wrapping: WrappingInvalid},
+	}
+
+	for i, key := range keys {
+		gsk, ok := GenericStreamKeyExtract(key)
+
+		Convey("Checking generic stream data element keys are decoded", t, func() {
+			Convey(fmt.Sprintf("decoding the key %s", FullNameMask(key)), func() {
+				Convey("The flags match the expected flags", func() {
+					So(ok, ShouldBeTrue)
+					So(gsk, ShouldResemble, expected[i])
+				})
+			})
+		})
+	}
+
+	badKeys := [][]byte{
+		{0x06, 0x0e, 0x2b, 0x34, 0x01, 0x02, 0x01, 0x01, 0x0f, 0x02, 0x01, 0x01, 0x05, 0x00, 0x00, 0x00},
+		{0x06, 0x0e, 0x2b, 0x34},
+	}
+
+	for _, key := range badKeys {
+		_, ok := GenericStreamKeyExtract(key)
+
+		Convey("Checking non generic stream keys are not decoded", t, func() {
+			Convey(fmt.Sprintf("decoding the key %v", key), func() {
+				Convey("The key is flagged as not a generic stream key", func() {
+					So(ok, ShouldBeFalse)
+				})
+			})
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package mxftest
+
+import (
+	"slices"
+	"strings"
+)
+
+// nodeIndex is the index of the nodes of a partition table,
+// so searches for a UL, label, InstanceID or sniffed value
+// only check the nodes that could match, rather than every node.
+//
+// The index stores the position of each node in the order the
+// table is searched, so indexed searches return the nodes in the same order.
+//
+// The AST is read only once it is made, so the index is not updated.
+// Only the count of top level nodes is checked before the index is used,
+// any other changes to the nodes are not seen by indexed searches.
+type nodeIndex struct {
+	// the count of top level nodes the index was made with,
+	// so an index is not used if nodes are added or removed
+	size int
+	// every node of the table, in the order they are searched
+	nodes []*Node
+	// the indexes of the node fields
+	ul, label, id ulIndex
+	// the positions of the nodes by their sniff key then sniff value
+	sniff map[string]map[string][]int
+}
+
+// ulIndex is the index of a field that contains ULs
+type ulIndex struct {
+	// the positions of the nodes by their UL key
+	keys map[string][]int
+	// the positions of the nodes by their lower case value,
+	// for values that are not ULs
+	text map[string][]int
+	// the positions of the nodes with a UL that can not
+	// be indexed, which are always searched
+	wild []int
+}
+
+// newNodeIndex indexes the nodes of a table, and all of their children
+func newNodeIndex(table []*Node) *nodeIndex {
+	idx := &nodeIndex{size: len(table), nodes: searchNodes(table, func(*Node) bool { return true }),
+		ul: newULIndex(), label: newULIndex(), id: newULIndex(), sniff: make(map[string]map[string][]int)}
+
+	for pos, n := range idx.nodes {
+		if n.Properties != nil {
+			idx.ul.add(pos, n.Properties.UL())
+			for _, label := range n.Properties.Label() {
+				idx.label.add(pos, label)
+			}
+
+			if id := n.Properties.ID(); id != "" {
+				idx.id.add(pos, id)
+			}
+		}
+
+		for key, sniff := range n.Sniffs {
+			if sniff == nil {
+				continue
+			}
+
+			if _, ok := idx.sniff[key]; !ok {
+				idx.sniff[key] = make(map[string][]int)
+			}
+			idx.sniff[key][sniff.Field] = append(idx.sniff[key][sniff.Field], pos)
+		}
+	}
+
+	return idx
+}
+
+// validIndex returns the index if it was made from the table,
+// otherwise nil is returned. A table with nodes added or removed
+// since it was indexed has to be searched without the index.
+func validIndex(idx *nodeIndex, table []*Node) *nodeIndex {
+	if idx == nil || idx.size != len(table) {
+		return nil
+	}
+
+	return idx
+}
+
+func newULIndex() ulIndex {
+	return ulIndex{keys: make(map[string][]int), text: make(map[string][]int)}
+}
+
+// ulKey is the key of a UL in an index. The version byte is removed and
+// bytes 5, 13 and 15, which are often masked, are replaced with 7f.
+// ok is false if the UL has any other 7f wildcards, as it can not be indexed.
+func ulKey(ul []byte) (key string, ok bool) {
+	masked := slices.Clone(ul)
+	masked[5], masked[7], masked[13], masked[15] = 0x7f, 0, 0x7f, 0x7f
+
+	for i, b := range masked {
+		if b == 0x7f && i != 5 && i != 13 && i != 15 {
+			return "", false
+		}
+	}
+
+	return string(masked), true
+}
+
+// add adds the value of a node to the index
+func (u ulIndex) add(pos int, value string) {
+	ul, ok := ulBytes(value)
+	if !ok {
+		u.text[strings.ToLower(value)] = append(u.text[strings.ToLower(value)], pos)
+		return
+	}
+
+	if key, ok := ulKey(ul); ok {
+		u.keys[key] = append(u.keys[key], pos)
+	} else {
+		u.wild = append(u.wild, pos)
+	}
+}
+
+// lookup returns the positions of the nodes that may have a value
+// equal to the target, when compared with the = operator.
+// ok is false if the target can not be found with the index.
+func (u ulIndex) lookup(target string, ulField bool) (positions []int, ok bool) {
+	if ul, isUL := ulBytes(target); isUL {
+		key, ok := ulKey(ul)
+		if !ok {
+			return nil, false
+		}
+
+		return append(slices.Clone(u.keys[key]), u.wild...), true
+	}
+
+	// values that are not ULs are only compared exactly
+	// for fields that are not ULs
+	if !ulField {
+		return nil, false
+	}
+
+	positions = append(slices.Clone(u.text[strings.ToLower(target)]), u.wild...)
+	for _, ul := range symbolULs(target) {
+		key, ok := ulKey(ul)
+		if !ok {
+			return nil, false
+		}
+		positions = append(positions, u.keys[key]...)
+	}
+
+	return positions, true
+}
+
+// lookup returns the positions of the nodes that may match a condition,
+// in the order they are searched. ok is false if the condition can not use the index,
+// and every node has to be searched.
+func (idx *nodeIndex) lookup(cond condition) (positions []int, ok bool) {
+	switch c := cond.(type) {
+	case *comparison:
+		if c.operator != "=" {
+			return nil, false
+		}
+
+		positions, ok = idx.fieldLookup(c.field, c.value)
+	case *logical:
+		left, leftOK := idx.lookup(c.left)
+		right, rightOK := idx.lookup(c.right)
+
+		switch {
+		case c.and && leftOK && rightOK:
+			// only nodes found by both sides can match
+			found := make(map[int]bool, len(left))
+			for _, pos := range left {
+				found[pos] = true
+			}
+			for _, pos := range right {
+				if found[pos] {
+					positions = append(positions, pos)
+				}
+			}
+			ok = true
+		case c.and && leftOK:
+			positions, ok = left, true
+		case c.and && rightOK:
+			positions, ok = right, true
+		case leftOK && rightOK:
+			positions, ok = append(left, right...), true
+		}
+	}
+
+	if !ok {
+		return nil, false
+	}
+
+	slices.Sort(positions)
+
+	return slices.Compact(positions), true
+}
+
+// fieldLookup finds the positions of the nodes which may have
+// a field equal to the value, for the fields that are indexed.
+func (idx *nodeIndex) fieldLookup(field, value string) ([]int, bool) {
+	switch {
+	case strings.EqualFold(field, "ul"):
+		return idx.ul.lookup(value, true)
+	case strings.EqualFold(field, "label"):
+		return idx.label.lookup(value, true)
+	case len(field) > 6 && strings.EqualFold(field[:6], "sniff:"):
+		// sniffed values that are ULs are matched as ULs,
+		// which is not indexed
+		values, ok := idx.sniff[field[6:]]
+		if _, isUL := ulBytes(value); isUL {
+			return nil, false
+		} else if !ok {
+			return nil, true
+		}
+
+		return slices.Clone(values[value]), true
+	}
+
+	if prop, ok := propertyName(field); ok && prop == "InstanceID" {
+		return idx.id.lookup(value, false)
+	}
+
+	return nil, false
+}
+
+// search returns the nodes of the index that match a condition,
+// ok is false if the index could not be used for the condition.
+func (idx *nodeIndex) search(cond condition, match func(*Node) bool) (found []*Node, ok bool) {
+	positions, ok := idx.lookup(cond)
+	if !ok {
+		return nil, false
+	}
+
+	found = make([]*Node, 0, len(positions))
+	for _, pos := range positions {
+		if match(idx.nodes[pos]) {
+			found = append(found, idx.nodes[pos])
+		}
+	}
+
+	return found, true
+}
+
+// candidates returns the nodes of the index that may match a condition,
+// or every node if the index could not be used for the condition.
+func (idx *nodeIndex) candidates(cond condition) []*Node {
+	positions, ok := idx.lookup(cond)
+	if !ok {
+		return idx.nodes
+	}
+
+	found := make([]*Node, len(positions))
+	for i, pos := range positions {
+		found[i] = idx.nodes[pos]
+	}
+
+	return found
+}
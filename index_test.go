@@ -0,0 +1,125 @@
+package mxftest
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/metarex-media/mrx-tool/klv"
+	mxf2go "github.com/metarex-media/mxf-to-go"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIndexedSearches(t *testing.T) {
+	doc, docErr := os.Open("./testdata/demoReports/goodISXD.mxf")
+	ast, genErr := MakeAST(doc, make(chan *klv.KLV, 1000), 10, *NewSpecification())
+	doc.Close()
+
+	Convey("Checking the partitions are indexed when the AST is made", t, func() {
+		Convey("generating an AST of goodISXD.mxf", func() {
+			Convey("Every partition has an index of the essence and metadata", func() {
+				So(docErr, ShouldBeNil)
+				So(genErr, ShouldBeNil)
+				for _, part := range ast.Partitions {
+					So(validIndex(part.essenceIndex, part.Essence), ShouldNotBeNil)
+					So(validIndex(part.metadataIndex, part.HeaderMetadata), ShouldNotBeNil)
+				}
+			})
+		})
+	})
+
+	preface := ast.Partitions[0].HeaderMetadata[1]
+	searches := []string{"select * from metadata where ul = Preface",
+		"select * from metadata where ul = 060e2b34.02530101.0d010101.01012f00",
+		"select * from metadata where ul = TimelineTrack or ul = Sequence",
+		"select * from metadata where ul = TimelineTrack and EditRate = 24/1",
+		"select * from metadata where label = 060e2b34.04010101.01030202.03000000",
+		"select * from metadata where InstanceUID = " + QuoteValue(preface.Properties.ID()),
+		"select * from metadata where ul = ISXD or ul = SourcePackage",
+		"select * from essence where ul = FrameWrappedISXDData",
+		"select * from essence where ul = 060e2b34.01020105.0e090502.01010101",
+		"select * from essence where label = essence and offset < 5000"}
+	indexed := []bool{true, true, true, true, true, true, true, true, true, true}
+
+	for i, search := range searches {
+		q, _ := parseSearch(search)
+
+		for _, part := range ast.Partitions {
+			found, err := part.Search(search)
+			// a partition without indexes searches every node
+			unindexed := *part
+			unindexed.essenceIndex, unindexed.metadataIndex = nil, nil
+			expected, expErr := unindexed.Search(search)
+
+			_, index, _ := part.table(q)
+			_, used := index.lookup(q.where)
+
+			Convey("Checking indexed searches find the same nodes as searching every node", t, func() {
+				Convey(fmt.Sprintf("running a search of %s on partition %v", search, part.PartitionPos), func() {
+					Convey("The same nodes are found in the same order", func() {
+						So(expErr, ShouldBeNil)
+						So(err, ShouldBeNil)
+						So(found, ShouldResemble, expected)
+						So(used, ShouldEqual, indexed[i])
+					})
+				})
+			})
+		}
+	}
+
+	counts := []int{1, 1, 7, 3, 9, 1}
+	for i, search := range searches[:len(counts)] {
+		found, err := ast.Partitions[0].Search(search)
+
+		Convey("Checking indexed searches find the expected nodes", t, func() {
+			Convey(fmt.Sprintf("running a search of %s on the header partition", search), func() {
+				Convey(fmt.Sprintf("%v nodes are found", counts[i]), func() {
+					So(err, ShouldBeNil)
+					So(len(found), ShouldEqual, counts[i])
+				})
+			})
+		})
+	}
+
+	// add a node to a copy of the header partition after it is indexed
+	extra := &Node{Properties: GroupProperties{UniversalLabel: mxf2go.GTextLocatorUL[13:]}}
+	changed := *ast.Partitions[0]
+	changed.HeaderMetadata = append(slices.Clip(changed.HeaderMetadata), extra)
+	changedQuery, _ := parseSearch("select * from metadata where ul = TextLocator")
+	_, changedIndex, _ := changed.table(changedQuery)
+	extraFound, extraErr := changed.Search("select * from metadata where ul = TextLocator")
+
+	Convey("Checking the index is not used once nodes are added to the table", t, func() {
+		Convey("searching the header metadata with a node added after the AST was made", func() {
+			Convey("Every node is searched and the added node is found", func() {
+				So(changedIndex, ShouldBeNil)
+				So(validIndex(ast.Partitions[0].metadataIndex, ast.Partitions[0].HeaderMetadata), ShouldNotBeNil)
+				So(extraErr, ShouldBeNil)
+				So(extraFound, ShouldResemble, []*Node{extra})
+			})
+		})
+	})
+
+	xmlNode := &Node{Sniffs: map[string]*SniffResult{"/*": {Field: "root"}}, Properties: EssenceProperties{EssUL: "060e2b34.01020105.0e090502.017f017f"}}
+	jsonNode := &Node{Sniffs: map[string]*SniffResult{"/*": {Field: "other"}}, Properties: EssenceProperties{EssUL: "060e2b34.01020105.0e090502.017f017f"}}
+	part := PartitionNode{Essence: []*Node{xmlNode, jsonNode, xmlNode}}
+	part.essenceIndex = newNodeIndex(part.Essence)
+
+	sniffed, sniffErr := part.Search("select * from essence where sniff:/* = root")
+	missing, missErr := part.Search("select * from essence where sniff:/root = root")
+	rows, rowErr := part.Query("select count(*) from essence where sniff:/* = other")
+
+	Convey("Checking sniffed values are indexed", t, func() {
+		Convey("searching essence by the sniffed root element", func() {
+			Convey("The nodes with the sniffed value are found", func() {
+				So(sniffErr, ShouldBeNil)
+				So(sniffed, ShouldResemble, []*Node{xmlNode, xmlNode})
+				So(missErr, ShouldBeNil)
+				So(missing, ShouldBeEmpty)
+				So(rowErr, ShouldBeNil)
+				So(rows.Rows, ShouldResemble, [][]string{{"1"}})
+			})
+		})
+	})
+}
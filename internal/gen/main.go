@@ -0,0 +1,142 @@
+// Command gen generates the required properties of the register
+// groups, from the IsOptional flags of the mxf2go group structs,
+// as the flags are not set in the mxf2go group definitions.
+//
+// It is run with go generate from the mxftest package.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const mxf2goModule = "github.com/metarex-media/mxf-to-go"
+
+func main() {
+	out := flag("-o", "required.go")
+
+	dir, err := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", mxf2goModule).Output()
+	if err != nil {
+		log.Fatalf("finding %s: %v", mxf2goModule, err)
+	}
+
+	groups, err := requiredProperties(filepath.Join(strings.TrimSpace(string(dir)), "groups.go"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := generate(groups)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// flag returns the value after an argument, or the default
+func flag(name, def string) string {
+	for i, arg := range os.Args[1 : len(os.Args)-1] {
+		if arg == name {
+			return os.Args[i+2]
+		}
+	}
+
+	return def
+}
+
+// group is a register group and its required properties
+type group struct {
+	name     string
+	required []string
+}
+
+// requiredProperties reads the properties of the group structs that are
+// marked as IsOptional:false, for every group that has a UL constant.
+func requiredProperties(path string) ([]group, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	uls := make(map[string]bool)
+	structs := make(map[string]*ast.StructType)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			switch s := spec.(type) {
+			case *ast.ValueSpec:
+				for _, name := range s.Names {
+					uls[name.Name] = true
+				}
+			case *ast.TypeSpec:
+				if st, ok := s.Type.(*ast.StructType); ok && strings.HasPrefix(s.Name.Name, "G") && strings.HasSuffix(s.Name.Name, "Struct") {
+					structs[strings.TrimSuffix(s.Name.Name[1:], "Struct")] = st
+				}
+			}
+		}
+	}
+
+	groups := make([]group, 0, len(structs))
+	for name, st := range structs {
+		if !uls["G"+name+"UL"] {
+			continue
+		}
+
+		g := group{name: name}
+		for _, field := range st.Fields.List {
+			if field.Comment == nil || !strings.Contains(field.Comment.Text(), "IsOptional:false") {
+				continue
+			}
+
+			for _, fieldName := range field.Names {
+				g.required = append(g.required, fieldName.Name)
+			}
+		}
+
+		if len(g.required) > 0 {
+			slices.Sort(g.required)
+			groups = append(groups, g)
+		}
+	}
+
+	slices.SortFunc(groups, func(a, b group) int { return strings.Compare(a.name, b.name) })
+
+	return groups, nil
+}
+
+// generate writes the go source of the required properties
+func generate(groups []group) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by internal/gen from the %s group structs. DO NOT EDIT.\n\n", mxf2goModule)
+	fmt.Fprintf(&buf, "package mxftest\n\nimport mxf2go %q\n\n", mxf2goModule)
+	fmt.Fprintf(&buf, "// registerRequired are the properties that shall be present in\n")
+	fmt.Fprintf(&buf, "// a group, mapped by the group UL. They are the properties that\n")
+	fmt.Fprintf(&buf, "// are not optional in the register.\n")
+	fmt.Fprintf(&buf, "var registerRequired = map[string][]string{\n")
+	for _, g := range groups {
+		fmt.Fprintf(&buf, "mxf2go.G%sUL[13:]: {%q", g.name, g.required[0])
+		for _, prop := range g.required[1:] {
+			fmt.Fprintf(&buf, ", %q", prop)
+		}
+		fmt.Fprintf(&buf, "},\n")
+	}
+	fmt.Fprintf(&buf, "}\n")
+
+	return format.Source(buf.Bytes())
+}
@@ -13,7 +13,7 @@ const (
 )
 
 // DataIdentifier is the JSON identifier function
-var DataIdentifier = mxftest.DataIdentifier{DataFunc: jSONIdentifier, ContentType: Content}
+var DataIdentifier = mxftest.DataIdentifier{DataFunc: jSONIdentifier, ContentType: Content, ID: "github.com/metarex-media/mxf-test/jsonhandle"}
 
 func jSONIdentifier(dataStream []byte) bool {
 	var js json.RawMessage
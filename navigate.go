@@ -0,0 +1,133 @@
+package mxftest
+
+import (
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+/*
+FindByInstanceUID returns the header metadata node of the partition with the InstanceUID,
+so strong and weak references can be followed to the group they point to.
+
+The uuid is hexadecimal, and can be split with "." or "-" and have a "urn:uuid:" prefix.
+e.g. "urn:uuid:9a2c4f7e-3b1d-4c8a-9e6f-0123456789ab"
+
+false is returned if no node has the InstanceUID. If more than one node has
+the InstanceUID, the last node in the file is returned, which is the node
+references are threaded to.
+*/
+func (p *PartitionNode) FindByInstanceUID(uuid string) (*Node, bool) {
+	id, ok := uuidBytes(uuid)
+	// groups without an InstanceUID have an ID of zeros
+	if !ok || !slices.ContainsFunc(id, func(b byte) bool { return b != 0 }) {
+		return nil, false
+	}
+
+	if p.instances != nil {
+		n, ok := p.instances[string(id)]
+		return n, ok && n != nil
+	}
+
+	// partitions that were not made by MakeAST have their nodes searched
+	target := hex.EncodeToString(id)
+	found := searchNodes(p.HeaderMetadata, func(n *Node) bool {
+		return n.Properties != nil && n.Properties.ID() == target
+	})
+	if len(found) == 0 {
+		return nil, false
+	}
+
+	return slices.MaxFunc(found, func(a, b *Node) int { return a.Key.Start - b.Key.Start }), true
+}
+
+// uuidBytes returns the bytes of a UUID string
+func uuidBytes(uuid string) ([]byte, bool) {
+	uuid = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(uuid)), "urn:uuid:")
+	uuid = strings.NewReplacer(".", "", "-", "").Replace(uuid)
+
+	b, err := hex.DecodeString(uuid)
+	if err != nil || len(b) != 16 {
+		return nil, false
+	}
+
+	return b, true
+}
+
+// Parent returns the node that references this node,
+// nil is returned if the node is a child of a partition,
+// such as top level metadata, essence and index tables.
+func (n *Node) Parent() *Node {
+	parent, _ := n.Tests.parent.(*Node)
+
+	return parent
+}
+
+// Partition returns the partition the node is found in,
+// nil is returned if the node is not part of an AST.
+func (n *Node) Partition() *PartitionNode {
+	for parent := n.Tests.parent; parent != nil; {
+		switch p := parent.(type) {
+		case *PartitionNode:
+			return p
+		case *Node:
+			parent = p.Tests.parent
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+/*
+Path returns the path of the node from the MXF node, which is the same
+as the path of the node when it is walked with Walk.
+
+e.g. /partition[1]/metadata/Preface[1]/ContentStorage[1] or /partition[3]/essence[6]
+
+Positions start at 1, and metadata is numbered among the groups of the same type,
+so the metadata steps of the path can be used with PartitionNode.SearchPath.
+
+A node referenced by more than one group only has the path through
+the group it is a child of, see Parent.
+An empty path is returned if the node is not part of an AST.
+*/
+func (n *Node) Path() string {
+	switch parent := n.Tests.parent.(type) {
+	case *Node:
+		base := parent.Path()
+		if base == "" || !slices.Contains(parent.Children, n) {
+			return ""
+		}
+
+		return fmt.Sprintf("%s/%s[%v]", base, walkName(n), pathPosition(parent.Children, n))
+	case *PartitionNode:
+		base := parent.path()
+		if parent.IndexTable == n {
+			return base + "/index"
+		}
+
+		if pos := slices.Index(parent.Essence, n); pos >= 0 {
+			return fmt.Sprintf("%s/essence[%v]", base, pos+1)
+		}
+
+		if slices.Contains(parent.HeaderMetadata, n) {
+			return fmt.Sprintf("%s/metadata/%s[%v]", base, walkName(n), pathPosition(parent.HeaderMetadata, n))
+		}
+	}
+
+	return ""
+}
+
+// path returns the path of the partition from the MXF node
+func (p *PartitionNode) path() string {
+	if p.Parent != nil {
+		if pos := slices.Index(p.Parent.Partitions, p); pos >= 0 {
+			return fmt.Sprintf("/partition[%v]", pos+1)
+		}
+	}
+
+	return fmt.Sprintf("/partition[%v]", p.PartitionPos+1)
+}
@@ -0,0 +1,133 @@
+package mxftest
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/metarex-media/mrx-tool/klv"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNodeNavigation(t *testing.T) {
+	doc, docErr := os.Open("./testdata/demoReports/goodISXD.mxf")
+	ast, genErr := MakeAST(doc, make(chan *klv.KLV, 1000), 10, *NewSpecification())
+	doc.Close()
+
+	type navigation struct {
+		item                 WalkItem
+		path                 string
+		parent               *Node
+		partition            *PartitionNode
+		expectedParent       *Node
+		expectedPartition    *PartitionNode
+		found, foundUnmapped *Node
+	}
+
+	navigations := make([]navigation, 0)
+	// nodes referenced by more than one group are walked more than once
+	walked := make(map[*Node]map[string]*Node)
+	walkErr := Walk(ast, Visitor{Enter: func(item WalkItem) error {
+		if item.Node == nil {
+			return nil
+		}
+
+		nav := navigation{item: item, path: item.Node.Path(), parent: item.Node.Parent(), partition: item.Node.Partition()}
+		for _, p := range item.Parents {
+			switch {
+			case p.Partition != nil:
+				nav.expectedPartition = p.Partition
+			case p.Node != nil:
+				nav.expectedParent = p.Node
+			}
+		}
+
+		if id := item.Node.Properties.ID(); id != "" {
+			nav.found, _ = nav.expectedPartition.FindByInstanceUID(id)
+			// search without the map of the ids
+			unmapped := *nav.expectedPartition
+			unmapped.instances = nil
+			nav.foundUnmapped, _ = unmapped.FindByInstanceUID(id)
+		}
+
+		if walked[item.Node] == nil {
+			walked[item.Node] = make(map[string]*Node)
+		}
+		walked[item.Node][item.Path] = nav.expectedParent
+		navigations = append(navigations, nav)
+
+		return nil
+	}})
+
+	Convey("Checking nodes can navigate up the AST", t, func() {
+		Convey("walking goodISXD.mxf and comparing the parents of each node to the walk", func() {
+			Convey("every node has a path and parent it was walked with, and the partition it was walked in", func() {
+				So(docErr, ShouldBeNil)
+				So(genErr, ShouldBeNil)
+				So(walkErr, ShouldBeNil)
+				So(len(navigations), ShouldBeGreaterThan, 0)
+
+				for _, nav := range navigations {
+					parent, ok := walked[nav.item.Node][nav.path]
+					So(ok, ShouldBeTrue)
+					So(nav.parent, ShouldEqual, parent)
+					So(nav.partition, ShouldEqual, nav.expectedPartition)
+				}
+			})
+		})
+	})
+
+	Convey("Checking nodes can be found by their InstanceUID", t, func() {
+		Convey("finding every node of goodISXD.mxf with an InstanceUID in its partition", func() {
+			Convey("the node is found, with and without the map of InstanceUIDs", func() {
+				ids := make(map[string]int)
+				for _, nav := range navigations {
+					ids[nav.item.Node.Properties.ID()]++
+				}
+
+				for _, nav := range navigations {
+					// nodes without an InstanceUID, which have an ID of zeros,
+					// or that share it with another node are skipped
+					if id := nav.item.Node.Properties.ID(); id != "" && ids[id] == 1 && strings.Trim(id, "0") != "" {
+						So(nav.found, ShouldEqual, nav.item.Node)
+						So(nav.foundUnmapped, ShouldEqual, nav.item.Node)
+					}
+				}
+			})
+		})
+	})
+
+	header := ast.Partitions[0]
+	preface := header.HeaderMetadata[1]
+	id := preface.Properties.ID()
+	uuids := []string{id,
+		fmt.Sprintf("urn:uuid:%s-%s-%s-%s-%s", id[:8], id[8:12], id[12:16], id[16:20], id[20:]),
+		fmt.Sprintf("%s.%s.%s.%s", id[:8], id[8:16], id[16:24], id[24:]),
+		"00000000.00000000.00000000.00000000", "not a uuid", id[:30]}
+	expected := []*Node{preface, preface, preface, nil, nil, nil}
+
+	for i, uuid := range uuids {
+		found, ok := header.FindByInstanceUID(uuid)
+
+		Convey("Checking the formats of InstanceUIDs that can be found", t, func() {
+			Convey(fmt.Sprintf("finding %s in the header partition", uuid), func() {
+				Convey("the preface is only found by its InstanceUID", func() {
+					So(found, ShouldEqual, expected[i])
+					So(ok, ShouldEqual, expected[i] != nil)
+				})
+			})
+		})
+	}
+
+	Convey("Checking nodes outside of an AST have no parents", t, func() {
+		Convey("navigating a node that has not been added to an AST", func() {
+			Convey("the node has no path, parent or partition", func() {
+				n := &Node{}
+				So(n.Path(), ShouldEqual, "")
+				So(n.Parent(), ShouldBeNil)
+				So(n.Partition(), ShouldBeNil)
+			})
+		})
+	})
+}
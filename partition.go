@@ -63,12 +63,20 @@ type Partition struct {
 	IndexSID          uint32
 	BodyOffset        uint64
 	BodySID           uint32
+	// the UL of the operational pattern
+	OperationalPattern string
+	// the ULs of the essence containers
+	EssenceContainers []string
 
 	// useful information from the partition
-	PartitionType     string
-	IndexTable        bool
-	TotalHeaderLength int
-	MetadataStart     int
+	PartitionType string
+	// the open/closed and complete/incomplete status of the partition
+	Status string
+	// the register symbol of the operational pattern
+	OperationalPatternName string
+	IndexTable             bool
+	TotalHeaderLength      int
+	MetadataStart          int
 }
 
 var (
@@ -84,6 +92,15 @@ const (
 	RIPPartition           = "rip"
 )
 
+const (
+	// the status of a partition, found from byte 14
+	// of the partition key.
+	OpenIncomplete   = "openincomplete"
+	ClosedIncomplete = "closedincomplete"
+	OpenComplete     = "opencomplete"
+	ClosedComplete   = "closedcomplete"
+)
+
 // PartitionExtract extracts the partition from a KLV packet
 func PartitionExtract(partitionKLV *klv.KLV) Partition {
 
@@ -98,16 +115,19 @@ func PartitionExtract(partitionKLV *klv.KLV) Partition {
 	case 02:
 		// header
 		partPack.PartitionType = HeaderPartition
+		partPack.Status = partitionStatus(partitionKLV.Key[14])
 	case 03:
 		// body
 		if partitionKLV.Key[14] == 17 {
 			partPack.PartitionType = GenericStreamPartition
 		} else {
 			partPack.PartitionType = BodyPartition
+			partPack.Status = partitionStatus(partitionKLV.Key[14])
 		}
 	case 04:
 		// footer
 		partPack.PartitionType = FooterPartition
+		partPack.Status = partitionStatus(partitionKLV.Key[14])
 	default:
 		// is nothing
 		partPack.PartitionType = "invalid"
@@ -130,6 +150,28 @@ func PartitionExtract(partitionKLV *klv.KLV) Partition {
 	partPack.BodyOffset = order.Uint64(partitionKLV.Value[52:60:60])
 	partPack.BodySID = order.Uint32(partitionKLV.Value[60:64:64])
 
+	// the operational pattern and the batch of essence containers
+	partPack.EssenceContainers = make([]string, 0)
+	if len(partitionKLV.Value) >= 80 {
+		partPack.OperationalPattern = fullName(partitionKLV.Value[64:80:80])
+		if label, ok := LabelLookUp(partPack.OperationalPattern); ok {
+			partPack.OperationalPatternName = label.Symbol
+		}
+	}
+
+	if len(partitionKLV.Value) >= 88 {
+		count := int(order.Uint32(partitionKLV.Value[80:84:84]))
+		itemLength := int(order.Uint32(partitionKLV.Value[84:88:88]))
+
+		// only decode the batch if it is the expected size
+		if itemLength == 16 && len(partitionKLV.Value) >= 88+count*itemLength {
+			for i := 0; i < count; i++ {
+				start := 88 + i*itemLength
+				partPack.EssenceContainers = append(partPack.EssenceContainers, fullName(partitionKLV.Value[start:start+itemLength]))
+			}
+		}
+	}
+
 	kag := int(partPack.SizeKAG)
 	headerLength := int(partPack.HeaderByteCount)
 	indexLength := int(partPack.IndexByteCount)
@@ -158,8 +200,41 @@ func PartitionExtract(partitionKLV *klv.KLV) Partition {
 	return partPack
 }
 
+// partitionStatus returns the status of a partition from the
+// 14th byte of the partition key.
+func partitionStatus(status byte) string {
+	switch status {
+	case 0x01:
+		return OpenIncomplete
+	case 0x02:
+		return ClosedIncomplete
+	case 0x03:
+		return OpenComplete
+	case 0x04:
+		return ClosedComplete
+	default:
+		return "invalid"
+	}
+}
+
 // RIP is the random index position struct
 type RIP struct {
 	Sid        uint32
 	ByteOffset uint64
 }
+
+// RIPExtract extracts the partition entries and the overall length
+// from a Random Index Pack KLV.
+func RIPExtract(ripKLV *klv.KLV) ([]RIP, uint32) {
+	if len(ripKLV.Value) < 4 {
+		return []RIP{}, 0
+	}
+
+	value := ripKLV.Value
+	entries := make([]RIP, 0, (len(value)-4)/12)
+	for pos := 0; pos+12 <= len(value)-4; pos += 12 {
+		entries = append(entries, RIP{Sid: order.Uint32(value[pos : pos+4]), ByteOffset: order.Uint64(value[pos+4 : pos+12])})
+	}
+
+	return entries, order.Uint32(value[len(value)-4:])
+}
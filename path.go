@@ -0,0 +1,398 @@
+package mxftest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	mxf2go "github.com/metarex-media/mxf-to-go"
+)
+
+// pathStep is a single step of a path query
+type pathStep struct {
+	// descendant is true for // steps
+	descendant bool
+	symbol     string
+	// the groups the symbol matches, which is
+	// empty for * and any group is matched
+	groups     []mxf2go.GroupID
+	predicates []pathPredicate
+}
+
+// pathPredicate is a filter of the nodes matched by a step,
+// which either picks a position or matches a condition.
+type pathPredicate struct {
+	position int
+	match    func(*Node) bool
+}
+
+// parsePath parses a path query into its steps
+func parsePath(path string) ([]pathStep, error) {
+	runes := []rune(path)
+	steps := make([]pathStep, 0)
+	pos := 0
+
+	for {
+		step := pathStep{}
+		switch {
+		case strings.HasPrefix(string(runes[pos:]), "//"):
+			step.descendant = true
+			pos += 2
+		case pos < len(runes) && runes[pos] == '/':
+			pos++
+		case pos > 0:
+			return nil, &SyntaxError{Query: path, Column: pos + 1, Msg: fmt.Sprintf("expected \"/\" but found %q", string(runes[pos]))}
+		}
+
+		start := pos
+		for pos < len(runes) && runes[pos] != '/' && runes[pos] != '[' {
+			pos++
+		}
+		step.symbol = strings.TrimSpace(string(runes[start:pos]))
+
+		if step.symbol == "" {
+			found := "the end of the path"
+			if pos < len(runes) {
+				found = fmt.Sprintf("%q", string(runes[pos]))
+			}
+
+			return nil, &SyntaxError{Query: path, Column: start + 1, Msg: fmt.Sprintf("expected a group symbol but found %s", found)}
+		}
+
+		if step.symbol != "*" {
+			step.groups = symbolGroups(step.symbol)
+			if len(step.groups) == 0 {
+				return nil, &SyntaxError{Query: path, Column: start + 1, Msg: fmt.Sprintf("unknown group %q", step.symbol)}
+			}
+		}
+
+		for pos < len(runes) && runes[pos] == '[' {
+			end, err := predicateEnd(path, runes, pos)
+			if err != nil {
+				return nil, err
+			}
+
+			pred, err := parsePredicate(path, runes, pos+1, end, step)
+			if err != nil {
+				return nil, err
+			}
+
+			step.predicates = append(step.predicates, pred)
+			pos = end + 1
+		}
+
+		steps = append(steps, step)
+
+		if pos >= len(runes) {
+			return steps, nil
+		}
+	}
+}
+
+// predicateEnd finds the closing bracket of the predicate starting at pos,
+// skipping any brackets in quoted strings.
+func predicateEnd(path string, runes []rune, pos int) (int, error) {
+	depth := 0
+	for i := pos; i < len(runes); i++ {
+		switch runes[i] {
+		case '\'', '"':
+			_, end, err := lexString(path, runes, i)
+			if err != nil {
+				return 0, &SyntaxError{Query: path, Column: i + 1, Msg: "unterminated quoted string"}
+			}
+			i = end - 1
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+
+	return 0, &SyntaxError{Query: path, Column: pos + 1, Msg: "expected \"]\" to close the \"[\""}
+}
+
+// parsePredicate parses the predicate between start and end,
+// which is either a position or a condition.
+func parsePredicate(path string, runes []rune, start, end int, step pathStep) (pathPredicate, error) {
+	text := strings.TrimSpace(string(runes[start:end]))
+	if text == "" {
+		return pathPredicate{}, &SyntaxError{Query: path, Column: start + 1, Msg: "expected a position or condition but found \"]\""}
+	}
+
+	if position, err := strconv.Atoi(text); err == nil {
+		if position < 1 {
+			return pathPredicate{}, &SyntaxError{Query: path, Column: start + 1, Msg: fmt.Sprintf("positions start at 1 but found %v", position)}
+		}
+
+		return pathPredicate{position: position}, nil
+	}
+
+	// pad the predicate so the columns of any errors are the columns of the path
+	tokens, err := lexQuery(strings.Repeat(" ", start) + string(runes[start:end]))
+	if err != nil {
+		if syntaxErr, ok := err.(*SyntaxError); ok {
+			syntaxErr.Query = path
+		}
+
+		return pathPredicate{}, err
+	}
+
+	p := &queryParser{text: path, tokens: tokens}
+	cond, err := p.parseOr()
+	if err != nil {
+		return pathPredicate{}, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return pathPredicate{}, p.errorf(tok, "unexpected %v", tok)
+	}
+
+	match, err := compileCondition(&query{text: path}, cond, pathField(step.symbol))
+	if err != nil {
+		return pathPredicate{}, err
+	}
+
+	return pathPredicate{match: match}, nil
+}
+
+// pathField returns the fields of a node in a path predicate
+func pathField(symbol string) func(name string) (queryField[*Node], bool) {
+	return func(name string) (queryField[*Node], bool) {
+		if strings.EqualFold(name, "@type") {
+			return queryField[*Node]{ul: true, values: func(n *Node) []string {
+				group, ok := nodeGroup(n)
+				if !ok {
+					return nil
+				}
+
+				// the type without the step symbol e.g. Material for MaterialPackage
+				if short := len(group.Name) - len(symbol); symbol != "*" && short > 0 && strings.EqualFold(group.Name[short:], symbol) {
+					return []string{group.Name, group.Name[:short]}
+				}
+
+				return []string{group.Name}
+			}}, true
+		}
+
+		return nodeField(strings.TrimPrefix(name, "@"))
+	}
+}
+
+// symbolGroups returns the group definitions of a symbol
+func symbolGroups(symbol string) []mxf2go.GroupID {
+	groups := make([]mxf2go.GroupID, 0)
+	for _, ul := range symbolULs(symbol) {
+		if group, ok := GroupLookUp(ul); ok && strings.EqualFold(group.Name, symbol) {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
+
+// nodeGroup returns the group definition of a node
+func nodeGroup(n *Node) (mxf2go.GroupID, bool) {
+	key, ok := ulBytes(n.Properties.UL())
+	if !ok {
+		return mxf2go.GroupID{}, false
+	}
+
+	return GroupLookUp(key)
+}
+
+// abstractGroups are the ST 377-1 groups that are only found as
+// the groups that inherit from them, e.g. Package for MaterialPackage.
+// A group inherits from an abstract group if it has every property of
+// the abstract group.
+var abstractGroups = map[string]bool{
+	"interchangeobject":     true,
+	"package":               true,
+	"track":                 true,
+	"component":             true,
+	"segment":               true,
+	"essencedescriptor":     true,
+	"filedescriptor":        true,
+	"picturedescriptor":     true,
+	"sounddescriptor":       true,
+	"dataessencedescriptor": true,
+	"subdescriptor":         true,
+	"locator":               true,
+	"descriptiveframework":  true,
+	"descriptiveobject":     true,
+	"definitionobject":      true,
+}
+
+// matches checks if a node is one of the groups of the step
+func (s pathStep) matches(n *Node) bool {
+	if s.symbol == "*" {
+		return true
+	}
+
+	group, ok := nodeGroup(n)
+	if !ok {
+		return false
+	}
+
+	for _, target := range s.groups {
+		if strings.EqualFold(group.Name, target.Name) {
+			return true
+		}
+
+		// only abstract groups are matched by their properties
+		if !abstractGroups[strings.ToLower(target.Name)] {
+			continue
+		}
+
+		kind := true
+		for prop := range target.Group {
+			if _, ok := group.Group[prop]; !ok {
+				kind = false
+				break
+			}
+		}
+
+		if kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// searchPath runs the steps of a path from the children of the root
+func searchPath(root *Node, steps []pathStep) []*Node {
+	context := []*Node{root}
+
+	for _, step := range steps {
+		next := make([]*Node, 0)
+		found := make(map[*Node]bool)
+
+		for _, parent := range context {
+			var candidates []*Node
+			if step.descendant {
+				candidates = searchNodes(parent.Children, step.matches)
+			} else {
+				for _, child := range parent.Children {
+					if child != nil && step.matches(child) {
+						candidates = append(candidates, child)
+					}
+				}
+			}
+
+			for _, pred := range step.predicates {
+				candidates = pred.filter(candidates)
+			}
+
+			for _, candidate := range candidates {
+				if !found[candidate] {
+					found[candidate] = true
+					next = append(next, candidate)
+				}
+			}
+		}
+
+		context = next
+	}
+
+	return context
+}
+
+// filter returns the nodes that match the predicate
+func (p pathPredicate) filter(nodes []*Node) []*Node {
+	if p.match == nil {
+		if p.position > len(nodes) {
+			return nil
+		}
+
+		return nodes[p.position-1 : p.position]
+	}
+
+	out := make([]*Node, 0, len(nodes))
+	for _, n := range nodes {
+		if p.match(n) {
+			out = append(out, n)
+		}
+	}
+
+	return out
+}
+
+// pathPosition returns the position of a node in a path, which is the position
+// of the node among the siblings with the same path name, starting at 1.
+// This is the position SearchPath picks with [n].
+func pathPosition(siblings []*Node, n *Node) int {
+	name := walkName(n)
+	step := pathStep{symbol: name, groups: symbolGroups(name)}
+
+	pos := 0
+	for _, sibling := range siblings {
+		if sibling == nil {
+			continue
+		}
+
+		// groups that are not in the register are matched by their UL
+		if (len(step.groups) > 0 && step.matches(sibling)) || (len(step.groups) == 0 && walkName(sibling) == name) {
+			pos++
+		}
+
+		if sibling == n {
+			return pos
+		}
+	}
+
+	return pos
+}
+
+/*
+SearchPath finds the descendants of the node that match a path query,
+where the first step of the path is a child of the node.
+
+e.g. staticTrack.SearchPath("Sequence/SourceClip")
+
+See PartitionNode.SearchPath for the path query syntax.
+*/
+func (n Node) SearchPath(path string) ([]*Node, error) {
+	steps, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return searchPath(&n, steps), nil
+}
+
+/*
+SearchPath finds the header metadata nodes of the partition that match a path query,
+where the first step of the path is a top level header metadata group, such as the Preface.
+
+e.g. Preface/ContentStorage/Package[@type=Material]/Track/Sequence
+
+  - / separates the steps of the path, with each step a child of the previous step
+  - // matches any descendant of the previous step, e.g. //EssenceDescriptor
+  - * matches a group of any type
+  - [n] picks the nth match of the step, starting at 1
+  - [condition] filters the step with a where condition of the query language,
+    e.g. //EssenceDescriptor[ContainerFormat = 060e2b34.04010105.0e090607.01010103 and SampleRate = 24/1]
+
+Groups match a step if they have the symbol of the step, or inherit from the group
+of the step. So abstract groups such as Package or EssenceDescriptor
+match MaterialPackage and ISXD descriptors.
+
+The predicate fields are the same as Search, with an optional @ prefix,
+and @type, which is the symbol of the group. @type can be given without
+the symbol of the step, e.g. Package[@type=Material] matches MaterialPackage.
+
+The paths given by Walk and Node.Path use the same positions, so the metadata
+steps of a path, e.g. /Preface[1]/ContentStorage[1] of
+/partition[1]/metadata/Preface[1]/ContentStorage[1], find the node in its partition.
+*/
+func (p PartitionNode) SearchPath(path string) ([]*Node, error) {
+	steps, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return searchPath(&Node{Children: p.HeaderMetadata}, steps), nil
+}
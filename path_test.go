@@ -0,0 +1,144 @@
+package mxftest
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/metarex-media/mrx-tool/klv"
+	mxf2go "github.com/metarex-media/mxf-to-go"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPathSearches(t *testing.T) {
+	doc, docErr := os.Open("./testdata/demoReports/goodISXD.mxf")
+	ast, genErr := MakeAST(doc, make(chan *klv.KLV, 1000), 10, *NewSpecification())
+	doc.Close()
+
+	paths := []string{"Preface/ContentStorage/Package[@type=Material]/Track/Sequence",
+		"//EssenceDescriptor[ContainerFormat = 060e2b34.04010105.0e090607.01010103]",
+		"Preface/ContentStorage/Package",
+		"//Package[2]",
+		"/Preface/*",
+		"//StaticTrack",
+		"//Track[EditRate = 24/1]",
+		"Preface//Track[@type <> Timeline]",
+		"//SourcePackage/*[@ul = ISXD and @DataEssenceCoding = 060e2b34.04010105.0e090606.00000000]"}
+	expected := [][]string{{mxf2go.GSequenceUL[13:]},
+		{"060e2b34.02530105.0e090502.00000000"},
+		{mxf2go.GMaterialPackageUL[13:], mxf2go.GSourcePackageUL[13:]},
+		{mxf2go.GSourcePackageUL[13:]},
+		{mxf2go.GContentStorageUL[13:], mxf2go.GIdentificationUL[13:]},
+		{},
+		{mxf2go.GTimelineTrackUL[13:], mxf2go.GTimelineTrackUL[13:], mxf2go.GTimelineTrackUL[13:]},
+		{},
+		{"060e2b34.02530105.0e090502.00000000"}}
+
+	for i, path := range paths {
+		found, err := ast.Partitions[0].SearchPath(path)
+
+		uls := make([]string, len(found))
+		for j, f := range found {
+			uls[j] = f.Properties.UL()
+		}
+
+		Convey("Checking the header metadata can be searched by paths of group symbols", t, func() {
+			Convey(fmt.Sprintf("searching the header metadata of goodISXD.mxf with %s", path), func() {
+				Convey("The groups at the end of the path are found", func() {
+					So(docErr, ShouldBeNil)
+					So(genErr, ShouldBeNil)
+					So(err, ShouldBeNil)
+					So(uls, ShouldResemble, expected[i])
+				})
+			})
+		})
+	}
+
+	prefaces, prefErr := ast.Partitions[0].SearchPath("Preface")
+	var packages []*Node
+	var packErr error
+	if len(prefaces) == 1 {
+		packages, packErr = prefaces[0].SearchPath("ContentStorage/Package[@type = Source]")
+	}
+
+	Convey("Checking paths can be searched from a node", t, func() {
+		Convey("searching the preface of goodISXD.mxf for the source package", func() {
+			Convey("The source package is found", func() {
+				So(prefErr, ShouldBeNil)
+				So(len(prefaces), ShouldEqual, 1)
+				So(packErr, ShouldBeNil)
+				So(len(packages), ShouldEqual, 1)
+				So(packages[0].Properties.UL(), ShouldEqual, mxf2go.GSourcePackageUL[13:])
+			})
+		})
+	})
+
+	// search for every walked metadata node with the metadata steps
+	// of its walked path and of the path of the node
+	var walked, resolved int
+	var unresolved []string
+	walkErr := Walk(ast, Visitor{Enter: func(item WalkItem) error {
+		if item.Kind != WalkMetadata {
+			return nil
+		}
+
+		walked++
+		found := 0
+		for _, path := range []string{item.Path, item.Node.Path()} {
+			_, metaPath, _ := strings.Cut(path, "/metadata")
+			nodes, err := item.Parents[1].Partition.SearchPath(metaPath)
+			if err == nil && len(nodes) == 1 && nodes[0] == item.Node {
+				found++
+			}
+		}
+
+		if found == 2 {
+			resolved++
+		} else {
+			unresolved = append(unresolved, item.Path)
+		}
+
+		return nil
+	}})
+
+	Convey("Checking the walked paths can be searched", t, func() {
+		Convey("searching the partitions of goodISXD.mxf with the metadata path of every walked node, and the path of the node", func() {
+			Convey("Each path finds the walked node", func() {
+				So(walkErr, ShouldBeNil)
+				So(walked, ShouldBeGreaterThan, 0)
+				So(unresolved, ShouldBeEmpty)
+				So(resolved, ShouldEqual, walked)
+			})
+		})
+	})
+
+	badPaths := []string{"Preface/Bad",
+		"Preface[",
+		"Preface[ul <=> 1]",
+		"Preface/[1]",
+		"//Package[0]",
+		"//Package[foo = 1]",
+		"Preface//"}
+	badErrors := []string{"unknown group \"Bad\" at column 9",
+		"expected \"]\" to close the \"[\" at column 8",
+		"unknown comparison operator \"<=>\" at column 12",
+		"expected a group symbol but found \"[\" at column 9",
+		"positions start at 1 but found 0 at column 11",
+		"unknown field \"foo\" at column 11",
+		"expected a group symbol but found the end of the path at column 10"}
+
+	for i, bp := range badPaths {
+		found, err := ast.Partitions[0].SearchPath(bp)
+
+		Convey("Checking invalid paths return syntax errors", t, func() {
+			Convey(fmt.Sprintf("searching with a path of %s", bp), func() {
+				Convey("A syntax error is returned with the column of the error", func() {
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldEqual, badErrors[i])
+					So(found, ShouldBeEmpty)
+				})
+			})
+		})
+	}
+}
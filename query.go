@@ -0,0 +1,752 @@
+package mxftest
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+/*
+The search query language is shared by the Node, PartitionNode and MXFNode
+Search and Query functions, and follows the form
+
+	select [distinct] columns [from table] [where condition] [group by columns]
+
+Search functions only select *, the columns, distinct and group by are used
+by the Query functions for tabular results.
+
+Conditions are comparisons of a field and a value, e.g. ul = 060e2b34.027f0101.0d010101.01010f00,
+which can be combined with and, or, not and parentheses. Values with spaces,
+quotes or any of the characters ()=<>!, are wrapped in single or double quotes,
+a quote is escaped by repeating it or with a backslash.
+Values that are added to a query from elsewhere, e.g. a decoded property,
+should be quoted with QuoteValue.
+
+Values are compared with the operators:
+
+  - = and <>, or !=, compare values. The UL fields (ul, label, operationalpattern
+    and essencecontainer) and properties that are ULs are matched with MatchUL,
+    so the version byte is ignored and 7f is a wildcard. UL fields can also be compared
+    to the symbolic name of a UL, e.g. ul = ISXD or ul = ISXDDataEssenceDescriptor.
+    The partition type and status are compared without case, e.g. type = Footer.
+    Other values, such as InstanceUID and sniff values, are compared exactly.
+  - == compares the exact value
+  - <, >, <= and >= compare numbers
+  - like and not like match a pattern, where * matches any characters.
+    ULs are matched by prefix e.g. ul like 060e2b34.0101010c.0d0105*, for fields that
+    are not ULs the prefix has to start with 060e2b34 or be grouped with dots.
+
+Keywords, tables and fields are not case sensitive, values are only
+compared without case when they are ULs, symbols, or the partition type and status.
+*/
+
+// QuoteValue quotes a value so it can be added to a query,
+// whatever characters the value contains.
+//
+// e.g. "select * from essence where sniff:/* = " + QuoteValue(root)
+func QuoteValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(value) + "'"
+}
+
+// SyntaxError is an error in a search query,
+// with the column of the query the error was found at.
+type SyntaxError struct {
+	// Query is the search query
+	Query string
+	// Column is the position of the error in the query, starting at 1
+	Column int
+	// Msg describes the error
+	Msg string
+}
+
+// Error returns the error message, with the column it was found at
+func (s *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at column %v", s.Msg, s.Column)
+}
+
+type tokenType int
+
+const (
+	tokenWord tokenType = iota
+	tokenString
+	tokenOperator
+	tokenOpenParen
+	tokenCloseParen
+	tokenComma
+	tokenEOF
+)
+
+// token is a lexed part of the query
+type token struct {
+	kind   tokenType
+	text   string
+	column int
+}
+
+// is checks if the token is the keyword, ignoring the case
+func (t token) is(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "the end of the query"
+	}
+
+	return fmt.Sprintf("%q", t.text)
+}
+
+// operatorChars are the characters that make up comparison operators
+const operatorChars = "=<>!"
+
+// lexQuery splits a query into its tokens
+func lexQuery(query string) ([]token, error) {
+	runes := []rune(query)
+	tokens := make([]token, 0)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		column := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenOpenParen, text: "(", column: column})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenCloseParen, text: ")", column: column})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", column: column})
+			i++
+		case r == '\'' || r == '"':
+			text, end, err := lexString(query, runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, column: column})
+			i = end
+		case strings.ContainsRune(operatorChars, r):
+			start := i
+			for i < len(runes) && strings.ContainsRune(operatorChars, runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: string(runes[start:i]), column: column})
+		default:
+			text, end, err := lexWord(query, runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: text, column: column})
+			i = end
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, column: len(runes) + 1}), nil
+}
+
+// lexString reads a quoted string starting at pos,
+// returning the unquoted string and the position after the closing quote.
+func lexString(query string, runes []rune, pos int) (string, int, error) {
+	quote := runes[pos]
+	var out strings.Builder
+
+	for i := pos + 1; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\' && i+1 < len(runes):
+			i++
+			out.WriteRune(runes[i])
+		case runes[i] == quote && i+1 < len(runes) && runes[i+1] == quote:
+			// repeated quotes are an escaped quote
+			i++
+			out.WriteRune(quote)
+		case runes[i] == quote:
+			return out.String(), i + 1, nil
+		default:
+			out.WriteRune(runes[i])
+		}
+	}
+
+	return "", 0, &SyntaxError{Query: query, Column: pos + 1, Msg: "unterminated quoted string"}
+}
+
+// lexWord reads a word starting at pos, returning the word and the position after it.
+// Parentheses within a word are kept as part of the word, e.g. sniff:namespace-uri(/*),
+// and quoted sections are added to the word without their quotes, e.g. sniff:"/a[@b='c']".
+func lexWord(query string, runes []rune, pos int) (string, int, error) {
+	var out strings.Builder
+	depth := 0
+	i := pos
+
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case r == '\'' || r == '"':
+			text, end, err := lexString(query, runes, i)
+			if err != nil {
+				return "", 0, err
+			}
+			out.WriteString(text)
+			i = end
+			continue
+		case depth > 0:
+		case unicode.IsSpace(r), r == ')', r == ',', strings.ContainsRune(operatorChars, r):
+			return out.String(), i, nil
+		}
+
+		out.WriteRune(r)
+		i++
+	}
+
+	if depth > 0 {
+		return "", 0, &SyntaxError{Query: query, Column: pos + 1, Msg: fmt.Sprintf("unclosed parenthesis in %q", string(runes[pos:]))}
+	}
+
+	return out.String(), i, nil
+}
+
+// query is a parsed search query
+type query struct {
+	text string
+	// the selected columns
+	columns  []column
+	distinct bool
+	// the group by columns, which are nil if there is no group by statement
+	groupBy     []column
+	groupColumn int
+	// the table, which is empty if no from statement was used
+	table       string
+	tableColumn int
+	// where is nil if there is no where statement
+	where condition
+}
+
+// column is a selected column of a query
+type column struct {
+	name   string
+	column int
+}
+
+// condition is a part of the where statement of a query
+type condition interface {
+	// comparisons returns every comparison within the condition
+	comparisons() []*comparison
+}
+
+// comparison is a field being compared to a value
+type comparison struct {
+	field, operator, value string
+	// the columns of the field and value
+	column, valueColumn int
+}
+
+// logical joins two conditions with and or or
+type logical struct {
+	and         bool
+	left, right condition
+}
+
+// negation inverts a condition
+type negation struct {
+	cond condition
+}
+
+func (c *comparison) comparisons() []*comparison { return []*comparison{c} }
+
+func (l *logical) comparisons() []*comparison {
+	return append(l.left.comparisons(), l.right.comparisons()...)
+}
+
+func (n *negation) comparisons() []*comparison { return n.cond.comparisons() }
+
+// queryParser is a recursive descent parser of the query tokens
+type queryParser struct {
+	text   string
+	tokens []token
+	pos    int
+}
+
+// parseQuery parses a search query
+func parseQuery(text string) (*query, error) {
+	tokens, err := lexQuery(text)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{text: text, tokens: tokens}
+
+	return p.parse()
+}
+
+func (p *queryParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+func (p *queryParser) errorf(tok token, format string, args ...any) error {
+	return &SyntaxError{Query: p.text, Column: tok.column, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *queryParser) parse() (*query, error) {
+	q := &query{text: p.text}
+
+	if tok := p.next(); !tok.is("select") {
+		return nil, p.errorf(tok, "expected \"select\" but found %v", tok)
+	}
+
+	if p.peek().is("distinct") {
+		p.next()
+		q.distinct = true
+	}
+
+	columns, err := p.parseColumns()
+	if err != nil {
+		return nil, err
+	}
+	q.columns = columns
+
+	if p.peek().is("from") {
+		p.next()
+		tok := p.next()
+		if tok.kind != tokenWord || isKeyword(tok) {
+			return nil, p.errorf(tok, "expected a table but found %v", tok)
+		}
+		q.table, q.tableColumn = strings.ToLower(tok.text), tok.column
+	}
+
+	if p.peek().is("where") {
+		p.next()
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		q.where = cond
+	}
+
+	if group := p.peek(); group.is("group") {
+		p.next()
+		if tok := p.next(); !tok.is("by") {
+			return nil, p.errorf(tok, "expected \"by\" but found %v", tok)
+		}
+
+		columns, err := p.parseColumns()
+		if err != nil {
+			return nil, err
+		}
+		q.groupBy, q.groupColumn = columns, group.column
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %v", tok)
+	}
+
+	return q, nil
+}
+
+// parseColumns parses a comma separated list of columns
+func (p *queryParser) parseColumns() ([]column, error) {
+	columns := make([]column, 0)
+	for {
+		tok := p.next()
+		if tok.kind != tokenWord || isKeyword(tok) {
+			return nil, p.errorf(tok, "expected a column but found %v", tok)
+		}
+		columns = append(columns, column{name: tok.text, column: tok.column})
+
+		if p.peek().kind != tokenComma {
+			return columns, nil
+		}
+		p.next()
+	}
+}
+
+// selectAll checks the query selects every column, with select *
+func (q *query) selectAll() error {
+	switch {
+	case q.distinct:
+		return &SyntaxError{Query: q.text, Column: q.columns[0].column, Msg: "distinct can only be used with Query"}
+	case q.groupBy != nil:
+		return &SyntaxError{Query: q.text, Column: q.groupColumn, Msg: "group by can only be used with Query"}
+	case q.columns[0].name != "*":
+		return &SyntaxError{Query: q.text, Column: q.columns[0].column, Msg: fmt.Sprintf("expected \"*\" but found %q", q.columns[0].name)}
+	case len(q.columns) > 1:
+		return &SyntaxError{Query: q.text, Column: q.columns[1].column, Msg: "only * can be selected"}
+	}
+
+	return nil
+}
+
+// parseSearch parses a query that selects every column,
+// as used by the Search functions.
+func parseSearch(text string) (*query, error) {
+	q, err := parseQuery(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return q, q.selectAll()
+}
+
+func (p *queryParser) parseOr() (condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().is("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logical{and: false, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (condition, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().is("and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logical{and: true, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseNot() (condition, error) {
+	if p.peek().is("not") {
+		p.next()
+		cond, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return &negation{cond: cond}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (condition, error) {
+	if p.peek().kind == tokenOpenParen {
+		open := p.next()
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if tok := p.next(); tok.kind != tokenCloseParen {
+			return nil, p.errorf(tok, "expected \")\" to close the \"(\" at column %v but found %v", open.column, tok)
+		}
+
+		return cond, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *queryParser) parseComparison() (condition, error) {
+	field := p.next()
+	if field.kind != tokenWord || isKeyword(field) {
+		return nil, p.errorf(field, "expected a field but found %v", field)
+	}
+
+	op := p.next()
+	switch {
+	case op.is("like"):
+		op.text = "like"
+	case op.is("not") && p.peek().is("like"):
+		p.next()
+		op.text = "not like"
+	case op.kind != tokenOperator:
+		return nil, p.errorf(op, "expected a comparison operator after %v but found %v", field, op)
+	}
+
+	switch op.text {
+	case "=", "==", "<>", "!=", "<", ">", "<=", ">=", "like", "not like":
+	default:
+		return nil, p.errorf(op, "unknown comparison operator %q", op.text)
+	}
+
+	value := p.next()
+	if value.kind != tokenWord && value.kind != tokenString {
+		return nil, p.errorf(value, "expected a value after %v but found %v", op, value)
+	}
+
+	return &comparison{field: field.text, operator: op.text, value: value.text,
+		column: field.column, valueColumn: value.column}, nil
+}
+
+// isKeyword checks if a token is a reserved word of the query language
+func isKeyword(tok token) bool {
+	for _, keyword := range []string{"select", "distinct", "from", "where", "and", "or", "not", "like", "group", "by"} {
+		if tok.is(keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// queryField is a field that can be searched on
+type queryField[T any] struct {
+	// values returns the values of the field for a search target,
+	// a comparison matches if any of the values match.
+	values func(T) []string
+	// ul fields are ULs, or their symbols, which are compared
+	// without case and can be matched by their symbolic name
+	ul bool
+	// ulOf reports if the values of a search target are ULs, for fields
+	// that are only ULs for some targets e.g. the decoded properties of a group
+	ulOf func(T) bool
+	// fold fields have a fixed set of values, e.g. the partition type,
+	// which are compared without case, apart from with ==
+	fold bool
+}
+
+// compileCondition converts a condition into a function that tests a search target,
+// where fields returns the field for a field name.
+func compileCondition[T any](q *query, cond condition, fields func(name string) (queryField[T], bool)) (func(T) bool, error) {
+	switch c := cond.(type) {
+	case nil:
+		return func(T) bool { return true }, nil
+	case *negation:
+		inner, err := compileCondition(q, c.cond, fields)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(target T) bool { return !inner(target) }, nil
+	case *logical:
+		left, err := compileCondition(q, c.left, fields)
+		if err != nil {
+			return nil, err
+		}
+		right, err := compileCondition(q, c.right, fields)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.and {
+			return func(target T) bool { return left(target) && right(target) }, nil
+		}
+
+		return func(target T) bool { return left(target) || right(target) }, nil
+	case *comparison:
+		field, ok := fields(c.field)
+		if !ok {
+			return nil, &SyntaxError{Query: q.text, Column: c.column, Msg: fmt.Sprintf("unknown field %q", c.field)}
+		}
+
+		switch c.operator {
+		case "<", ">", "<=", ">=":
+			return compileNumeric(q, c, field)
+		}
+
+		// values of fold fields are compared in lower case
+		if field.fold && c.operator != "==" {
+			folded := *c
+			folded.value = strings.ToLower(c.value)
+			c = &folded
+		}
+
+		// the matchers for when the values are, and are not, ULs
+		var equal, ulEqual func(v string) bool
+		switch c.operator {
+		case "==":
+			equal = func(v string) bool { return v == c.value }
+			ulEqual = equal
+		case "like", "not like":
+			var err error
+			if equal, err = likeMatcher(q, c, false); err != nil {
+				return nil, err
+			}
+			if ulEqual, err = likeMatcher(q, c, true); err != nil {
+				return nil, err
+			}
+		default:
+			equal = valueMatcher(c.value, false)
+			ulEqual = valueMatcher(c.value, true)
+		}
+
+		matches := func(target T) bool {
+			match := equal
+			if field.ul || (field.ulOf != nil && field.ulOf(target)) {
+				match = ulEqual
+			}
+
+			for _, v := range field.values(target) {
+				if field.fold && c.operator != "==" {
+					v = strings.ToLower(v)
+				}
+
+				if match(v) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		switch c.operator {
+		case "=", "==", "like":
+			return matches, nil
+		}
+
+		// <>, != and not like match if none of the values match
+		return func(target T) bool { return !matches(target) }, nil
+	default:
+		return nil, fmt.Errorf("unknown condition type %T", cond)
+	}
+}
+
+// compileNumeric converts a <, >, <= or >= comparison into a function,
+// which matches if any value of the field is a number that satisfies the comparison.
+// Numbers can be integers, decimals or fractions such as 25/1.
+func compileNumeric[T any](q *query, c *comparison, field queryField[T]) (func(T) bool, error) {
+	target, ok := new(big.Rat).SetString(c.value)
+	if !ok {
+		return nil, &SyntaxError{Query: q.text, Column: c.valueColumn, Msg: fmt.Sprintf("expected a number after %q but found %q", c.operator, c.value)}
+	}
+
+	var satisfies func(cmp int) bool
+	switch c.operator {
+	case "<":
+		satisfies = func(cmp int) bool { return cmp < 0 }
+	case ">":
+		satisfies = func(cmp int) bool { return cmp > 0 }
+	case "<=":
+		satisfies = func(cmp int) bool { return cmp <= 0 }
+	default:
+		satisfies = func(cmp int) bool { return cmp >= 0 }
+	}
+
+	return func(t T) bool {
+		for _, v := range field.values(t) {
+			// values that are not numbers do not match
+			num, ok := new(big.Rat).SetString(v)
+			if ok && satisfies(num.Cmp(target)) {
+				return true
+			}
+		}
+
+		return false
+	}, nil
+}
+
+// valueMatcher returns the function for matching values to the value of a comparison.
+// UL fields match ULs with MatchUL, and also match the symbolic name of the UL,
+// e.g. ISXD, with values compared without case.
+// Any other 16 byte values, such as UUIDs, have to match exactly.
+func valueMatcher(target string, ulField bool) func(string) bool {
+	targetUL, isUL := ulBytes(target)
+
+	if !ulField {
+		return func(v string) bool {
+			if v == target {
+				return true
+			}
+
+			// the same UUID written differently e.g. with dots
+			valueUL, ok := ulBytes(v)
+
+			return isUL && ok && bytes.Equal(valueUL, targetUL)
+		}
+	}
+
+	if isUL {
+		return func(v string) bool {
+			valueUL, ok := ulBytes(v)
+
+			return ok && matchULBytes(valueUL, targetUL)
+		}
+	}
+
+	symbols := symbolULs(target)
+
+	return func(v string) bool {
+		if strings.EqualFold(v, target) {
+			return true
+		}
+
+		valueUL, ok := ulBytes(v)
+		if !ok {
+			return false
+		}
+
+		for _, symbol := range symbols {
+			if matchULBytes(valueUL, symbol) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// likeMatcher returns the function for matching values to the pattern of a like comparison.
+// ULs are matched by their prefix, e.g. 060e2b34.0101010c.0d0105*, with the version byte ignored
+// and 7f as a wildcard. For fields that are not ULs, only patterns that start with 060e2b34
+// or are grouped with dots are UL prefixes. Any other values are matched with * as the
+// wildcard for any characters.
+func likeMatcher(q *query, c *comparison, ulField bool) (func(string) bool, error) {
+	// a lone * matches every value, not just ULs
+	if prefix, ok := ulPattern(c.value); ok && len(prefix) > 0 && (ulField || ulLike(c.value)) {
+		return func(v string) bool {
+			valueUL, ok := ulBytes(v)
+
+			return ok && matchULPrefix(valueUL, prefix)
+		}, nil
+	}
+
+	parts := strings.Split(c.value, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	expr := "^" + strings.Join(parts, ".*") + "$"
+	if ulField {
+		expr = "(?i)" + expr
+	}
+
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, &SyntaxError{Query: q.text, Column: c.valueColumn, Msg: fmt.Sprintf("invalid like pattern %q", c.value)}
+	}
+
+	return pattern.MatchString, nil
+}
+
+// ulLike checks if a like pattern is written as a UL,
+// rather than any value that only has hex digits e.g. cafe*
+func ulLike(pattern string) bool {
+	pattern = strings.TrimPrefix(strings.ToLower(pattern), "urn:smpte:ul:")
+
+	return strings.HasPrefix(pattern, "060e2b34") || strings.Contains(pattern, ".")
+}
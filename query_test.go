@@ -0,0 +1,604 @@
+package mxftest
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/metarex-media/mrx-tool/klv"
+	mxf2go "github.com/metarex-media/mxf-to-go"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestQueryLanguage(t *testing.T) {
+
+	targetNode := &Node{Sniffs: map[string]*SniffResult{"/root/title": {Field: "a title with spaces"}}, Properties: EssenceProperties{EssUL: "060e2b34.027f0101.0d010101.01010f00"}}
+	otherNode := &Node{Sniffs: map[string]*SniffResult{"/root/title": {Field: "it's a title"}}, Properties: EssenceProperties{EssUL: "060e2b34.027f0101.0d010101.01011800"}}
+	parentNode := &Node{Children: []*Node{targetNode, otherNode}, Properties: EssenceProperties{EssUL: "060e2b34.027f0101.0d010101.01013000"}}
+
+	searches := []string{"select * where sniff:/root/title = \"a title with spaces\"",
+		"select * where sniff:/root/title = 'it''s a title'",
+		"SELECT * WHERE ul = 060E2B34.027F0101.0D010101.01010F00 or ul = 060e2b34.027f0101.0d010101.01011800",
+		"select * where not ul = 060e2b34.027f0101.0d010101.01010f00",
+		"select * where not (ul = 060e2b34.027f0101.0d010101.01010f00 or sniff:/root/title = 'it\\'s a title')",
+		"select * where sniff:/root/title <> 'a title with spaces' and (ul = 060e2b34.027f0101.0d010101.01011800 or ul = 060e2b34.027f0101.0d010101.01010f00)",
+		"select *"}
+
+	expected := [][]*Node{
+		{targetNode},
+		{otherNode},
+		{targetNode, otherNode},
+		{otherNode},
+		{},
+		{otherNode},
+		{targetNode, otherNode},
+	}
+
+	for i, s := range searches {
+		found, err := parentNode.Search(s)
+
+		Convey("Checking the search query language", t, func() {
+			Convey(fmt.Sprintf("running a search of %s", s), func() {
+				Convey("No error is returned and the expected nodes are returned", func() {
+					So(err, ShouldBeNil)
+					So(found, ShouldResemble, expected[i])
+				})
+			})
+		})
+	}
+
+	dummyPart := &PartitionNode{HeaderMetadata: []*Node{parentNode}, Props: PartitionProperties{PartitionType: string(Header)}}
+	partFound, partErr := dummyPart.Search("SELECT * FROM Metadata WHERE sniff:/root/title = 'a title with spaces'")
+
+	dummyMXF := MXFNode{Partitions: []*PartitionNode{dummyPart, {Props: PartitionProperties{PartitionType: BodyPartition}}, {Props: PartitionProperties{PartitionType: "footer"}}}}
+	mxfFound, mxfErr := dummyMXF.Search("select * from partitions where type = body or (metadata <> 0 and not type = footer)")
+
+	Convey("Checking the query language is shared across the node levels", t, func() {
+		Convey("running searches with upper case keywords, quoted values and boolean logic on a partition and an mxf node", func() {
+			Convey("The expected nodes and partitions are returned", func() {
+				So(partErr, ShouldBeNil)
+				So(partFound, ShouldResemble, []*Node{targetNode})
+				So(mxfErr, ShouldBeNil)
+				So(mxfFound, ShouldResemble, []*PartitionNode{dummyPart, dummyMXF.Partitions[1]})
+			})
+		})
+	})
+
+	quotedNode := &Node{Sniffs: map[string]*SniffResult{"/root/title": {Field: `{"a":1, "b":'it\'s'}`}}}
+	quotedParent := &Node{Children: []*Node{quotedNode, otherNode}}
+	quotedFound, quotedErr := quotedParent.Search("select * where sniff:/root/title = " + QuoteValue(`{"a":1, "b":'it\'s'}`))
+
+	Convey("Checking values can be quoted before they are added to a query", t, func() {
+		Convey("running a search for a value with commas, quotes and backslashes", func() {
+			Convey("No error is returned and only the node with the value is returned", func() {
+				So(quotedErr, ShouldBeNil)
+				So(quotedFound, ShouldResemble, []*Node{quotedNode})
+			})
+		})
+	})
+
+	badSearches := []string{"select * where sniff:/root/title = 'unterminated",
+		"select * where (ul = 060e2b34.027f0101.0d010101.01010f00",
+		"select * where ul = 060e2b34.027f0101.0d010101.01010f00 and",
+		"select * where ul 060e2b34.027f0101.0d010101.01010f00",
+		"select * where ul = 060e2b34.027f0101.0d010101.01010f00 )",
+		"select ul where ul = 060e2b34.027f0101.0d010101.01010f00",
+		"select * from metadata where ul = 060e2b34.027f0101.0d010101.01010f00"}
+	badErrors := []string{"unterminated quoted string at column 36",
+		"expected \")\" to close the \"(\" at column 16 but found the end of the query at column 57",
+		"expected a field but found the end of the query at column 60",
+		"expected a comparison operator after \"ul\" but found \"060e2b34.027f0101.0d010101.01010f00\" at column 19",
+		"unexpected \")\" at column 57",
+		"expected \"*\" but found \"ul\" at column 8",
+		"nodes do not have tables, remove the from statement at column 15"}
+
+	for i, bs := range badSearches {
+		found, err := parentNode.Search(bs)
+
+		Convey("Checking the search query language returns syntax errors", t, func() {
+			Convey(fmt.Sprintf("running a search of %s", bs), func() {
+				Convey("A syntax error is returned with the column of the error", func() {
+					So(err, ShouldHaveSameTypeAs, &SyntaxError{})
+					So(err.Error(), ShouldEqual, badErrors[i])
+					So(found, ShouldBeEmpty)
+				})
+			})
+		})
+	}
+}
+
+func TestPropertySearches(t *testing.T) {
+	doc, docErr := os.Open("./testdata/demoReports/goodISXD.mxf")
+	ast, genErr := MakeAST(doc, make(chan *klv.KLV, 1000), 10, *NewSpecification())
+	doc.Close()
+
+	Convey("Checking the demo file is parsed for searching", t, func() {
+		Convey("generating an AST of goodISXD.mxf", func() {
+			Convey("No error is returned", func() {
+				So(docErr, ShouldBeNil)
+				So(genErr, ShouldBeNil)
+			})
+		})
+	})
+
+	searches := []string{"select * from metadata where DataEssenceCoding = 060E2B34.04010105.0E090606.00000000",
+		"select * from metadata where namespaceuriutf8 = 'http://www.example.com'",
+		"select * from metadata where EditRate = 24/1",
+		"select * from metadata where EssenceTrackNumber > 0",
+		"select * from metadata where SampleRate >= 23.976 and SampleRate < 25",
+		"select * from metadata where ContainerDuration > 0"}
+	expectedCounts := []int{1, 1, 3, 1, 1, 0}
+
+	for i, s := range searches {
+		found, err := ast.Partitions[0].Search(s)
+
+		Convey("Checking the decoded properties of the header metadata can be searched", t, func() {
+			Convey(fmt.Sprintf("running a search of %s", s), func() {
+				Convey("No error is returned and the expected nodes are returned", func() {
+					So(err, ShouldBeNil)
+					So(len(found), ShouldEqual, expectedCounts[i])
+				})
+			})
+		})
+	}
+
+	_, numErr := ast.Partitions[0].Search("select * from metadata where SampleRate > fast")
+	Convey("Checking numeric comparisons require numbers", t, func() {
+		Convey("running a search of select * from metadata where SampleRate > fast", func() {
+			Convey("A syntax error is returned at the value", func() {
+				So(numErr, ShouldNotBeNil)
+				So(numErr.Error(), ShouldEqual, "expected a number after \">\" but found \"fast\" at column 43")
+			})
+		})
+	})
+}
+
+func TestULSearches(t *testing.T) {
+	doc, docErr := os.Open("./testdata/demoReports/goodISXD.mxf")
+	ast, genErr := MakeAST(doc, make(chan *klv.KLV, 1000), 10, *NewSpecification())
+	doc.Close()
+
+	Convey("Checking the demo file is parsed for searching", t, func() {
+		Convey("generating an AST of goodISXD.mxf", func() {
+			Convey("No error is returned", func() {
+				So(docErr, ShouldBeNil)
+				So(genErr, ShouldBeNil)
+			})
+		})
+	})
+
+	// the preface is 060e2b34.027f0101.0d010101.01012f00 in the AST
+	searches := []string{"select * from metadata where ul = " + QuoteValue(mxf2go.GISXDUL),
+		"select * from metadata where ul = 060e2b34.02530101.0d010101.01012f00",
+		"select * from metadata where ul = 060e2b34.0253010d.0d010101.01012f00",
+		"select * from metadata where ul == 060e2b34.02530101.0d010101.01012f00",
+		"select * from metadata where ul = isxd or ul = Preface",
+		"select * from metadata where ul <> Preface",
+		"select * from metadata where ul like 060e2b34.027f0101.0d010101.0101*",
+		"select * from metadata where ul not like 060e2b34.027f0101.0d010101.0101*"}
+	expectedCounts := []int{1, 1, 1, 0, 2, 19, 17, 3}
+
+	for i, s := range searches {
+		found, err := ast.Partitions[0].Search(s)
+
+		Convey("Checking ULs are searched with the version byte ignored, wildcards and symbols", t, func() {
+			Convey(fmt.Sprintf("running a search of %s", s), func() {
+				Convey("No error is returned and the expected nodes are returned", func() {
+					So(err, ShouldBeNil)
+					So(len(found), ShouldEqual, expectedCounts[i])
+				})
+			})
+		})
+	}
+
+	essence, essErr := ast.Partitions[1].Search("select * from essence where ul = FrameWrappedISXDData")
+	ops, opErr := ast.Search("select * from partitions where op like mxfop1a* and operationalpattern = MXFOP1aSingleItemSinglePackageUniTrackNonStreamInternal")
+
+	Convey("Checking essence and partitions are searched by symbol", t, func() {
+		Convey("searching for the FrameWrappedISXDData essence and the OP1a partitions of goodISXD.mxf", func() {
+			Convey("Every essence and partition pack is found", func() {
+				So(essErr, ShouldBeNil)
+				So(len(essence), ShouldEqual, 24)
+				So(opErr, ShouldBeNil)
+				So(len(ops), ShouldEqual, 3)
+			})
+		})
+	})
+
+	pairs := [][2]string{{"060e2b34.02530101.0d010101.01012f00", "060e2b34.027f0101.0d010101.01012f00"},
+		{"060e2b34.04010101.0d010201.01010500", "060e2b34.0401010d.0d010201.01010500"},
+		{"060e2b34.04010101.0d010201.01010500", "urn:smpte:ul:060e2b34.04010101.0d010201.01010100"},
+		{"060e2b34.04010101.0d010201.01010500", "not a ul"}}
+	matches := []bool{true, true, false, false}
+
+	for i, pair := range pairs {
+		Convey("Checking ULs are matched", t, func() {
+			Convey(fmt.Sprintf("matching %s and %s", pair[0], pair[1]), func() {
+				Convey(fmt.Sprintf("The match is %v", matches[i]), func() {
+					So(MatchUL(pair[0], pair[1]), ShouldEqual, matches[i])
+				})
+			})
+		})
+	}
+
+	// the same InstanceUID with byte 8 changed, which only ULs ignore
+	preface, _ := ast.Partitions[0].Search("select * from metadata where ul = Preface")
+	id := preface[0].Properties.ID()
+	versioned := id[:14] + "ff" + id[16:]
+
+	exactSearches := []string{"select * from metadata where InstanceUID = " + QuoteValue(id),
+		"select * from metadata where InstanceUID = " + QuoteValue(versioned),
+		"select * from metadata where DataEssenceCoding = 060e2b34.0401010d.0e090606.00000000"}
+	exactCounts := []int{1, 0, 1}
+
+	for i, s := range exactSearches {
+		found, err := ast.Partitions[0].Search(s)
+
+		Convey("Checking only the UL fields and properties are searched as ULs", t, func() {
+			Convey(fmt.Sprintf("running a search of %s", s), func() {
+				Convey("No error is returned and the expected nodes are returned", func() {
+					So(err, ShouldBeNil)
+					So(len(found), ShouldEqual, exactCounts[i])
+				})
+			})
+		})
+	}
+
+	cafe := &Node{Sniffs: map[string]*SniffResult{"/root/name": {Field: "cafeteria"}}}
+	parentNode := &Node{Children: []*Node{cafe}}
+	likeSearches := []string{"select * where sniff:/root/name like cafe*",
+		"select * where sniff:/root/name like caf*",
+		"select * where sniff:/root/name like *fe*",
+		"select * where sniff:/root/name like CAFE*",
+		"select * where sniff:/root/name like '*'"}
+	likeCounts := []int{1, 1, 1, 0, 1}
+
+	for i, s := range likeSearches {
+		found, err := parentNode.Search(s)
+
+		Convey("Checking like patterns of hex digits are only UL prefixes for UL fields", t, func() {
+			Convey(fmt.Sprintf("running a search of %s", s), func() {
+				Convey("No error is returned and the expected nodes are returned", func() {
+					So(err, ShouldBeNil)
+					So(len(found), ShouldEqual, likeCounts[i])
+				})
+			})
+		})
+	}
+
+	allMetadata, _ := ast.Partitions[0].Search("select * from metadata where ul like 060e2b34*")
+	allULs, allErr := ast.Partitions[0].Search("select * from metadata where ul like '*'")
+
+	Convey("Checking a lone * matches every UL", t, func() {
+		Convey("running a search of select * from metadata where ul like '*'", func() {
+			Convey("No error is returned and every metadata node is returned", func() {
+				So(allErr, ShouldBeNil)
+				So(len(allULs), ShouldEqual, len(allMetadata))
+			})
+		})
+	})
+
+	isxd, isxdErr := ast.Partitions[0].Search("select * from metadata where ul = ISXD")
+	described, describedErr := ast.Partitions[0].Search("select * from metadata where ul = ISXDDataEssenceDescriptor")
+	unknown, unknownErr := ast.Partitions[0].Search("select * from metadata where ul = NotASymbol")
+
+	Convey("Checking UL fields can be searched by the register and document names of groups", t, func() {
+		Convey("running a search of select * from metadata where ul = ISXDDataEssenceDescriptor", func() {
+			Convey("The ISXD descriptor is found, and names that are not symbols match nothing", func() {
+				So(isxdErr, ShouldBeNil)
+				So(isxd, ShouldHaveLength, 1)
+				So(describedErr, ShouldBeNil)
+				So(described, ShouldResemble, isxd)
+				So(unknownErr, ShouldBeNil)
+				So(unknown, ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestJoinSearches(t *testing.T) {
+	doc, docErr := os.Open("./testdata/demoReports/goodISXD.mxf")
+	ast, genErr := MakeAST(doc, make(chan *klv.KLV, 1000), 10, *NewSpecification())
+	doc.Close()
+
+	essence, essErr := ast.SearchNodes("select essence from partitions where type = body and essence.ul = FrameWrappedISXDData")
+	descriptors, descErr := ast.SearchNodes("SELECT Metadata FROM partitions WHERE metadata.ul = ISXD")
+	footerPreface, footErr := ast.SearchNodes("select metadata from partitions where ul = Preface and position > 0")
+
+	Convey("Checking partitions are joined to their nodes", t, func() {
+		Convey("searching goodISXD.mxf for the ISXD essence, the ISXD descriptors and the footer preface", func() {
+			Convey("The nodes are returned with their partitions", func() {
+				So(docErr, ShouldBeNil)
+				So(genErr, ShouldBeNil)
+				So(essErr, ShouldBeNil)
+				So(len(essence), ShouldEqual, 24)
+				So(essence[0].Partition, ShouldEqual, ast.Partitions[1])
+				So(essence[0].Node, ShouldEqual, ast.Partitions[1].Essence[0])
+				So(essence[23].Node, ShouldEqual, ast.Partitions[1].Essence[23])
+				So(descErr, ShouldBeNil)
+				So(len(descriptors), ShouldEqual, 2)
+				So(descriptors[0].Partition.Props.PartitionType, ShouldEqual, HeaderPartition)
+				So(descriptors[1].Partition.Props.PartitionType, ShouldEqual, FooterPartition)
+				So(footErr, ShouldBeNil)
+				So(len(footerPreface), ShouldEqual, 1)
+				So(footerPreface[0].Partition, ShouldEqual, ast.Partitions[2])
+			})
+		})
+	})
+
+	// the children are found after their parents in the file
+	child := &Node{Key: Position{Start: 10}, Sniffs: map[string]*SniffResult{"GPSSchema": {Field: "fail"}}, Properties: EssenceProperties{EssUL: "child"}}
+	parent := &Node{Key: Position{Start: 20}, Children: []*Node{child}, Sniffs: map[string]*SniffResult{"GPSSchema": {Field: "fail"}}, Properties: EssenceProperties{EssUL: "parent"}}
+	body := &PartitionNode{HeaderMetadata: []*Node{parent}, Essence: []*Node{parent}, Props: PartitionProperties{PartitionType: BodyPartition}}
+	dummyMXF := MXFNode{Partitions: []*PartitionNode{{Props: PartitionProperties{PartitionType: HeaderPartition}}, body}}
+
+	sniffed, sniffErr := dummyMXF.SearchNodes("select metadata from partitions where type = body and metadata.sniff:GPSSchema <> pass")
+
+	Convey("Checking joined nodes are returned in file order", t, func() {
+		Convey("searching a partition where the child metadata is before the parent", func() {
+			Convey("The child is returned first", func() {
+				So(sniffErr, ShouldBeNil)
+				So(sniffed, ShouldResemble, []NodeResult{{Partition: body, Node: child}, {Partition: body, Node: parent}})
+			})
+		})
+	})
+
+	badSearches := []string{"select ul from partitions where type = body",
+		"select essence, metadata from partitions",
+		"select essence from essence",
+		"select essence from partitions where metadata.ul = ISXD"}
+	badErrors := []string{"expected essence or metadata to be selected but found \"ul\" at column 8",
+		"expected essence or metadata to be selected but found \"metadata\" at column 17",
+		"unknown table \"essence\" at column 21",
+		"unknown field \"metadata.ul\" at column 38"}
+
+	for i, bs := range badSearches {
+		found, err := dummyMXF.SearchNodes(bs)
+
+		Convey("Checking invalid joins return syntax errors", t, func() {
+			Convey(fmt.Sprintf("running a search of %s", bs), func() {
+				Convey("A syntax error is returned with the column of the error", func() {
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldEqual, badErrors[i])
+					So(found, ShouldBeEmpty)
+				})
+			})
+		})
+	}
+}
+
+func TestQueries(t *testing.T) {
+	doc, docErr := os.Open("./testdata/demoReports/goodISXD.mxf")
+	ast, genErr := MakeAST(doc, make(chan *klv.KLV, 1000), 10, *NewSpecification())
+	doc.Close()
+
+	essenceCount, countErr := ast.Partitions[1].Query("select count(*) from essence")
+	uls, ulErr := ast.Partitions[0].Query("select distinct ul from metadata")
+	ulCounts, groupErr := ast.Partitions[0].Query("select ul, count(*) from metadata group by ul")
+	types, typeErr := ast.Query("select type, count(*) from metadata group by type")
+	projection, projErr := ast.Partitions[1].Query("select ul, offset from essence where offset < 3000")
+
+	Convey("Checking queries return tables of results", t, func() {
+		Convey("querying goodISXD.mxf for counts, distinct values and projections", func() {
+			Convey("The tables match the contents of the file", func() {
+				So(docErr, ShouldBeNil)
+				So(genErr, ShouldBeNil)
+				So(countErr, ShouldBeNil)
+				So(essenceCount, ShouldResemble, Table{Columns: []string{"count(*)"}, Rows: [][]string{{"24"}}})
+				So(ulErr, ShouldBeNil)
+				So(len(uls.Rows), ShouldEqual, 12)
+				So(groupErr, ShouldBeNil)
+				So(len(ulCounts.Rows), ShouldEqual, 12)
+				So(ulCounts.Rows[0][0], ShouldEqual, uls.Rows[0][0])
+				So(typeErr, ShouldBeNil)
+				So(types.Rows, ShouldResemble, [][]string{{HeaderPartition, "20"}, {FooterPartition, "20"}})
+				So(projErr, ShouldBeNil)
+				So(projection.Rows, ShouldResemble, [][]string{{"060e2b34.01020105.0e090502.017f017f", "2623"}, {"060e2b34.01020105.0e090502.017f017f", "2986"}})
+				offsets, ok := projection.Column("OFFSET")
+				So(ok, ShouldBeTrue)
+				So(offsets, ShouldResemble, []string{"2623", "2986"})
+			})
+		})
+	})
+
+	footers, footerErr := ast.Search("select * from partitions where type = Footer")
+	exactFooters, _ := ast.Search("select * from partitions where type == Footer")
+	closed, closedErr := ast.Search("select * from partitions where status = ClosedComplete")
+	lowerClosed, _ := ast.Search("select * from partitions where status = " + QuoteValue(ClosedComplete))
+
+	Convey("Checking the partition type and status are compared without case", t, func() {
+		Convey("searching goodISXD.mxf for type = Footer and status = ClosedComplete", func() {
+			Convey("The footer and closed complete partitions are found, unless the values are compared exactly", func() {
+				So(footerErr, ShouldBeNil)
+				So(footers, ShouldHaveLength, 1)
+				So(footers[0].Props.PartitionType, ShouldEqual, FooterPartition)
+				So(exactFooters, ShouldBeEmpty)
+				So(closedErr, ShouldBeNil)
+				So(closed, ShouldNotBeEmpty)
+				So(closed, ShouldResemble, lowerClosed)
+			})
+		})
+	})
+
+	// the offsets of every essence node, and of their partitions
+	essenceOffsets, partitionOffsets := make([]string, 0), make([]string, 0)
+	for _, part := range ast.Partitions {
+		for _, e := range part.Essence {
+			essenceOffsets = append(essenceOffsets, fmt.Sprintf("%v", e.Key.Start))
+			partitionOffsets = append(partitionOffsets, fmt.Sprintf("%v", part.Key.Start))
+		}
+	}
+
+	joinedOffsets, joinedErr := ast.Query("select offset, partition.offset from essence")
+	early, earlyErr := ast.Query("select offset from essence where offset < 2700")
+	compiled, compileErr := CompileQuery("select offset from essence where offset < 2700")
+
+	Convey("Checking the essence table fields are the fields of the essence", t, func() {
+		Convey("querying the offsets of the essence of goodISXD.mxf", func() {
+			Convey("Each row has the offset of its essence, and partition.offset is the offset of its partition", func() {
+				So(joinedErr, ShouldBeNil)
+				So(len(essenceOffsets), ShouldEqual, 24)
+				rowOffsets, _ := joinedOffsets.Column("offset")
+				So(rowOffsets, ShouldResemble, essenceOffsets)
+				rowPartitions, _ := joinedOffsets.Column("partition.offset")
+				So(rowPartitions, ShouldResemble, partitionOffsets)
+				So(earlyErr, ShouldBeNil)
+				So(early.Rows, ShouldResemble, [][]string{{"2623"}})
+				So(compileErr, ShouldBeNil)
+				So(compiled, ShouldNotBeNil)
+			})
+		})
+	})
+
+	xmlNode := &Node{Sniffs: map[string]*SniffResult{"/*": {Field: "root"}}, Properties: EssenceProperties{EssUL: "060e2b34.027f0101.0d010101.01010f00"}}
+	otherNode := &Node{Sniffs: map[string]*SniffResult{"/*": {Field: "root"}}, Properties: EssenceProperties{EssUL: "060e2b34.027f0101.0d010101.01011800"}}
+	binaryNode := &Node{Properties: EssenceProperties{EssUL: "060e2b34.027f0101.0d010101.01011800"}}
+	parentNode := &Node{Children: []*Node{xmlNode, otherNode, binaryNode}}
+
+	queries := []string{"select distinct sniff:/* where sniff:/* like '*'",
+		"select sniff:/*, count(*) group by sniff:/*",
+		"select count(*) where sniff:/* = missing",
+		"select ul, sniff:/*"}
+	expected := [][][]string{{{"root"}},
+		{{"root", "2"}, {"", "1"}},
+		{{"0"}},
+		{{"060e2b34.027f0101.0d010101.01010f00", "root"}, {"060e2b34.027f0101.0d010101.01011800", "root"}, {"060e2b34.027f0101.0d010101.01011800", ""}}}
+
+	for i, query := range queries {
+		table, err := parentNode.Query(query)
+
+		Convey("Checking the aggregations of the sniffed values", t, func() {
+			Convey(fmt.Sprintf("running a query of %s", query), func() {
+				Convey("The rows match the sniffed values", func() {
+					So(err, ShouldBeNil)
+					So(table.Rows, ShouldResemble, expected[i])
+				})
+			})
+		})
+	}
+
+	badQueries := []string{"select *, type from partitions",
+		"select type, bodysid, count(*) from partitions group by type",
+		"select count(*) from partitions group by count(*)",
+		"select count(*) from partitions group by size"}
+	badErrors := []string{"* can not be selected in a query, select the fields instead at column 8",
+		"\"bodysid\" has to be in the group by statement at column 14",
+		"count(*) can not be grouped by at column 42",
+		"unknown field \"size\" at column 42"}
+
+	for i, bq := range badQueries {
+		_, err := ast.Query(bq)
+
+		Convey("Checking invalid queries return syntax errors", t, func() {
+			Convey(fmt.Sprintf("running a query of %s", bq), func() {
+				Convey("A syntax error is returned with the column of the error", func() {
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldEqual, badErrors[i])
+				})
+			})
+		})
+	}
+
+	distinct, distErr := parentNode.Search("select distinct *")
+
+	Convey("Checking search functions do not aggregate", t, func() {
+		Convey("running a search of select distinct *", func() {
+			Convey("A syntax error is returned", func() {
+				So(distErr, ShouldNotBeNil)
+				So(distErr.Error(), ShouldEqual, "distinct can only be used with Query at column 17")
+				So(distinct, ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestCompiledQueries(t *testing.T) {
+	doc, docErr := os.Open("./testdata/demoReports/goodISXD.mxf")
+	ast, genErr := MakeAST(doc, make(chan *klv.KLV, 1000), 10, *NewSpecification())
+	doc.Close()
+
+	essence := MustCompileQuery("select * from essence where ul = FrameWrappedISXDData")
+	partEssence, partErr := essence.SearchPartition(ast.Partitions[1])
+	_, tableErr := essence.SearchMXF(ast)
+	count := MustCompileQuery("select count(*) from essence where ul = FrameWrappedISXDData")
+	partCount, partCountErr := count.QueryPartition(ast.Partitions[1])
+	fileCount, fileCountErr := count.QueryMXF(ast)
+
+	Convey("Checking compiled queries can be run at different node levels", t, func() {
+		Convey("running a compiled search for the ISXD essence of goodISXD.mxf", func() {
+			Convey("The same essence is found in the partition and the file", func() {
+				So(docErr, ShouldBeNil)
+				So(genErr, ShouldBeNil)
+				So(essence.String(), ShouldEqual, "select * from essence where ul = FrameWrappedISXDData")
+				So(partErr, ShouldBeNil)
+				So(len(partEssence), ShouldEqual, 24)
+				So(tableErr, ShouldNotBeNil)
+				So(partCountErr, ShouldBeNil)
+				So(partCount.Rows, ShouldResemble, [][]string{{"24"}})
+				So(fileCountErr, ShouldBeNil)
+				So(fileCount.Rows, ShouldResemble, [][]string{{"24"}})
+			})
+		})
+	})
+
+	joined, joinCompileErr := CompileQuery("select essence from partitions where type = body and essence.ul = FrameWrappedISXDData")
+	var joinedEssence []NodeResult
+	var joinErr error
+	if joinCompileErr == nil {
+		joinedEssence, joinErr = joined.SearchNodes(ast)
+	}
+
+	Convey("Checking compiled queries are checked at the level they are run", t, func() {
+		Convey("compiling a search of the essence joined to the body partitions of goodISXD.mxf", func() {
+			Convey("The query compiles with the node fields and finds every essence", func() {
+				So(joinCompileErr, ShouldBeNil)
+				So(joinErr, ShouldBeNil)
+				So(len(joinedEssence), ShouldEqual, 24)
+			})
+		})
+	})
+
+	badQueries := []string{"select * from essences",
+		"select * from partitions where sizes = 1",
+		"select * from partitions where bodysid > one",
+		"select count(*), ul from essence",
+		"select * where ul = ",
+		"select * from essence where type = body"}
+	badErrors := []string{"unknown table \"essences\" at column 15",
+		"unknown field \"sizes\" at column 32",
+		"expected a number after \">\" but found \"one\" at column 42",
+		"\"ul\" has to be in the group by statement at column 18",
+		"expected a value after \"=\" but found the end of the query at column 21",
+		"unknown field \"type\" at column 29"}
+
+	for i, bq := range badQueries {
+		compiled, err := CompileQuery(bq)
+
+		Convey("Checking invalid queries are found when they are compiled", t, func() {
+			Convey(fmt.Sprintf("compiling a query of %s", bq), func() {
+				Convey("A syntax error is returned with the column of the error", func() {
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldEqual, badErrors[i])
+					So(compiled, ShouldBeNil)
+					So(func() { MustCompileQuery(bq) }, ShouldPanic)
+				})
+			})
+		})
+	}
+
+	badSpec := NewSpecification(WithQueries("select * from partitions where type = header", "select * from partitions where sizes = 1"))
+	doc, docErr = os.Open("./testdata/demoReports/goodISXD.mxf")
+	_, specErr := MakeAST(doc, make(chan *klv.KLV, 1000), 10, *badSpec)
+	doc.Close()
+
+	Convey("Checking specifications with invalid queries are not run", t, func() {
+		Convey("making a specification with an unknown field in a query", func() {
+			Convey("The error is returned before the file is read", func() {
+				So(docErr, ShouldBeNil)
+				So(badSpec.Err(), ShouldNotBeNil)
+				So(specErr, ShouldResemble, badSpec.Err())
+				So(NewSpecification(WithQueries("select * from partitions where type = header")).Err(), ShouldBeNil)
+			})
+		})
+	})
+}
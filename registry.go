@@ -0,0 +1,569 @@
+package mxftest
+
+import (
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	mxf2go "github.com/metarex-media/mxf-to-go"
+)
+
+// registeredLabel is a label from the registers, with the
+// UL as bytes for matching against.
+type registeredLabel struct {
+	ul    []byte
+	label mxf2go.LabelInformation
+}
+
+var (
+	labelOnce sync.Once
+	labels    []registeredLabel
+)
+
+// ulBytes converts a UL string of the format
+// "060e2b34.04010101.0d010201.01010900" into its bytes.
+// The "urn:smpte:ul:" prefix is optional.
+func ulBytes(ul string) ([]byte, bool) {
+	ul = strings.TrimPrefix(strings.ToLower(ul), "urn:smpte:ul:")
+	b, err := hex.DecodeString(strings.ReplaceAll(ul, ".", ""))
+	if err != nil || len(b) != 16 {
+		return nil, false
+	}
+
+	return b, true
+}
+
+// loadLabels generates the byte versions of the label registers
+// in a fixed order, so that the label search is consistent.
+func loadLabels() {
+	labels = make([]registeredLabel, 0, len(mxf2go.LabelsLookUp))
+	for ul, label := range mxf2go.LabelsLookUp {
+		if b, ok := ulBytes(ul); ok {
+			labels = append(labels, registeredLabel{ul: b, label: label})
+		}
+	}
+
+	slices.SortFunc(labels, func(a, b registeredLabel) int {
+		return strings.Compare(a.label.UL, b.label.UL)
+	})
+}
+
+/*
+LabelLookUp finds the register entry of a label, where the UL
+is in the format "060e2b34.04010101.0d010201.01010900".
+
+The register version byte (byte 8) of the UL is ignored
+and any bytes with the value 7f in the register are treated as wildcards.
+When several labels match, the label with the fewest wildcards is returned.
+*/
+func LabelLookUp(ul string) (mxf2go.LabelInformation, bool) {
+	// check for an exact match first
+	if label, ok := mxf2go.LabelsLookUp["urn:smpte:ul:"+strings.TrimPrefix(strings.ToLower(ul), "urn:smpte:ul:")]; ok {
+		return label, true
+	}
+
+	target, ok := ulBytes(ul)
+	if !ok {
+		return mxf2go.LabelInformation{}, false
+	}
+
+	labelOnce.Do(loadLabels)
+
+	var found mxf2go.LabelInformation
+	bestWildcards := 17
+	for _, reg := range labels {
+		wildcards := 0
+		match := true
+		for i, b := range reg.ul {
+			switch {
+			case i == 7:
+				// skip the version byte
+			case b == 0x7f && target[i] != 0x7f:
+				wildcards++
+			case b != target[i]:
+				match = false
+			}
+
+			if !match {
+				break
+			}
+		}
+
+		if match && wildcards < bestWildcards {
+			found = reg.label
+			bestWildcards = wildcards
+		}
+	}
+
+	return found, bestWildcards != 17
+}
+
+/*
+MatchUL checks if two ULs, in the format "060e2b34.04010101.0d010201.01010900", match.
+
+The register version byte (byte 8) is ignored and any bytes with the value 7f,
+in either UL, are treated as wildcards. So that ULs match across register versions
+and with the masked ULs of the AST nodes.
+*/
+func MatchUL(a, b string) bool {
+	aUL, aOk := ulBytes(a)
+	bUL, bOk := ulBytes(b)
+
+	return aOk && bOk && matchULBytes(aUL, bUL)
+}
+
+// matchULBytes is MatchUL for ULs as bytes
+func matchULBytes(a, b []byte) bool {
+	for i := range a {
+		switch {
+		case i == 7:
+			// skip the version byte
+		case a[i] == 0x7f || b[i] == 0x7f:
+		case a[i] != b[i]:
+			return false
+		}
+	}
+
+	return true
+}
+
+// ulPattern converts a UL prefix pattern, e.g. 060e2b34.0101010c.0d0105*,
+// into its hex digits. Patterns without the * have to be a complete UL.
+func ulPattern(pattern string) ([]byte, bool) {
+	pattern = strings.TrimPrefix(strings.ToLower(pattern), "urn:smpte:ul:")
+	prefix, wildcard := strings.CutSuffix(pattern, "*")
+
+	nibbles := make([]byte, 0, 32)
+	for _, r := range prefix {
+		switch {
+		case r == '.':
+		case r >= '0' && r <= '9':
+			nibbles = append(nibbles, byte(r-'0'))
+		case r >= 'a' && r <= 'f':
+			nibbles = append(nibbles, byte(r-'a'+10))
+		default:
+			return nil, false
+		}
+	}
+
+	if len(nibbles) > 32 || (!wildcard && len(nibbles) != 32) {
+		return nil, false
+	}
+
+	return nibbles, true
+}
+
+// matchULPrefix checks a UL starts with the hex digits of a pattern,
+// with the version byte ignored and 7f treated as a wildcard.
+func matchULPrefix(ul []byte, pattern []byte) bool {
+	for i := 0; i < len(pattern); i++ {
+		pos := i / 2
+		// a complete 7f byte in the pattern
+		wildcard := i%2 == 0 && i+1 < len(pattern) && pattern[i] == 0x7 && pattern[i+1] == 0xf
+
+		nibble := ul[pos] >> 4
+		if i%2 == 1 {
+			nibble = ul[pos] & 0x0f
+		}
+
+		switch {
+		case pos == 7, ul[pos] == 0x7f, wildcard:
+			// skip the version byte and wildcards
+			if wildcard {
+				i++
+			}
+		case nibble != pattern[i]:
+			return false
+		}
+	}
+
+	return true
+}
+
+var (
+	symbolOnce sync.Once
+	symbols    map[string][]string
+	// groupAliases are the document names of groups
+	// that have a different symbol in the register
+	groupAliases = map[string]string{
+		"isxddataessencedescriptor":       "ISXD",
+		"genericpictureessencedescriptor": "PictureDescriptor",
+		"cdcipictureessencedescriptor":    "CDCIDescriptor",
+		"rgbapictureessencedescriptor":    "RGBADescriptor",
+		"genericsoundessencedescriptor":   "SoundDescriptor",
+		"genericdataessencedescriptor":    "DataEssenceDescriptor",
+	}
+)
+
+// symbolULs returns the ULs of the groups, essence and labels
+// with a symbolic name, the name is matched without case.
+// The names of groups in the documents that define them are also
+// accepted, e.g. ISXDDataEssenceDescriptor returns the UL of ISXD.
+func symbolULs(symbol string) [][]byte {
+	if alias, ok := groupAliases[strings.ToLower(symbol)]; ok {
+		symbol = alias
+	}
+
+	symbolOnce.Do(func() {
+		symbols = make(map[string][]string)
+		for ul, group := range mxf2go.Groups {
+			symbols[strings.ToLower(group.Name)] = append(symbols[strings.ToLower(group.Name)], ul)
+		}
+		for ul, essence := range mxf2go.EssenceLookUp {
+			symbols[strings.ToLower(essence.Symbol)] = append(symbols[strings.ToLower(essence.Symbol)], ul)
+		}
+		for ul, label := range mxf2go.LabelsLookUp {
+			symbols[strings.ToLower(label.Symbol)] = append(symbols[strings.ToLower(label.Symbol)], ul)
+		}
+	})
+
+	uls := slices.Clone(symbols[strings.ToLower(symbol)])
+
+	registryLock.RLock()
+	for ul, group := range privateGroups {
+		if strings.EqualFold(group.Name, symbol) {
+			uls = append(uls, ul)
+		}
+	}
+	for ul, essence := range privateEssence {
+		if strings.EqualFold(essence.Symbol, symbol) {
+			uls = append(uls, ul)
+		}
+	}
+	registryLock.RUnlock()
+
+	out := make([][]byte, 0, len(uls))
+	for _, ul := range uls {
+		if b, ok := ulBytes(ul); ok {
+			out = append(out, b)
+		}
+	}
+
+	return out
+}
+
+// GroupLookUp finds the register definition of a group from its key.
+// If the exact key is not found, then the key is searched for
+// with byte 5, then bytes 5 and 13 replaced with the 7f wildcard.
+//
+// Groups added with RegisterGroup are searched before the SMPTE registers.
+func GroupLookUp(key []byte) (mxf2go.GroupID, bool) {
+	group, _, ok := groupLookUp(key)
+
+	return group, ok
+}
+
+// groupLookUp finds the definition of a group from its key,
+// along with the UL that the group was found with.
+func groupLookUp(key []byte) (mxf2go.GroupID, string, bool) {
+	if len(key) != 16 {
+		return mxf2go.GroupID{}, "", false
+	}
+
+	for _, ul := range []string{fullName(key), FullNameMask(key, 5), FullNameMask(key, 5, 13)} {
+		if group, ok := groupDefinition(ul); ok {
+			return group, ul, true
+		}
+	}
+
+	return mxf2go.GroupID{}, "", false
+}
+
+// EssenceLookUp finds the register entry of an essence key, where the UL
+// is in the format "060e2b34.01020101.0d010301.0501017f".
+// Essence added with RegisterEssence is searched before the SMPTE registers.
+func EssenceLookUp(ul string) (mxf2go.EssenceInformation, bool) {
+	ul = "urn:smpte:ul:" + strings.TrimPrefix(strings.ToLower(ul), "urn:smpte:ul:")
+
+	registryLock.RLock()
+	essence, ok := privateEssence[ul]
+	registryLock.RUnlock()
+
+	if ok {
+		return essence, true
+	}
+
+	essence, ok = mxf2go.EssenceLookUp[ul]
+
+	return essence, ok
+}
+
+// PrivateGroup is the definition of a metadata group that
+// is not found in the SMPTE registers, such as private or partner metadata sets.
+type PrivateGroup struct {
+	// UL is the Universal Label of the group, in the format
+	// "060e2b34.027f0101.0d010101.01012f00". Bytes 5 and 13
+	// may be 7f, as with the registered groups.
+	UL string
+	// Symbol is the symbolic name of the group
+	Symbol string
+	// Properties are the properties of the group
+	Properties []PrivateProperty
+}
+
+// PrivateProperty is the definition of a property of a PrivateGroup.
+type PrivateProperty struct {
+	// UL is the Universal Label of the property, in the format
+	// "060e2b34.01010101.01011502.00000000"
+	UL string
+	// Symbol is the symbolic name of the property,
+	// it is used as the name of the decoded field.
+	Symbol string
+	// LocalTag is the optional static local tag of the property, e.g. "ffe0".
+	// It is used if the tag is not found in the primer of the file.
+	LocalTag string
+	// Length is the fixed length of the property value,
+	// 0 is used for variable lengths.
+	Length int
+	// Optional marks the property as optional for the group
+	Optional bool
+	// Decode decodes the property value.
+	// For references to be followed in the AST, the decoded
+	// values have to be reference types, such as those returned by
+	// DecodeStrongReferenceVector or the mxf2go reference decoders.
+	Decode func([]byte) (any, error)
+}
+
+var (
+	registryLock   sync.RWMutex
+	privateGroups  = make(map[string]mxf2go.GroupID)
+	privateTags    = make(map[string]map[string]string)
+	privateEssence = make(map[string]mxf2go.EssenceInformation)
+)
+
+// RegisterGroup adds a private group to the registry, so that
+// it is decoded and has its references followed like a registered group.
+// Registering a group with the UL of an existing group replaces it.
+func RegisterGroup(group PrivateGroup) error {
+	if _, ok := ulBytes(group.UL); !ok {
+		return fmt.Errorf("invalid group UL %q", group.UL)
+	}
+
+	definition := mxf2go.GroupID{Name: group.Symbol, Group: make(map[string]mxf2go.Group)}
+	tags := make(map[string]string)
+
+	for _, prop := range group.Properties {
+		if _, ok := ulBytes(prop.UL); !ok {
+			return fmt.Errorf("invalid UL %q for the property %s of the group %s", prop.UL, prop.Symbol, group.Symbol)
+		}
+
+		if prop.Decode == nil {
+			return fmt.Errorf("no decoder for the property %s of the group %s", prop.Symbol, group.Symbol)
+		}
+
+		ul := strings.TrimPrefix(strings.ToLower(prop.UL), "urn:smpte:ul:")
+		definition.Group["urn:smpte:ul:"+ul] = mxf2go.Group{UL: prop.Symbol, IsOpt: prop.Optional, Length: prop.Length, Decode: prop.Decode}
+
+		if prop.LocalTag != "" {
+			tags[strings.ToLower(prop.LocalTag)] = ul
+		}
+	}
+
+	key := registryKey(group.UL)
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	privateGroups[key] = definition
+	privateTags[key] = tags
+
+	return nil
+}
+
+// UnregisterGroup removes a group added with RegisterGroup from the registry,
+// so the group is no longer decoded. The ul is the UL the group was registered with.
+func UnregisterGroup(ul string) {
+	key := registryKey(ul)
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	delete(privateGroups, key)
+	delete(privateTags, key)
+}
+
+// RegisterEssence adds a private essence key to the registry,
+// so it is identified as essence in the AST.
+// Bytes of the UL with the value 7f are treated as wildcards
+// in the same way as the essence register.
+func RegisterEssence(essence mxf2go.EssenceInformation) error {
+	if _, ok := ulBytes(essence.UL); !ok {
+		return fmt.Errorf("invalid essence UL %q", essence.UL)
+	}
+
+	essence.UL = registryKey(essence.UL)
+
+	registryLock.Lock()
+	privateEssence[essence.UL] = essence
+	registryLock.Unlock()
+
+	return nil
+}
+
+// UnregisterEssence removes an essence key added with RegisterEssence
+// from the registry. The ul is the UL the essence was registered with.
+func UnregisterEssence(ul string) {
+	registryLock.Lock()
+	delete(privateEssence, registryKey(ul))
+	registryLock.Unlock()
+}
+
+// registryKey returns the key of a private UL in the registry,
+// in the format "urn:smpte:ul:060e2b34.027f0101.0d010101.01012f00"
+func registryKey(ul string) string {
+	return "urn:smpte:ul:" + strings.TrimPrefix(strings.ToLower(ul), "urn:smpte:ul:")
+}
+
+// groupDefinition returns the definition of a group,
+// where ul is in the format "060e2b34.027f0101.0d010101.01012f00"
+func groupDefinition(ul string) (mxf2go.GroupID, bool) {
+	registryLock.RLock()
+	group, ok := privateGroups["urn:smpte:ul:"+ul]
+	registryLock.RUnlock()
+
+	if ok {
+		return group, true
+	}
+
+	registerOnce.Do(flagOptional)
+	group, ok = registerGroups["urn:smpte:ul:"+ul]
+
+	return group, ok
+}
+
+//go:generate go run ./internal/gen -o required.go
+
+var (
+	registerOnce   sync.Once
+	registerGroups map[string]mxf2go.GroupID
+)
+
+// flagOptional copies the register groups with the IsOpt flag
+// of their properties set, as mxf2go does not set the flag.
+// Properties are optional unless they are generated as required.
+func flagOptional() {
+	registerGroups = make(map[string]mxf2go.GroupID, len(mxf2go.Groups))
+	for key, group := range mxf2go.Groups {
+		required := registerRequired[strings.TrimPrefix(key, "urn:smpte:ul:")]
+		props := make(map[string]mxf2go.Group, len(group.Group))
+		for key, prop := range group.Group {
+			prop.IsOpt = !slices.Contains(required, prop.UL)
+			props[key] = prop
+		}
+		group.Group = props
+		registerGroups[key] = group
+	}
+}
+
+var (
+	propertyOnce  sync.Once
+	propertyNames map[string]string
+	// propertyAliases are the ST 377-1 names of properties
+	// that have a different name in the register
+	propertyAliases = map[string]string{
+		"containerduration": "EssenceLength",
+		"duration":          "ComponentLength",
+		"datadefinition":    "ComponentDataDefinition",
+		"instanceuid":       "InstanceID",
+	}
+)
+
+// propertyName returns the register name of a group property,
+// the name is matched without case, e.g. essencelength returns EssenceLength.
+// The ST 377-1 names of properties are also accepted, e.g. ContainerDuration returns EssenceLength.
+func propertyName(name string) (string, bool) {
+	propertyOnce.Do(func() {
+		propertyNames = make(map[string]string)
+		for _, group := range mxf2go.Groups {
+			for _, prop := range group.Group {
+				propertyNames[strings.ToLower(prop.UL)] = prop.UL
+			}
+		}
+	})
+
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	for _, group := range privateGroups {
+		for _, prop := range group.Group {
+			if strings.EqualFold(prop.UL, name) {
+				return prop.UL, true
+			}
+		}
+	}
+
+	if alias, ok := propertyAliases[strings.ToLower(name)]; ok {
+		return alias, true
+	}
+
+	full, ok := propertyNames[strings.ToLower(name)]
+
+	return full, ok
+}
+
+// localTagUL returns the UL of a local tag from the primer,
+// or from the static tags of a private group if it is not in the primer.
+func localTagUL(group string, tag string, primer map[string]string) string {
+	if ul, ok := primer[tag]; ok {
+		return ul
+	}
+
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	return privateTags["urn:smpte:ul:"+group][tag]
+}
+
+// StrongReferenceVector is a batch of strong references,
+// for use as the decoded type of private group properties.
+type StrongReferenceVector []mxf2go.TStrongReference
+
+// DecodeStrongReferenceVector decodes a batch of strong references.
+// It is a decoder for PrivateProperty.
+func DecodeStrongReferenceVector(value []byte) (any, error) {
+	refs, err := referenceBatch(value)
+	out := make(StrongReferenceVector, len(refs))
+	for i, ref := range refs {
+		out[i] = mxf2go.TStrongReference(ref)
+	}
+
+	return out, err
+}
+
+// WeakReferenceVector is a batch of weak references,
+// for use as the decoded type of private group properties.
+type WeakReferenceVector []mxf2go.TWeakReference
+
+// DecodeWeakReferenceVector decodes a batch of weak references.
+// It is a decoder for PrivateProperty.
+func DecodeWeakReferenceVector(value []byte) (any, error) {
+	refs, err := referenceBatch(value)
+	out := make(WeakReferenceVector, len(refs))
+	for i, ref := range refs {
+		out[i] = mxf2go.TWeakReference(ref)
+	}
+
+	return out, err
+}
+
+// referenceBatch splits a batch into its references
+func referenceBatch(value []byte) ([][]byte, error) {
+	if len(value) < 8 {
+		return nil, fmt.Errorf("batch of %v bytes is too short for the batch header", len(value))
+	}
+
+	count := int(order.Uint32(value[0:4]))
+	size := int(order.Uint32(value[4:8]))
+	if count*size != len(value)-8 {
+		return nil, fmt.Errorf("batch of %v items of %v bytes does not match the length of %v", count, size, len(value)-8)
+	}
+
+	refs := make([][]byte, count)
+	for i := range refs {
+		refs[i] = value[8+i*size : 8+(i+1)*size]
+	}
+
+	return refs, nil
+}
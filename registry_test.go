@@ -0,0 +1,188 @@
+package mxftest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/metarex-media/mrx-tool/klv"
+	mxf2go "github.com/metarex-media/mxf-to-go"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPrivateGroups(t *testing.T) {
+	// replace the text based frameworks with a private group
+	veryBad, readErr := os.ReadFile("./testdata/demoReports/veryBadISXD.mxf")
+	ast, astErr := MakeAST(bytes.NewReader(veryBad), make(chan *klv.KLV, 1000), 10, *NewSpecification())
+	frameworks, searchErr := ast.Partitions[0].Search("select * from metadata where UL = " + QuoteValue(mxf2go.GTextBasedFrameworkUL[13:]))
+
+	privateKey := []byte{0x06, 0x0e, 0x2b, 0x34, 0x02, 0x53, 0x01, 0x01, 0x0e, 0x0b, 0x01, 0x01, 0x01, 0x01, 0x00, 0x00}
+	private := bytes.Clone(veryBad)
+	for _, framework := range frameworks {
+		copy(private[framework.Key.Start:], privateKey)
+	}
+
+	privateSearch := "select * from metadata where UL = " + QuoteValue(fullName(privateKey))
+
+	unknownAST, unknownErr := MakeAST(bytes.NewReader(private), make(chan *klv.KLV, 1000), 10, *NewSpecification())
+	// unknown groups are given the UL with bytes 5 and 13 masked
+	unknown, _ := unknownAST.Partitions[0].Search("select * from metadata where UL = " + QuoteValue(FullNameMask(privateKey, 5, 13)))
+	_, unknownDecodeErr := DecodeGroupNode(bytes.NewReader(private), unknown[0], unknownAST.Partitions[0].Props.Primer)
+
+	Convey("Checking private groups are not decoded before they are registered", t, func() {
+		Convey("generating an AST of veryBadISXD.mxf with the text based frameworks replaced with an unregistered private group", func() {
+			Convey("The private groups have no children and can not be decoded", func() {
+				So(readErr, ShouldBeNil)
+				So(astErr, ShouldBeNil)
+				So(searchErr, ShouldBeNil)
+				So(unknownErr, ShouldBeNil)
+				So(len(frameworks), ShouldEqual, len(unknown))
+				So(unknown[0].Children, ShouldBeEmpty)
+				So(unknownDecodeErr, ShouldNotBeNil)
+			})
+		})
+	})
+
+	regErr := RegisterGroup(PrivateGroup{UL: fullName(privateKey), Symbol: "PrivateFramework",
+		Properties: []PrivateProperty{
+			{UL: "060e2b34.0101010d.06010104.05410100", Symbol: "PrivateObject", Decode: mxf2go.DecodeTStrongReference},
+			{UL: "060e2b34.01010101.01011502.00000000", Symbol: "InstanceID", Length: 16, Decode: mxf2go.DecodeTUUID},
+		}})
+	t.Cleanup(func() { UnregisterGroup(fullName(privateKey)) })
+
+	privateAST, privateErr := MakeAST(bytes.NewReader(private), make(chan *klv.KLV, 1000), 10, *NewSpecification())
+	registered, _ := privateAST.Partitions[0].Search(privateSearch)
+	decoded, decodeErr := DecodeGroupNode(bytes.NewReader(private), registered[0], privateAST.Partitions[0].Props.Primer)
+	group, found := GroupLookUp(privateKey)
+
+	Convey("Checking private groups are decoded once they are registered", t, func() {
+		Convey("generating an AST of veryBadISXD.mxf with the text based frameworks replaced with a registered private group", func() {
+			Convey("The private groups are decoded with their symbols and their references are followed", func() {
+				So(regErr, ShouldBeNil)
+				So(privateErr, ShouldBeNil)
+				So(len(registered), ShouldEqual, len(frameworks))
+				So(registered[0].Children, ShouldHaveLength, 1)
+				So(registered[0].Children[0].Properties.UL(), ShouldEqual, mxf2go.GGenericStreamTextBasedSetUL[13:])
+				So(decodeErr, ShouldBeNil)
+				So(decoded, ShouldContainKey, "PrivateObject")
+				So(decoded, ShouldContainKey, "InstanceID")
+				So(found, ShouldBeTrue)
+				So(group.Name, ShouldEqual, "PrivateFramework")
+			})
+		})
+	})
+
+	badGroups := []PrivateGroup{
+		{UL: "not a ul", Symbol: "Bad"},
+		{UL: fullName(privateKey), Symbol: "Bad", Properties: []PrivateProperty{{UL: "not a ul", Symbol: "BadProperty", Decode: mxf2go.DecodeTUUID}}},
+		{UL: fullName(privateKey), Symbol: "Bad", Properties: []PrivateProperty{{UL: "060e2b34.01010101.01011502.00000000", Symbol: "NoDecoder"}}},
+	}
+	descs := []string{"an invalid group UL", "an invalid property UL", "a property without a decoder"}
+
+	for i, bad := range badGroups {
+		err := RegisterGroup(bad)
+
+		Convey("Checking invalid private groups are not registered", t, func() {
+			Convey(fmt.Sprintf("registering a private group with %s", descs[i]), func() {
+				Convey("An error is returned", func() {
+					So(err, ShouldNotBeNil)
+				})
+			})
+		})
+	}
+}
+
+func TestPrivateLocalTags(t *testing.T) {
+	groupKey := []byte{0x06, 0x0e, 0x2b, 0x34, 0x02, 0x53, 0x01, 0x01, 0x0e, 0x0b, 0x01, 0x01, 0x02, 0x01, 0x00, 0x00}
+	regErr := RegisterGroup(PrivateGroup{UL: fullName(groupKey), Symbol: "PrivateCounter",
+		Properties: []PrivateProperty{
+			{UL: "060e2b34.01010101.0e0b0101.01000000", Symbol: "Count", LocalTag: "ffe1", Length: 4, Decode: mxf2go.DecodeTUInt32},
+			{UL: "060e2b34.01010101.0e0b0101.02000000", Symbol: "Children", LocalTag: "ffe2", Decode: DecodeStrongReferenceVector},
+		}})
+	t.Cleanup(func() { UnregisterGroup(fullName(groupKey)) })
+
+	value := []byte{0xff, 0xe1, 0x00, 0x04, 0x00, 0x00, 0x00, 0x07,
+		0xff, 0xe2, 0x00, 0x18, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x10, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+	// the primer is empty so the static tags are used
+	decoded, err := DecodeGroup(&klv.KLV{Key: groupKey, Length: []byte{byte(len(value))}, Value: value}, map[string]string{})
+
+	Convey("Checking private groups are decoded with their static local tags", t, func() {
+		Convey("decoding a private group with local tags that are not in the primer", func() {
+			Convey("The properties are decoded with the static local tags", func() {
+				So(regErr, ShouldBeNil)
+				So(err, ShouldBeNil)
+				So(decoded, ShouldResemble, map[string]any{"Count": uint32(7),
+					"Children": StrongReferenceVector{mxf2go.TStrongReference{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}}})
+				So(ReferenceExtract(decoded["Children"], StrongRef), ShouldResemble, [][]byte{{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}})
+			})
+		})
+	})
+}
+
+func TestPrivateEssence(t *testing.T) {
+	// replace the ISXD essence keys with a private essence key
+	good, readErr := os.ReadFile("./testdata/demoReports/goodISXD.mxf")
+	ast, astErr := MakeAST(bytes.NewReader(good), make(chan *klv.KLV, 1000), 10, *NewSpecification())
+
+	privateKey := []byte{0x06, 0x0e, 0x2b, 0x34, 0x01, 0x02, 0x01, 0x01, 0x0e, 0x0b, 0x01, 0x01, 0x01, 0x01, 0x01, 0x00}
+	private := bytes.Clone(good)
+	for _, e := range ast.Partitions[1].Essence {
+		copy(private[e.Key.Start:], privateKey)
+	}
+
+	regErr := RegisterEssence(mxf2go.EssenceInformation{UL: "060e2b34.01020101.0e0b0101.0101017f", Symbol: "PrivateElement"})
+	t.Cleanup(func() { UnregisterEssence("060e2b34.01020101.0e0b0101.0101017f") })
+	privateAST, privateErr := MakeAST(bytes.NewReader(private), make(chan *klv.KLV, 1000), 10, *NewSpecification())
+	essence, found := EssenceLookUp("060e2b34.01020101.0e0b0101.0101017f")
+	badErr := RegisterEssence(mxf2go.EssenceInformation{UL: "not a ul"})
+
+	Convey("Checking private essence keys are identified once they are registered", t, func() {
+		Convey("generating an AST of goodISXD.mxf with the essence keys replaced with a registered private key", func() {
+			Convey("The essence has the registered UL and symbol", func() {
+				So(readErr, ShouldBeNil)
+				So(astErr, ShouldBeNil)
+				So(regErr, ShouldBeNil)
+				So(privateErr, ShouldBeNil)
+				So(privateAST.Partitions[1].Essence[0].Properties.UL(), ShouldEqual, "060e2b34.01020101.0e0b0101.0101017f")
+				So(found, ShouldBeTrue)
+				So(essence.Symbol, ShouldEqual, "PrivateElement")
+				So(badErr, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestUnregister(t *testing.T) {
+	groupKey := []byte{0x06, 0x0e, 0x2b, 0x34, 0x02, 0x53, 0x01, 0x01, 0x0e, 0x0b, 0x01, 0x01, 0x03, 0x01, 0x00, 0x00}
+	groupErr := RegisterGroup(PrivateGroup{UL: fullName(groupKey), Symbol: "PrivateRemoved",
+		Properties: []PrivateProperty{
+			{UL: "060e2b34.01010101.0e0b0101.03000000", Symbol: "Removed", LocalTag: "ffe3", Length: 4, Decode: mxf2go.DecodeTUInt32},
+		}})
+	essenceErr := RegisterEssence(mxf2go.EssenceInformation{UL: "060e2b34.01020101.0e0b0101.0201017f", Symbol: "PrivateRemovedElement"})
+	_, groupFound := GroupLookUp(groupKey)
+	_, essenceFound := EssenceLookUp("060e2b34.01020101.0e0b0101.0201017f")
+
+	UnregisterGroup(fullName(groupKey))
+	UnregisterEssence("urn:smpte:ul:060E2B34.01020101.0E0B0101.0201017F")
+	_, groupRemoved := GroupLookUp(groupKey)
+	_, essenceRemoved := EssenceLookUp("060e2b34.01020101.0e0b0101.0201017f")
+	_, tagRemoved := propertyName("Removed")
+	symbolRemoved := symbolULs("PrivateRemoved")
+
+	Convey("Checking private groups and essence can be removed from the registry", t, func() {
+		Convey("registering a private group and essence key then unregistering them", func() {
+			Convey("The group and essence are found until they are unregistered", func() {
+				So(groupErr, ShouldBeNil)
+				So(essenceErr, ShouldBeNil)
+				So(groupFound, ShouldBeTrue)
+				So(essenceFound, ShouldBeTrue)
+				So(groupRemoved, ShouldBeFalse)
+				So(essenceRemoved, ShouldBeFalse)
+				So(tagRemoved, ShouldBeFalse)
+				So(symbolRemoved, ShouldBeEmpty)
+			})
+		})
+	})
+}
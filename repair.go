@@ -0,0 +1,322 @@
+package mxftest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/metarex-media/mrx-tool/klv"
+	mxf2go "github.com/metarex-media/mxf-to-go"
+	"golang.org/x/sync/errgroup"
+)
+
+// RepairChange is a single fix that was applied by Repair.
+// The offset is the byte offset of the KLV in the original file.
+type RepairChange struct {
+	Offset int
+	// the partition or pack field that was changed
+	Field string
+	// the old and new values as strings
+	Old, New string
+	// a description of why the change was made
+	Desc string
+}
+
+// String allows the repair change to be written as a single log line
+func (r RepairChange) String() string {
+	return fmt.Sprintf("offset %v: %s changed from %s to %s, %s", r.Offset, r.Field, r.Old, r.New, r.Desc)
+}
+
+const (
+	primerKey     = "060e2b34.027f0101.0d010201.01050100"
+	fillKey       = "060e2b34.0101017f.03010210.01000000"
+	indexTableKey = "060e2b34.027f0101.0d010201.01100100"
+	ripKey        = "060e2b34.0205017f.0d010201.01110100"
+)
+
+// repairKLV is the position of a KLV in the file to be repaired.
+// Only the partition packs keep their bytes.
+type repairKLV struct {
+	pos      *Node
+	key      []byte
+	pack     *klv.KLV
+	packInfo Partition
+}
+
+/*
+Repair rewrites an MXF file to w with the common structural defects fixed.
+It returns a change log of every fix that was made, if no changes
+are returned then the file was copied without alteration.
+
+The defects that are fixed are:
+
+  - ThisPartition, PreviousPartition and FooterPartition pointers that do not match the file
+  - A missing or incorrect Random Index Pack (RIP)
+  - HeaderByteCount values that do not match the header metadata, including any trailing fill
+  - open and/or incomplete partition keys, when the footer partition is closed and complete
+
+The layout of the file is not changed, only the values of the partition packs,
+so the essence and metadata are found at the same byte offsets in the repaired file.
+The only exception is the RIP, which is always written as the final KLV of the file.
+*/
+func Repair(doc io.ReadSeeker, w io.Writer) ([]RepairChange, error) {
+
+	items, err := repairScan(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := make([]int, 0)
+	var footer *repairKLV
+	var rip *repairKLV
+	for i, item := range items {
+		switch {
+		case item.pack != nil:
+			partitions = append(partitions, i)
+			if item.packInfo.PartitionType == FooterPartition {
+				footer = items[i]
+			}
+		case FullNameMask(item.key, 7) == ripKey:
+			rip = items[i]
+		}
+	}
+
+	if len(partitions) == 0 {
+		return nil, fmt.Errorf("no partitions found in the mxf file, unable to repair")
+	}
+
+	// the byte offsets are relative to the header partition
+	// so any run in is ignored
+	base := items[partitions[0]].pos.Key.Start
+	changes := make([]RepairChange, 0)
+	var footerOffset uint64
+	if footer != nil {
+		footerOffset = uint64(footer.pos.Key.Start - base)
+	}
+	footerComplete := footer != nil && footer.pack.Key[14] == 0x04
+
+	expectedRIP := make([]RIP, len(partitions))
+	var previous uint64
+
+	for pi, i := range partitions {
+		part := items[i]
+		this := uint64(part.pos.Key.Start - base)
+		expectedRIP[pi] = RIP{Sid: part.packInfo.BodySID, ByteOffset: this}
+
+		changes = append(changes, repairPointer(part, 8, "ThisPartition", this, "the partition is not at the byte offset it declares")...)
+		changes = append(changes, repairPointer(part, 16, "PreviousPartition", previous, "the previous partition is not at the byte offset declared")...)
+		if footer != nil {
+			changes = append(changes, repairPointer(part, 24, "FooterPartition", footerOffset, "the footer partition is not at the byte offset declared")...)
+		}
+
+		// find the end of the partition
+		end := len(items)
+		if pi+1 < len(partitions) {
+			end = partitions[pi+1]
+		}
+		changes = append(changes, repairPointer(part, 32, "HeaderByteCount", headerByteCount(items[i+1:end]), "the header metadata length does not match the declared length")...)
+
+		// update any open or incomplete status to the closed complete
+		// status of the footer.
+		if footerComplete && part.packInfo.PartitionType != GenericStreamPartition && part.pack.Key[14] != 0x04 {
+			changes = append(changes, RepairChange{Offset: part.pos.Key.Start, Field: "PartitionStatus",
+				Old: partitionStatus(part.pack.Key[14]), New: ClosedComplete, Desc: "the footer partition is closed and complete"})
+			part.pack.Key[14] = 0x04
+		}
+
+		previous = this
+	}
+
+	// generate the rip if the file does not have a valid one
+	ripBytes := encodeRIP(expectedRIP)
+	switch {
+	case rip == nil:
+		changes = append(changes, RepairChange{Offset: items[len(items)-1].pos.Value.End, Field: "RandomIndexPack",
+			Old: "missing", New: fmt.Sprintf("%v partition entries", len(expectedRIP)), Desc: "the file has no random index pack"})
+	default:
+		ripKLV, err := NodeToKLV(doc, rip.pos)
+		if err != nil {
+			return nil, err
+		}
+
+		entries, overallLength := RIPExtract(ripKLV)
+		if !slices.Equal(entries, expectedRIP) || int(overallLength) != ripKLV.TotalLength() || rip != items[len(items)-1] {
+			changes = append(changes, RepairChange{Offset: rip.pos.Key.Start, Field: "RandomIndexPack",
+				Old: fmt.Sprintf("%v partition entries", len(entries)), New: fmt.Sprintf("%v partition entries", len(expectedRIP)),
+				Desc: "the random index pack does not match the partitions in the file"})
+		} else {
+			// keep the original encoding
+			ripBytes = append(append(append([]byte{}, ripKLV.Key...), ripKLV.Length...), ripKLV.Value...)
+		}
+	}
+
+	// write the file, copying everything between the partition packs
+	copied := 0
+	for _, item := range items {
+		switch {
+		case item.pack != nil:
+			if err := repairCopy(doc, w, copied, item.pos.Key.Start); err != nil {
+				return nil, err
+			}
+			for _, b := range [][]byte{item.pack.Key, item.pack.Length, item.pack.Value} {
+				if _, err := w.Write(b); err != nil {
+					return nil, err
+				}
+			}
+			copied = item.pos.Value.End
+		case item == rip:
+			// skip the old rip
+			if err := repairCopy(doc, w, copied, item.pos.Key.Start); err != nil {
+				return nil, err
+			}
+			copied = item.pos.Value.End
+		}
+	}
+
+	if err := repairCopy(doc, w, copied, items[len(items)-1].pos.Value.End); err != nil {
+		return nil, err
+	}
+	_, err = w.Write(ripBytes)
+
+	return changes, err
+}
+
+// repairScan breaks the file into its KLV positions, without building
+// an AST. This is so files with incorrect header byte counts can still be read.
+func repairScan(doc io.ReadSeeker) ([]*repairKLV, error) {
+	if _, err := doc.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	buffer := make(chan *klv.KLV, 1000)
+	errs, _ := errgroup.WithContext(context.Background())
+
+	errs.Go(func() error {
+		return klv.StartKLVStream(doc, buffer, 10)
+	})
+
+	items := make([]*repairKLV, 0)
+	errs.Go(func() error {
+		defer func() {
+			// drain the channel to stop blocking on an error
+			_, klvOpen := <-buffer
+			for klvOpen {
+				_, klvOpen = <-buffer
+			}
+		}()
+
+		offset := 0
+		for klvItem := range buffer {
+			item := &repairKLV{
+				pos: &Node{
+					Key:    Position{Start: offset, End: offset + len(klvItem.Key)},
+					Length: Position{Start: offset + len(klvItem.Key), End: offset + len(klvItem.Key) + len(klvItem.Length)},
+					Value:  Position{Start: offset + len(klvItem.Key) + len(klvItem.Length), End: offset + klvItem.TotalLength()},
+				},
+				key: klvItem.Key,
+			}
+
+			if FullNameMask(klvItem.Key, 7, 13, 14) == "060e2b34.0205017f.0d010201.017f7f00" {
+				info := PartitionExtract(klvItem)
+				if info.PartitionType != "invalid" && info.Signature != "" {
+					item.pack = klvItem
+					item.packInfo = info
+				}
+			}
+
+			items = append(items, item)
+			offset += klvItem.TotalLength()
+		}
+
+		if offset == 0 {
+			return fmt.Errorf("no mxf data found in byte stream")
+		}
+
+		return nil
+	})
+
+	err := errs.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// repairPointer updates a uint64 field of the partition pack if it does not match
+// the expected value.
+func repairPointer(part *repairKLV, pos int, field string, expected uint64, desc string) []RepairChange {
+	current := order.Uint64(part.pack.Value[pos : pos+8])
+	if current == expected {
+		return nil
+	}
+
+	order.PutUint64(part.pack.Value[pos:pos+8], expected)
+
+	return []RepairChange{{Offset: part.pos.Key.Start, Field: field, Old: fmt.Sprintf("%v", current), New: fmt.Sprintf("%v", expected), Desc: desc}}
+}
+
+// headerByteCount finds the length of the header metadata of a partition
+// from the KLVs that follow the partition pack.
+// It starts at the primer pack and includes any trailing fill.
+func headerByteCount(contents []*repairKLV) uint64 {
+	start := -1
+	end := 0
+	for _, item := range contents {
+		isFill := FullNameMask(item.key, 7) == fillKey
+
+		switch {
+		case start == -1 && isFill:
+			// skip the kag fill before the metadata
+			continue
+		case start == -1 && FullNameMask(item.key, 5) == primerKey:
+			start = item.pos.Key.Start
+			end = item.pos.Value.End
+		case start == -1:
+			// no metadata in this partition
+			return 0
+		case isFill || (item.key[4] == 0x02 && FullNameMask(item.key, 5) != indexTableKey && FullNameMask(item.key, 7) != ripKey):
+			end = item.pos.Value.End
+		default:
+			return uint64(end - start)
+		}
+	}
+
+	if start == -1 {
+		return 0
+	}
+
+	return uint64(end - start)
+}
+
+// repairCopy copies the bytes from start to end of the doc into w
+func repairCopy(doc io.ReadSeeker, w io.Writer, start, end int) error {
+	if end <= start {
+		return nil
+	}
+
+	if _, err := doc.Seek(int64(start), io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.CopyN(w, doc, int64(end-start))
+
+	return err
+}
+
+// encodeRIP encodes the Random Index Pack as a complete KLV
+func encodeRIP(entries []RIP) []byte {
+	value := make([]byte, 0, len(entries)*12+4)
+	for _, e := range entries {
+		value = order.AppendUint32(value, e.Sid)
+		value = order.AppendUint64(value, e.ByteOffset)
+	}
+
+	key := []byte{0x06, 0x0e, 0x2b, 0x34, 0x02, 0x05, 0x01, 0x01, 0x0d, 0x01, 0x02, 0x01, 0x01, 0x11, 0x01, 0x00}
+	length := mxf2go.BEREncode(len(value) + 4)
+	// the overall length includes the length field
+	value = order.AppendUint32(value, uint32(len(key)+len(length)+len(value)+4))
+
+	out := append(key, length...)
+	return append(out, value...)
+}
@@ -0,0 +1,89 @@
+package mxftest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/metarex-media/mrx-tool/klv"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRepair(t *testing.T) {
+
+	good, readErr := os.ReadFile("./testdata/demoReports/goodISXD.mxf")
+
+	// break the file in several ways
+	// the body partition is at 2499 and its value starts at 2519
+	badPointer := bytes.Clone(good)
+	order.PutUint64(badPointer[2519+8:], 12)
+	badHeaderCount := bytes.Clone(good)
+	// header byte count of the header partition
+	order.PutUint64(badHeaderCount[20+32:], 2000)
+	// remove the RIP from the end of the file
+	noRIP := bytes.Clone(good[:13793])
+
+	inputs := [][]byte{badPointer, badHeaderCount, noRIP}
+	expectedFields := []string{"ThisPartition", "HeaderByteCount", "RandomIndexPack"}
+
+	for i, input := range inputs {
+		var repaired bytes.Buffer
+		changes, repairErr := Repair(bytes.NewReader(input), &repaired)
+
+		// run the repair a second time to check nothing else needs changing
+		var rerun bytes.Buffer
+		rerunChanges, rerunErr := Repair(bytes.NewReader(repaired.Bytes()), &rerun)
+
+		_, astErr := MakeAST(bytes.NewReader(repaired.Bytes()), make(chan *klv.KLV, 1000), 10, *NewSpecification())
+
+		fields := make(map[string]bool)
+		for _, c := range changes {
+			fields[c.Field] = true
+		}
+
+		Convey("Checking that the common structural defects are repaired", t, func() {
+			Convey(fmt.Sprintf("repairing a file with a broken %s", expectedFields[i]), func() {
+				Convey("The defect is logged and fixed, and the repaired file needs no further repairs", func() {
+					So(readErr, ShouldBeNil)
+					So(repairErr, ShouldBeNil)
+					So(fields[expectedFields[i]], ShouldBeTrue)
+					So(rerunErr, ShouldBeNil)
+					So(rerunChanges, ShouldBeEmpty)
+					So(rerun.Bytes(), ShouldResemble, repaired.Bytes())
+					So(astErr, ShouldBeNil)
+					// the layout only changes if the RIP was missing
+					So(repaired.Len(), ShouldEqual, len(good))
+				})
+			})
+		})
+	}
+
+	// check the partitions after a repair
+	var repaired bytes.Buffer
+	_, repairErr := Repair(bytes.NewReader(badPointer), &repaired)
+	body := PartitionExtract(&klv.KLV{Key: repaired.Bytes()[2499:2515], Value: repaired.Bytes()[2519:2623]})
+	header := PartitionExtract(&klv.KLV{Key: repaired.Bytes()[:16], Value: repaired.Bytes()[20:124]})
+
+	Convey("Checking the repaired partition packs contain the correct values", t, func() {
+		Convey("repairing a file with a broken ThisPartition pointer, and an open incomplete header", func() {
+			Convey("The pointers and partition statuses match the file layout", func() {
+				So(repairErr, ShouldBeNil)
+				So(body.ThisPartition, ShouldEqual, 2499)
+				So(body.FooterPartition, ShouldEqual, 11294)
+				So(header.FooterPartition, ShouldEqual, 11294)
+				So(header.HeaderByteCount, ShouldEqual, 2375)
+				So(repaired.Bytes()[14], ShouldEqual, 0x04)
+			})
+		})
+	})
+
+	_, err := Repair(bytes.NewReader([]byte{}), &repaired)
+	Convey("Checking that the repair returns an error for invalid files", t, func() {
+		Convey("repairing an empty byte stream", func() {
+			Convey("An error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
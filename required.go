@@ -0,0 +1,213 @@
+// Code generated by internal/gen from the github.com/metarex-media/mxf-to-go group structs. DO NOT EDIT.
+
+package mxftest
+
+import mxf2go "github.com/metarex-media/mxf-to-go"
+
+// registerRequired are the properties that shall be present in
+// a group, mapped by the group UL. They are the properties that
+// are not optional in the register.
+var registerRequired = map[string][]string{
+	mxf2go.GAES3PCMDescriptorUL[13:]:                         {"AudioSampleRate", "AverageBytesPerSecond", "BlockAlign", "ChannelCount", "QuantizationBits"},
+	mxf2go.GAIFCDescriptorUL[13:]:                            {"AIFCSummary"},
+	mxf2go.GAS_07_Core_DMS_FrameworkUL[13:]:                  {"AS_07_Core_DMS_AudioTrackLayout", "AS_07_Core_DMS_Captions", "AS_07_Core_DMS_Identifiers", "AS_07_Core_DMS_IntendedAFD", "AS_07_Core_DMS_PictureFormat", "AS_07_Core_DMS_ResponsibleOrganizationName", "AS_07_Core_DMS_ShimName"},
+	mxf2go.GAS_07_DMS_IdentifierUL[13:]:                      {"AS_07_DMS_IdentifierRole", "AS_07_DMS_IdentifierType", "AS_07_DMS_IdentifierValue"},
+	mxf2go.GAS_07_GSP_BD_DMS_FrameworkUL[13:]:                {"TextBasedObject"},
+	mxf2go.GAS_07_GSP_DMS_FrameworkUL[13:]:                   {"TextBasedObject"},
+	mxf2go.GAS_07_GSP_DMS_ObjectUL[13:]:                      {"AS_07_GSP_DMS_DataDescription", "AS_07_GSP_DMS_Identifiers", "AS_07_GSP_DMS_MIMEMediaType", "GenericStreamID", "RFC5646TextLanguageCode", "TextBasedMetadataPayloadSchemeID", "TextMIMEMediaType"},
+	mxf2go.GAS_07_GSP_TD_DMS_FrameworkUL[13:]:                {"AS_07_GSP_TD_DMS_PrimaryRFC5646LanguageCode", "TextBasedObject"},
+	mxf2go.GAS_07_Segmentation_DMS_FrameworkUL[13:]:          {"AS_07_Segmentation_DMS_PartNumber", "AS_07_Segmentation_DMS_PartTotal"},
+	mxf2go.GAS_07_TimecodeLabelSubdescriptorUL[13:]:          {"AS_07_DateTimeSymbol"},
+	mxf2go.GAVCSubDescriptorUL[13:]:                          {"AVCDecodingDelay"},
+	mxf2go.GAbstractObjectUL[13:]:                            {"InstanceID"},
+	mxf2go.GApplicationPlugInObjectUL[13:]:                   {"ApplicationPluginInstanceID", "ApplicationScheme"},
+	mxf2go.GApplicationReferencedObjectUL[13:]:               {"LinkedApplicationPluginInstanceID"},
+	mxf2go.GAudioChannelLabelSubDescriptorUL[13:]:            {"MCALabelDictionaryID", "MCALinkID", "MCATagSymbol"},
+	mxf2go.GAuxDataBlockUL[13:]:                              {"AuxDataBlockEditUnitEditRate", "AuxDataBlockEditUnitIndex", "AuxDataBlockSourceCryptographicContext", "AuxDataBlockSourceCryptographicContextLength", "AuxDataBlockSourceDataEssenceCodingUL", "AuxDataBlockSourceDataItem", "AuxDataBlockSourceDataItemLength"},
+	mxf2go.GAuxDataBlockTransferHeaderUL[13:]:                {"AuxDataEditUnitRangeStartIndex", "AuxEditUnitRangeCount"},
+	mxf2go.GAuxiliaryDescriptorUL[13:]:                       {"MIMEType"},
+	mxf2go.GBadRequestResponseUL[13:]:                        {"ASMBadRequestCopy", "ASMResponse"},
+	mxf2go.GBodyPartitionClosedCompleteUL[13:]:               {"BodyOffset", "EssenceContainers", "EssenceStreamID", "FooterPartition", "HeaderByteCount", "IndexByteCount", "IndexStreamID", "KAGSize", "MajorVersion", "MinorVersion", "OperationalPattern", "PreviousPartition", "ThisPartition"},
+	mxf2go.GBodyPartitionClosedIncompleteUL[13:]:             {"BodyOffset", "EssenceContainers", "EssenceStreamID", "FooterPartition", "HeaderByteCount", "IndexByteCount", "IndexStreamID", "KAGSize", "MajorVersion", "MinorVersion", "OperationalPattern", "PreviousPartition", "ThisPartition"},
+	mxf2go.GBodyPartitionOpenCompleteUL[13:]:                 {"BodyOffset", "EssenceContainers", "EssenceStreamID", "FooterPartition", "HeaderByteCount", "IndexByteCount", "IndexStreamID", "KAGSize", "MajorVersion", "MinorVersion", "OperationalPattern", "PreviousPartition", "ThisPartition"},
+	mxf2go.GBodyPartitionOpenIncompleteUL[13:]:               {"BodyOffset", "EssenceContainers", "EssenceStreamID", "FooterPartition", "HeaderByteCount", "IndexByteCount", "IndexStreamID", "KAGSize", "MajorVersion", "MinorVersion", "OperationalPattern", "PreviousPartition", "ThisPartition"},
+	mxf2go.GBodyPartitionPackUL[13:]:                         {"BodyOffset", "EssenceContainers", "EssenceStreamID", "FooterPartition", "HeaderByteCount", "IndexByteCount", "IndexStreamID", "KAGSize", "MajorVersion", "MinorVersion", "OperationalPattern", "PreviousPartition", "ThisPartition"},
+	mxf2go.GCDCIDescriptorUL[13:]:                            {"ComponentDepth", "FrameLayout", "HorizontalSubsampling", "ImageAspectRatio", "StoredHeight", "StoredWidth", "VideoLineMap"},
+	mxf2go.GClassDefinitionUL[13:]:                           {"IsConcrete", "MetaDefinitionIdentification", "MetaDefinitionName", "ParentClass"},
+	mxf2go.GCodecDefinitionUL[13:]:                           {"CodecDataDefinitions", "DefinitionObjectIdentification", "DefinitionObjectName", "FileDescriptorClass"},
+	mxf2go.GCommentMarkerUL[13:]:                             {"ComponentDataDefinition"},
+	mxf2go.GComponentUL[13:]:                                 {"ComponentDataDefinition"},
+	mxf2go.GCompositionPackageUL[13:]:                        {"CreationTime", "PackageID", "PackageLastModified", "PackageTracks"},
+	mxf2go.GCompressionDefinitionUL[13:]:                     {"DefinitionObjectIdentification", "DefinitionObjectName"},
+	mxf2go.GConstantValueUL[13:]:                             {"ParameterDefinitionReference", "Value"},
+	mxf2go.GContactUL[13:]:                                   {"ContactID"},
+	mxf2go.GContainerDefinitionUL[13:]:                       {"DefinitionObjectIdentification", "DefinitionObjectName"},
+	mxf2go.GContentStorageUL[13:]:                            {"Packages"},
+	mxf2go.GControlPointUL[13:]:                              {"ControlPointTime", "ControlPointValue"},
+	mxf2go.GCryptographicContextUL[13:]:                      {"CipherAlgorithm", "CryptographicContextID", "CryptographicKeyID", "MICAlgorithm", "SourceContainerFormat"},
+	mxf2go.GCryptographicFrameworkUL[13:]:                    {"CryptographicContextObject"},
+	mxf2go.GDCPCMSoundDescriptorUL[13:]:                      {"AudioSampleRate", "ChannelCount", "QuantizationBits"},
+	mxf2go.GDCTimedTextDescriptorUL[13:]:                     {"NamespaceURI", "ResourceID", "UCSEncoding"},
+	mxf2go.GDCTimedTextResourceSubDescriptorUL[13:]:          {"AncillaryResourceID", "EssenceStreamID", "MIMEType"},
+	mxf2go.GDMCVTApp1SetUL[13:]:                              {"ApplicationIdentifier", "ApplicationVersionNumber", "AveragePqencodedMaxrgb", "MaximumPqencodedMaxrgb", "MinimumPqencodedMaxrgb"},
+	mxf2go.GDMCVTApp2SetUL[13:]:                              {"ApplicationIdentifier", "ApplicationVersionNumber", "HighlightGainControl", "MidToneWidthAdjustmentFactor", "SaturationGainFunction", "ShadowGainControl", "ToneMappingInputSignalBlackLevelOffset", "ToneMappingInputSignalWeights", "ToneMappingInputSignalWhiteLevelOffset", "ToneMappingOutputFineTuningFunction"},
+	mxf2go.GDMCVTApp3SetUL[13:]:                              {"ApplicationIdentifier", "ApplicationVersionNumber"},
+	mxf2go.GDMCVTApp4SetUL[13:]:                              {"ApplicationIdentifier", "ApplicationVersionNumber", "AverageMaxRGB", "DistributionMaxRGBPercentages", "DistributionMaxRGBPercentiles", "FractionBrightPixels", "MaxSCL"},
+	mxf2go.GDMCVTGenericSet1UL[13:]:                          {"ApplicationIdentifier", "ApplicationVersionNumber"},
+	mxf2go.GDMCVTTargetSubDescriptorUL[13:]:                  {"DMCVTApplicationIdentifier", "DMCVTApplicationVersionNumber"},
+	mxf2go.GDMS_AS_03_FrameworkUL[13:]:                       {"AS_03_Identifier", "AS_03_IdentifierKind", "AS_03_IntendedAFD", "AS_03_ShimName", "AS_03_SignalStandard"},
+	mxf2go.GDMS_AS_10_Core_FrameworkUL[13:]:                  {"AS_10_Shim_Name"},
+	mxf2go.GDMS_AS_12_AdID_SlateUL[13:]:                      {"ad_title", "adid_code", "adid_prefix", "advertiser", "agency_office_location", "brand", "length", "medium", "parent", "product", "sd_flag"},
+	mxf2go.GDMS_AS_12_FrameworkUL[13:]:                       {"AS_12_ShimName", "AS_12_Slate"},
+	mxf2go.GDM_AS_11_Core_FrameworkUL[13:]:                   {"AS_11_Audio_Track_Layout", "AS_11_Closed_Captions_Present", "AS_11_Episode_Title_Number", "AS_11_Primary_Audio_Language", "AS_11_Programme_Title", "AS_11_Series_Title", "AS_11_Shim_Name", "AS_11_Shim_Version"},
+	mxf2go.GDM_AS_11_Segmentation_FrameworkUL[13:]:           {"AS_11_Part_Number", "AS_11_Part_Total"},
+	mxf2go.GDM_AS_11_UKDPP_FrameworkUL[13:]:                  {"UKDPP_3D", "UKDPP_Audio_Description_Present", "UKDPP_Audio_Loudness_Standard", "UKDPP_Completion_Date", "UKDPP_Contact_Email", "UKDPP_Contact_Telephone_Number", "UKDPP_Copyright_Year", "UKDPP_Ident_Clock_Start", "UKDPP_Line_Up_Start", "UKDPP_Open_Captions_Present", "UKDPP_Originator", "UKDPP_PSE_Pass", "UKDPP_Production_Number", "UKDPP_Secondary_Audio_Language", "UKDPP_Signing_Present", "UKDPP_Synopsis", "UKDPP_Tertiary_Audio_Language", "UKDPP_Total_Number_Of_Parts", "UKDPP_Total_Programme_Duration"},
+	mxf2go.GDataDefinitionUL[13:]:                            {"DefinitionObjectIdentification", "DefinitionObjectName"},
+	mxf2go.GDateTimeDescriptorUL[13:]:                        {"DateTimeKind"},
+	mxf2go.GDefinitionObjectUL[13:]:                          {"DefinitionObjectIdentification", "DefinitionObjectName"},
+	mxf2go.GDescriptiveClipUL[13:]:                           {"ComponentDataDefinition", "SourcePackageID", "SourceTrackID", "StartPosition"},
+	mxf2go.GDescriptiveMarkerUL[13:]:                         {"ComponentDataDefinition", "DescriptiveFrameworkObject"},
+	mxf2go.GDynamicClipUL[13:]:                               {"ComponentDataDefinition", "DescriptiveFrameworkObject", "DynamicSourcePackageID"},
+	mxf2go.GDynamicMarkerUL[13:]:                             {"ComponentDataDefinition", "DescriptiveFrameworkObject"},
+	mxf2go.GEBUCoreMainFrameworkUL[13:]:                      {"coreMetadataObject"},
+	mxf2go.GEIDRFrameworkUL[13:]:                             {"EIDRDMSEssenceID"},
+	mxf2go.GEdgeCodeUL[13:]:                                  {"ComponentDataDefinition", "EdgeCodeFilmFormat", "EdgeCodeFormat", "EdgeCodeStart"},
+	mxf2go.GEncryptedTripletUL[13:]:                          {"CryptographicContextLink", "EncryptedSourceValue", "PlaintextOffset", "SourceKey", "SourceLength"},
+	mxf2go.GEssenceDataUL[13:]:                               {"LinkedPackageID"},
+	mxf2go.GEssenceGroupUL[13:]:                              {"Choices", "ComponentDataDefinition"},
+	mxf2go.GEventUL[13:]:                                     {"ComponentDataDefinition"},
+	mxf2go.GEventIDRequestUL[13:]:                            {"ASMEventID", "ASMRequestID"},
+	mxf2go.GEventIDResponseUL[13:]:                           {"ASMLogRecord", "ASMRequestID", "ASMResponse"},
+	mxf2go.GEventListRequestUL[13:]:                          {"ASMEventListStartTime", "ASMEventListStopTime", "ASMRequestID"},
+	mxf2go.GEventListResponseUL[13:]:                         {"ASMEventIDBatch", "ASMRequestID", "ASMResponse"},
+	mxf2go.GEventTextDescriptorUL[13:]:                       {"EventTextKind", "EventTextLanguageCode"},
+	mxf2go.GEventTrackUL[13:]:                                {"EssenceTrackNumber", "EventTrackEditRate", "TrackID", "TrackSegment"},
+	mxf2go.GExtendibleEnumerationElementUL[13:]:              {"DefinitionObjectIdentification", "DefinitionObjectName"},
+	mxf2go.GExtensionSchemeUL[13:]:                           {"ExtensionSchemeID", "InstanceID", "SymbolSpaceURI"},
+	mxf2go.GFillerUL[13:]:                                    {"ComponentDataDefinition"},
+	mxf2go.GFooterPartitionClosedCompleteUL[13:]:             {"BodyOffset", "EssenceContainers", "EssenceStreamID", "FooterPartition", "HeaderByteCount", "IndexByteCount", "IndexStreamID", "KAGSize", "MajorVersion", "MinorVersion", "OperationalPattern", "PreviousPartition", "ThisPartition"},
+	mxf2go.GFooterPartitionClosedIncompleteUL[13:]:           {"BodyOffset", "EssenceContainers", "EssenceStreamID", "FooterPartition", "HeaderByteCount", "IndexByteCount", "IndexStreamID", "KAGSize", "MajorVersion", "MinorVersion", "OperationalPattern", "PreviousPartition", "ThisPartition"},
+	mxf2go.GFooterPartitionPackUL[13:]:                       {"BodyOffset", "EssenceContainers", "EssenceStreamID", "FooterPartition", "HeaderByteCount", "IndexByteCount", "IndexStreamID", "KAGSize", "MajorVersion", "MinorVersion", "OperationalPattern", "PreviousPartition", "ThisPartition"},
+	mxf2go.GGPITriggerUL[13:]:                                {"ActiveState", "ComponentDataDefinition"},
+	mxf2go.GGenericStreamPartitionUL[13:]:                    {"BodyOffset", "EssenceContainers", "EssenceStreamID", "FooterPartition", "HeaderByteCount", "IndexByteCount", "IndexStreamID", "KAGSize", "MajorVersion", "MinorVersion", "OperationalPattern", "PreviousPartition", "ThisPartition"},
+	mxf2go.GGenericStreamTextBasedSetUL[13:]:                 {"GenericStreamID", "RFC5646TextLanguageCode", "TextBasedMetadataPayloadSchemeID", "TextMIMEMediaType"},
+	mxf2go.GGroupOfSoundfieldGroupsLabelSubDescriptorUL[13:]: {"MCALabelDictionaryID", "MCALinkID", "MCATagSymbol"},
+	mxf2go.GHEVCSubDescriptorUL[13:]:                         {"HEVCDecodingDelay"},
+	mxf2go.GHTMLClipUL[13:]:                                  {"ComponentDataDefinition", "SourcePackageID", "SourceTrackID"},
+	mxf2go.GHTMLParsedTextDescriptorUL[13:]:                  {"HTMLDOCTYPE"},
+	mxf2go.GHeaderPartitionClosedCompleteUL[13:]:             {"BodyOffset", "EssenceContainers", "EssenceStreamID", "FooterPartition", "HeaderByteCount", "IndexByteCount", "IndexStreamID", "KAGSize", "MajorVersion", "MinorVersion", "OperationalPattern", "PreviousPartition", "ThisPartition"},
+	mxf2go.GHeaderPartitionClosedIncompleteUL[13:]:           {"BodyOffset", "EssenceContainers", "EssenceStreamID", "FooterPartition", "HeaderByteCount", "IndexByteCount", "IndexStreamID", "KAGSize", "MajorVersion", "MinorVersion", "OperationalPattern", "PreviousPartition", "ThisPartition"},
+	mxf2go.GHeaderPartitionOpenCompleteUL[13:]:               {"BodyOffset", "EssenceContainers", "EssenceStreamID", "FooterPartition", "HeaderByteCount", "IndexByteCount", "IndexStreamID", "KAGSize", "MajorVersion", "MinorVersion", "OperationalPattern", "PreviousPartition", "ThisPartition"},
+	mxf2go.GHeaderPartitionOpenIncompleteUL[13:]:             {"BodyOffset", "EssenceContainers", "EssenceStreamID", "FooterPartition", "HeaderByteCount", "IndexByteCount", "IndexStreamID", "KAGSize", "MajorVersion", "MinorVersion", "OperationalPattern", "PreviousPartition", "ThisPartition"},
+	mxf2go.GHeaderPartitionPackUL[13:]:                       {"BodyOffset", "EssenceContainers", "EssenceStreamID", "FooterPartition", "HeaderByteCount", "IndexByteCount", "IndexStreamID", "KAGSize", "MajorVersion", "MinorVersion", "OperationalPattern", "PreviousPartition", "ThisPartition"},
+	mxf2go.GIABEssenceDescriptorUL[13:]:                      {"AudioSampleRate", "ChannelCount", "QuantizationBits"},
+	mxf2go.GIABSoundfieldLabelSubDescriptorUL[13:]:           {"MCALabelDictionaryID", "MCALinkID", "MCATagSymbol"},
+	mxf2go.GISXDUL[13:]:                                      {"ContainerFormat", "DataEssenceCoding", "NamespaceURIUTF8", "SampleRate"},
+	mxf2go.GIdentificationUL[13:]:                            {"ApplicationName", "ApplicationProductID", "ApplicationSupplierName", "ApplicationVersionString", "GenerationID"},
+	mxf2go.GIndexTableSegmentUL[13:]:                         {"EssenceStreamID", "IndexDuration", "IndexEditRate", "IndexStartPosition", "InstanceID"},
+	mxf2go.GInterpolationDefinitionUL[13:]:                   {"DefinitionObjectIdentification", "DefinitionObjectName"},
+	mxf2go.GJPEG2000SubDescriptorUL[13:]:                     {"Csiz", "PictureComponentSizing", "Rsiz", "XOsiz", "XTOsiz", "XTsiz", "Xsiz", "YOsiz", "YTOsiz", "YTsiz", "Ysiz"},
+	mxf2go.GKLVDataUL[13:]:                                   {"KLVDataValue"},
+	mxf2go.GKLVDataDefinitionUL[13:]:                         {"DefinitionObjectIdentification", "DefinitionObjectName", "KLVDataParentProperties"},
+	mxf2go.GLinkEncryptionKeyLoadRequestUL[13:]:              {"ASMLinkEncryptionKeyBatch", "ASMRequestID"},
+	mxf2go.GLinkEncryptionKeyLoadResponseUL[13:]:             {"ASMBufferOverflowFlag", "ASMRequestID", "ASMResponse"},
+	mxf2go.GLinkEncryptionKeyQueryAllRequestUL[13:]:          {"ASMRequestID"},
+	mxf2go.GLinkEncryptionKeyQueryAllResponseUL[13:]:         {"ASMLinkEncryptionKeyIDBatch", "ASMRequestID", "ASMResponse"},
+	mxf2go.GLinkEncryptionKeyQueryIDRequestUL[13:]:           {"ASMLinkEncryptionKeyID", "ASMRequestID"},
+	mxf2go.GLinkEncryptionKeyQueryIDResponseUL[13:]:          {"ASMKeyPresentFlag", "ASMRequestID", "ASMResponse"},
+	mxf2go.GLinkEncryptionPurgeAllRequestUL[13:]:             {"ASMRequestID"},
+	mxf2go.GLinkEncryptionPurgeAllResponseUL[13:]:            {"ASMRequestID", "ASMResponse"},
+	mxf2go.GLinkEncryptionPurgeIDRequestUL[13:]:              {"ASMLinkEncryptionKeyID", "ASMRequestID"},
+	mxf2go.GLinkEncryptionPurgeIDResponseUL[13:]:             {"ASMKeyNotPresentFlag", "ASMRequestID", "ASMResponse"},
+	mxf2go.GLocationUL[13:]:                                  {"ContactID"},
+	mxf2go.GMCALabelSubDescriptorUL[13:]:                     {"MCALabelDictionaryID", "MCALinkID", "MCATagSymbol"},
+	mxf2go.GMPEGAudioDescriptorUL[13:]:                       {"AudioSampleRate", "ChannelCount", "QuantizationBits"},
+	mxf2go.GMPEGVideoDescriptorUL[13:]:                       {"ComponentDepth", "FrameLayout", "HorizontalSubsampling", "ImageAspectRatio", "StoredHeight", "StoredWidth", "VideoLineMap"},
+	mxf2go.GMRXessencedescriptorUL[13:]:                      {"ISO8601Time", "MetarexID", "RegURI"},
+	mxf2go.GMaterialPackageUL[13:]:                           {"CreationTime", "PackageID", "PackageLastModified", "PackageTracks"},
+	mxf2go.GMetaDefinitionUL[13:]:                            {"MetaDefinitionIdentification", "MetaDefinitionName"},
+	mxf2go.GMultipleDescriptorUL[13:]:                        {"FileDescriptors"},
+	mxf2go.GNestedScopeUL[13:]:                               {"ComponentDataDefinition", "NestedScopeTracks"},
+	mxf2go.GNetworkLocatorUL[13:]:                            {"URL"},
+	mxf2go.GOPDefinitionUL[13:]:                              {"DefinitionObjectIdentification", "DefinitionObjectName"},
+	mxf2go.GOperationDefinitionUL[13:]:                       {"DefinitionObjectIdentification", "DefinitionObjectName", "OperationDataDefinition", "OperationInputCount"},
+	mxf2go.GOperationGroupUL[13:]:                            {"ComponentDataDefinition", "Operation"},
+	mxf2go.GOperationsStereoscopicSubDescriptorUL[13:]:       {"StereoscopicEyeID"},
+	mxf2go.GOrganizationUL[13:]:                              {"ContactID"},
+	mxf2go.GPackageUL[13:]:                                   {"CreationTime", "PackageID", "PackageLastModified", "PackageTracks"},
+	mxf2go.GParameterUL[13:]:                                 {"ParameterDefinitionReference"},
+	mxf2go.GParameterDefinitionUL[13:]:                       {"DefinitionObjectIdentification", "DefinitionObjectName", "ParameterType"},
+	mxf2go.GParticipantUL[13:]:                               {"ParticipantID"},
+	mxf2go.GPartitionPackUL[13:]:                             {"BodyOffset", "EssenceContainers", "EssenceStreamID", "FooterPartition", "HeaderByteCount", "IndexByteCount", "IndexStreamID", "KAGSize", "MajorVersion", "MinorVersion", "OperationalPattern", "PreviousPartition", "ThisPartition"},
+	mxf2go.GPersonUL[13:]:                                    {"ContactID"},
+	mxf2go.GPictureDescriptorUL[13:]:                         {"FrameLayout", "ImageAspectRatio", "StoredHeight", "StoredWidth", "VideoLineMap"},
+	mxf2go.GPluginDefinitionUL[13:]:                          {"DefinitionObjectIdentification", "DefinitionObjectName", "PluginCategory", "PluginVersion"},
+	mxf2go.GPrefaceUL[13:]:                                   {"ContentStorageObject", "DescriptiveSchemes", "EssenceContainers", "FileLastModified", "FormatVersion", "IdentificationList", "OperationalPattern"},
+	mxf2go.GPrimerPackUL[13:]:                                {"LocalTagEntries"},
+	mxf2go.GProjectorCertificateRequestUL[13:]:               {"ASMRequestID"},
+	mxf2go.GProjectorCertificateResponseUL[13:]:              {"ASMProjectorCertificateData", "ASMRequestID", "ASMResponse"},
+	mxf2go.GPropertyAliasDefinitionUL[13:]:                   {"IsOptional", "MetaDefinitionIdentification", "MetaDefinitionName", "OriginalProperty", "PropertyType"},
+	mxf2go.GPropertyDefinitionUL[13:]:                        {"IsOptional", "MetaDefinitionIdentification", "MetaDefinitionName", "PropertyType"},
+	mxf2go.GPulldownUL[13:]:                                  {"ComponentDataDefinition", "InputSegment", "PhaseFrame", "PulldownDirection", "PulldownKind"},
+	mxf2go.GRGBADescriptorUL[13:]:                            {"FrameLayout", "ImageAspectRatio", "PixelLayout", "StoredHeight", "StoredWidth", "VideoLineMap"},
+	mxf2go.GRIFFChunkUL[13:]:                                 {"ChunkData", "ChunkID", "ChunkLength"},
+	mxf2go.GRP217DescriptorUL[13:]:                           {"RP217DataStreamPID", "RP217VideoStreamPID"},
+	mxf2go.GRootUL[13:]:                                      {"RootPreface"},
+	mxf2go.GSTLDescriptorUL[13:]:                             {"EventTextKind", "EventTextLanguageCode", "STLReferencePointTimecode"},
+	mxf2go.GSTLSubDescriptorUL[13:]:                          {"EventTextLanguageCode", "STLLineNumber"},
+	mxf2go.GScopeReferenceUL[13:]:                            {"ComponentDataDefinition", "RelativeScope", "RelativeTrack"},
+	mxf2go.GSecureProcessingBlockQueryRequestUL[13:]:         {"ASMRequestID"},
+	mxf2go.GSecureProcessingBlockQueryResponseUL[13:]:        {"ASMPlayoutStatus", "ASMProtocolVersion", "ASMRequestID", "ASMResponse"},
+	mxf2go.GSegmentUL[13:]:                                   {"ComponentDataDefinition"},
+	mxf2go.GSelectorUL[13:]:                                  {"ComponentDataDefinition", "SelectedSegment"},
+	mxf2go.GSequenceUL[13:]:                                  {"ComponentDataDefinition", "ComponentObjects"},
+	mxf2go.GSoundDescriptorUL[13:]:                           {"AudioSampleRate", "ChannelCount", "QuantizationBits"},
+	mxf2go.GSoundfieldGroupLabelSubDescriptorUL[13:]:         {"MCALabelDictionaryID", "MCALinkID", "MCATagSymbol"},
+	mxf2go.GSourceClipUL[13:]:                                {"ComponentDataDefinition", "SourcePackageID", "SourceTrackID", "StartPosition"},
+	mxf2go.GSourcePackageUL[13:]:                             {"CreationTime", "EssenceDescription", "PackageID", "PackageLastModified", "PackageTracks"},
+	mxf2go.GSourceReferenceUL[13:]:                           {"ComponentDataDefinition", "SourcePackageID", "SourceTrackID"},
+	mxf2go.GStaticTrackUL[13:]:                               {"EssenceTrackNumber", "TrackID", "TrackSegment"},
+	mxf2go.GTIFFDescriptorUL[13:]:                            {"IsContiguous", "IsUniform", "TIFFSummary"},
+	mxf2go.GTIFFPictureEssenceDescriptorUL[13:]:              {"FrameLayout", "ImageAspectRatio", "StoredHeight", "StoredWidth", "VideoLineMap"},
+	mxf2go.GTaggedValueUL[13:]:                               {"IndirectValue", "Tag"},
+	mxf2go.GTaggedValueDefinitionUL[13:]:                     {"DefinitionObjectIdentification", "DefinitionObjectName", "TaggedValueParentProperties"},
+	mxf2go.GTargetFrameSubDescriptorUL[13:]:                  {"MediaType", "TargetFrameAncillaryResourceID", "TargetFrameColorPrimaries", "TargetFrameComponentMaxRef", "TargetFrameComponentMinRef", "TargetFrameEssenceStreamID", "TargetFrameIndex", "TargetFrameTransferCharacteristic"},
+	mxf2go.GTextBasedFrameworkUL[13:]:                        {"TextBasedObject"},
+	mxf2go.GTextBasedObjectUL[13:]:                           {"RFC5646TextLanguageCode", "TextBasedMetadataPayloadSchemeID", "TextMIMEMediaType"},
+	mxf2go.GTextClipUL[13:]:                                  {"ComponentDataDefinition", "SourcePackageID", "SourceTrackID"},
+	mxf2go.GTextLocatorUL[13:]:                               {"LocationName"},
+	mxf2go.GTimeRequestUL[13:]:                               {"ASMRequestID"},
+	mxf2go.GTimeResponseUL[13:]:                              {"ASMCurrentTime", "ASMRequestID", "ASMResponse"},
+	mxf2go.GTimecodeUL[13:]:                                  {"ComponentDataDefinition", "DropFrame", "FramesPerSecond", "StartTimecode"},
+	mxf2go.GTimecodeStreamUL[13:]:                            {"ComponentDataDefinition", "TimecodeSource", "TimecodeStreamData", "TimecodeStreamSampleRate"},
+	mxf2go.GTimecodeStream12MUL[13:]:                         {"ComponentDataDefinition", "IncludeSync", "TimecodeSource", "TimecodeStreamData", "TimecodeStreamSampleRate"},
+	mxf2go.GTimelineTrackUL[13:]:                             {"EditRate", "EssenceTrackNumber", "Origin", "TrackID", "TrackSegment"},
+	mxf2go.GTrackUL[13:]:                                     {"EssenceTrackNumber", "TrackID", "TrackSegment"},
+	mxf2go.GTransitionUL[13:]:                                {"ComponentDataDefinition", "CutPoint", "TransitionOperation"},
+	mxf2go.GTypeDefinitionUL[13:]:                            {"MetaDefinitionIdentification", "MetaDefinitionName"},
+	mxf2go.GTypeDefinitionCharacterUL[13:]:                   {"MetaDefinitionIdentification", "MetaDefinitionName"},
+	mxf2go.GTypeDefinitionEnumerationUL[13:]:                 {"ElementNames", "ElementType", "ElementValues", "MetaDefinitionIdentification", "MetaDefinitionName"},
+	mxf2go.GTypeDefinitionExtendibleEnumerationUL[13:]:       {"MetaDefinitionIdentification", "MetaDefinitionName"},
+	mxf2go.GTypeDefinitionFixedArrayUL[13:]:                  {"ElementCount", "FixedArrayElementType", "MetaDefinitionIdentification", "MetaDefinitionName"},
+	mxf2go.GTypeDefinitionIndirectUL[13:]:                    {"MetaDefinitionIdentification", "MetaDefinitionName"},
+	mxf2go.GTypeDefinitionIntegerUL[13:]:                     {"IsSigned", "MetaDefinitionIdentification", "MetaDefinitionName", "Size"},
+	mxf2go.GTypeDefinitionOpaqueUL[13:]:                      {"MetaDefinitionIdentification", "MetaDefinitionName"},
+	mxf2go.GTypeDefinitionRecordUL[13:]:                      {"MemberNames", "MemberTypes", "MetaDefinitionIdentification", "MetaDefinitionName"},
+	mxf2go.GTypeDefinitionRenameUL[13:]:                      {"MetaDefinitionIdentification", "MetaDefinitionName", "RenamedType"},
+	mxf2go.GTypeDefinitionSetUL[13:]:                         {"MetaDefinitionIdentification", "MetaDefinitionName", "SetElementType"},
+	mxf2go.GTypeDefinitionStreamUL[13:]:                      {"MetaDefinitionIdentification", "MetaDefinitionName"},
+	mxf2go.GTypeDefinitionStringUL[13:]:                      {"MetaDefinitionIdentification", "MetaDefinitionName", "StringElementType"},
+	mxf2go.GTypeDefinitionStrongObjectReferenceUL[13:]:       {"MetaDefinitionIdentification", "MetaDefinitionName", "ReferencedType"},
+	mxf2go.GTypeDefinitionVariableArrayUL[13:]:               {"MetaDefinitionIdentification", "MetaDefinitionName", "VariableArrayElementType"},
+	mxf2go.GTypeDefinitionWeakObjectReferenceUL[13:]:         {"MetaDefinitionIdentification", "MetaDefinitionName", "TargetSet", "WeakReferencedType"},
+	mxf2go.GUTF16TextBasedSetUL[13:]:                         {"RFC5646TextLanguageCode", "TextBasedMetadataPayloadSchemeID", "TextMIMEMediaType", "UTF16TextData"},
+	mxf2go.GUTF8TextBasedSetUL[13:]:                          {"RFC5646TextLanguageCode", "TextBasedMetadataPayloadSchemeID", "TextMIMEMediaType", "UTF8TextData"},
+	mxf2go.GVC1VideoDescriptorUL[13:]:                        {"ComponentDepth", "FrameLayout", "HorizontalSubsampling", "ImageAspectRatio", "StoredHeight", "StoredWidth", "VideoLineMap"},
+	mxf2go.GVC2SubDescriptorUL[13:]:                          {"VC2Level", "VC2MajorVersion", "VC2MinorVersion", "VC2Profile"},
+	mxf2go.GVC5BayerPictureEssenceSubDescriptorUL[13:]:       {"VC5BayerComponentPattern"},
+	mxf2go.GVC5CDCIPictureEssenceSubDescriptorUL[13:]:        {"VC5AlphaSampling"},
+	mxf2go.GVaryingValueUL[13:]:                              {"Interpolation", "ParameterDefinitionReference", "PointList"},
+	mxf2go.GWAVEDescriptorUL[13:]:                            {"WAVESummary"},
+	mxf2go.GWAVEPCMDescriptorUL[13:]:                         {"AudioSampleRate", "AverageBytesPerSecond", "BlockAlign", "ChannelCount", "QuantizationBits"},
+	mxf2go.GXMLDescriptorUL[13:]:                             {"DefaultNamespaceURI"},
+}
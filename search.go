@@ -0,0 +1,521 @@
+package mxftest
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	mxf2go "github.com/metarex-media/mxf-to-go"
+)
+
+/*
+Search follows SQL syntax for nodes within a nodes children
+
+e.g. select * where UL = 060e2b34.027f0101.0d010101.01010f00
+
+"from" is not used as there are no tables within a node,
+the node is a table unto itself.
+
+Available fields are:
+
+  - ul
+  - label - a label of the node, e.g. essence or the data definition of a track
+  - offset - the byte offset of the node in the file
+  - sniff:{field name} - e.g. sniff:/root searches the sniff value of root
+  - {property name} - a decoded property of a header metadata group, e.g. ContainerDuration.
+    ULs are formatted as 060e2b34.04010105.0e090606.00000000 and rationals as 25/1.
+
+Values are compared with =, ==, <>, !=, <, >, <=, >=, like and not like.
+Conditions can be combined with and, or, not and parentheses,
+e.g. select * where not (ul = 060e2b34.027f0101.0d010101.01010f00 or sniff:/root = "a value")
+
+The keywords and field names are not case sensitive, apart from the sniff field names.
+ULs and symbols are compared without case, other values are compared exactly.
+*/
+func (n Node) Search(searchfield string) ([]*Node, error) {
+	q, err := parseSearch(searchfield)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.search(q)
+}
+
+// search searches the children of the node with a parsed query
+func (n Node) search(q *query) ([]*Node, error) {
+	if q.table != "" {
+		return nil, &SyntaxError{Query: q.text, Column: q.tableColumn, Msg: "nodes do not have tables, remove the from statement"}
+	}
+
+	match, err := compileCondition(q, q.where, nodeField)
+	if err != nil {
+		return nil, err
+	}
+
+	return searchNodes(n.Children, match), nil
+}
+
+/*
+Search follows SQL syntax for nodes within a partition
+
+e.g. select * from essence where UL <> 060e2b34.01020105.0e090502.017f017f
+
+Available tables are:
+
+  - essence
+  - metadata
+
+Available fields are:
+
+  - ul
+  - label - a label of the node, e.g. essence or the data definition of a track
+  - offset - the byte offset of the node in the file
+  - sniff:{field name} - e.g. sniff:/root searches the sniff value of root
+  - {property name} - a decoded property of a header metadata group, e.g. ContainerDuration.
+    ULs are formatted as 060e2b34.04010105.0e090606.00000000 and rationals as 25/1.
+
+Values are compared with =, ==, <>, !=, <, >, <=, >=, like and not like.
+Conditions can be combined with and, or, not and parentheses,
+e.g. select * from metadata where ul = 060e2b34.027f0101.0d010101.01011800 or ul = 060e2b34.027f0101.0d010101.01013000
+
+Without a where statement the top level nodes of the table are returned,
+otherwise every node in the table is searched. The properties are decoded
+with the primer of the partition the first time they are searched.
+
+Partitions made by MakeAST are indexed by the ul, label, InstanceUID and sniff fields,
+so searches that compare these fields with = only check the nodes that could match.
+
+The keywords and field names are not case sensitive, apart from the sniff field names.
+ULs and symbols are compared without case, other values are compared exactly.
+*/
+func (p PartitionNode) Search(searchfield string) ([]*Node, error) {
+	q, err := parseSearch(searchfield)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.search(q)
+}
+
+// search searches the essence or metadata of the partition with a parsed query
+func (p PartitionNode) search(q *query) ([]*Node, error) {
+	searchFields, index, err := p.table(q)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.where == nil {
+		return searchFields, nil
+	}
+
+	match, err := compileCondition(q, q.where, nodeField)
+	if err != nil {
+		return nil, err
+	}
+
+	if index != nil {
+		if found, ok := index.search(q.where, match); ok {
+			return found, nil
+		}
+	}
+
+	return searchNodes(searchFields, match), nil
+}
+
+// table returns the top level nodes of the table of a query, along with
+// the index of the table. The index is nil if the partition was not made by MakeAST,
+// or the table has changed since.
+func (p PartitionNode) table(q *query) ([]*Node, *nodeIndex, error) {
+	switch q.table {
+	case "essence":
+		return p.Essence, validIndex(p.essenceIndex, p.Essence), nil
+	case "metadata":
+		return p.HeaderMetadata, validIndex(p.metadataIndex, p.HeaderMetadata), nil
+	case "":
+		return nil, nil, &SyntaxError{Query: q.text, Column: len([]rune(q.text)) + 1, Msg: "expected a from statement with the essence or metadata table"}
+	default:
+		return nil, nil, &SyntaxError{Query: q.text, Column: q.tableColumn, Msg: fmt.Sprintf("unknown table %q", q.table)}
+	}
+}
+
+// searchNodes returns every node, and the children of those nodes,
+// that matches the search. The nodes are returned in pre-order.
+func searchNodes(nodes []*Node, match func(*Node) bool) []*Node {
+	out := make([]*Node, 0)
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+
+		if match(node) {
+			out = append(out, node)
+		}
+		// search through the children as well
+		out = append(out, searchNodes(node.Children, match)...)
+	}
+
+	return out
+}
+
+// nodeField returns the searchable fields of a node
+func nodeField(name string) (queryField[*Node], bool) {
+	switch {
+	case strings.EqualFold(name, "ul"):
+		return queryField[*Node]{ul: true, values: func(n *Node) []string {
+			return []string{n.Properties.UL()}
+		}}, true
+	case strings.EqualFold(name, "label"):
+		return queryField[*Node]{ul: true, values: func(n *Node) []string {
+			return n.Properties.Label()
+		}}, true
+	case strings.EqualFold(name, "offset"):
+		// the byte offset of the node in the file
+		return queryField[*Node]{values: func(n *Node) []string {
+			return []string{fmt.Sprintf("%v", n.Key.Start)}
+		}}, true
+	case len(name) > 6 && strings.EqualFold(name[:6], "sniff:"):
+		key := name[6:]
+		return queryField[*Node]{values: func(n *Node) []string {
+			if sniff, ok := n.Sniffs[key]; ok && sniff != nil {
+				return []string{sniff.Field}
+			}
+
+			return nil
+		}}, true
+	default:
+		// the decoded properties of the group
+		prop, ok := propertyName(name)
+		if !ok {
+			return queryField[*Node]{}, false
+		}
+
+		return queryField[*Node]{values: func(n *Node) []string {
+			value, ok := n.decodedProperties()[prop]
+			if !ok {
+				return nil
+			}
+
+			return propertyValues(value)
+		}, ulOf: func(n *Node) bool {
+			value, ok := n.decodedProperties()[prop]
+
+			return ok && ulProperty(value)
+		}}, true
+	}
+}
+
+// ulProperty checks if the register type of a decoded property is a UL,
+// or an array of ULs.
+func ulProperty(value any) bool {
+	if value == nil {
+		return false
+	}
+
+	typ := reflect.TypeOf(value)
+	if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = typ.Elem()
+	}
+
+	return typ.ConvertibleTo(auidType)
+}
+
+// auidType is used for finding types based on mxf2go.TAUID
+var auidType = reflect.TypeOf(mxf2go.TAUID{})
+
+// propertyValues converts a decoded property into the strings it is searched with.
+// ULs, UUIDs and references are formatted as 060e2b34.04010105.0e090606.00000000,
+// rationals as 25/1 and arrays return a value for each item.
+func propertyValues(value any) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case mxf2go.TRational:
+		return []string{fmt.Sprintf("%v/%v", v.Numerator, v.Denominator)}
+	}
+
+	val := reflect.ValueOf(value)
+	switch {
+	case val.Type().ConvertibleTo(auidType):
+		b, _ := mxf2go.EncodeTAUID(val.Convert(auidType).Interface().(mxf2go.TAUID))
+		return []string{fullName(b)}
+	case val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.Int32:
+		// UTF strings
+		return []string{string(val.Convert(reflect.TypeOf([]rune{})).Interface().([]rune))}
+	case (val.Kind() == reflect.Slice || val.Kind() == reflect.Array) && val.Type().Elem().Kind() == reflect.Uint8:
+		b := make([]byte, val.Len())
+		reflect.Copy(reflect.ValueOf(b), val)
+		if len(b) == 16 {
+			return []string{fullName(b)}
+		}
+
+		return []string{fmt.Sprintf("%x", b)}
+	case val.Kind() == reflect.Slice || val.Kind() == reflect.Array:
+		out := make([]string, 0, val.Len())
+		for i := 0; i < val.Len(); i++ {
+			out = append(out, propertyValues(val.Index(i).Interface())...)
+		}
+
+		return out
+	default:
+		return []string{fmt.Sprintf("%v", value)}
+	}
+}
+
+/*
+Search follows SQL for finding things within a partition
+e.g. select * from partitions where type <> header
+
+Available tables are:
+
+  - partition
+  - partitions
+
+Available fields are:
+
+  - essence - the count of essence
+  - type - the partition types
+  - metadata - the count of metadata
+  - status - the partition status e.g. closedcomplete
+  - operationalpattern - the operational pattern UL
+  - op - the register symbol of the operational pattern e.g. MXFOP1aSingleItemSinglePackageUniTrackStreamInternal
+  - essencecontainer - an essence container UL of the partition,
+    = matches if any container matches, <> matches if no containers match
+  - bodysid, indexsid - the stream IDs of the partition
+  - kag - the KAG size of the partition
+  - thispartition, previouspartition, footerpartition - the partition offsets from the partition pack
+  - headerbytecount, indexbytecount, bodyoffset - the byte counts and body offset from the partition pack
+  - majorversion, minorversion - the version of the partition pack
+  - position - the position of the partition in the file, starting at 0
+  - offset - the byte offset of the partition in the file
+
+Values are compared with =, ==, <>, !=, <, >, <=, >=, like and not like.
+Conditions can be combined with and, or, not and parentheses,
+e.g. select * from partitions where bodysid = 1 and essence > 0 and (type = body or type = footer)
+
+The keywords and field names are not case sensitive. ULs and symbols, and the
+type and status values, e.g. type = Footer, are compared without case.
+Other values are compared exactly.
+*/
+func (m MXFNode) Search(searchfield string) ([]*PartitionNode, error) {
+	q, err := parseSearch(searchfield)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.search(q)
+}
+
+// search searches the partitions of the file with a parsed query
+func (m MXFNode) search(q *query) ([]*PartitionNode, error) {
+	switch q.table {
+	case "partition", "partitions":
+	case "":
+		return nil, &SyntaxError{Query: q.text, Column: len([]rune(q.text)) + 1, Msg: "expected a from statement with the partitions table"}
+	default:
+		return nil, &SyntaxError{Query: q.text, Column: q.tableColumn, Msg: fmt.Sprintf("unknown table %q", q.table)}
+	}
+
+	match, err := compileCondition(q, q.where, partitionField)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*PartitionNode, 0)
+	for _, search := range m.Partitions {
+		if match(search) {
+			out = append(out, search)
+		}
+	}
+
+	return out, nil
+}
+
+// NodeResult is a node found by a file wide search,
+// with the partition the node belongs to.
+type NodeResult struct {
+	Partition *PartitionNode
+	Node      *Node
+}
+
+/*
+SearchNodes searches for the essence or metadata nodes of every partition
+in the file, by joining the partitions to their nodes.
+
+e.g. select essence from partitions where type = body and essence.sniff:GPSSchema <> pass
+
+The selected column is the table of nodes, either essence or metadata.
+The fields of the partition are used as in MXFNode.Search, and the fields
+of the nodes are used as in PartitionNode.Search, with the table as a prefix.
+e.g. essence.ul or metadata.sniff:/root. The prefix can be left out if the
+field is not a partition field, and partition fields can be prefixed with partition.
+
+The nodes are returned with their partitions in the order they are found in the file.
+*/
+func (m MXFNode) SearchNodes(searchfield string) ([]NodeResult, error) {
+	q, err := parseQuery(searchfield)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.joinSearch(q)
+}
+
+// joinSearch searches the joined nodes of the file with a parsed query
+func (m MXFNode) joinSearch(q *query) ([]NodeResult, error) {
+	switch q.table {
+	case "partition", "partitions":
+	case "":
+		return nil, &SyntaxError{Query: q.text, Column: len([]rune(q.text)) + 1, Msg: "expected a from statement with the partitions table"}
+	default:
+		return nil, &SyntaxError{Query: q.text, Column: q.tableColumn, Msg: fmt.Sprintf("unknown table %q", q.table)}
+	}
+
+	table := strings.ToLower(q.columns[0].name)
+	if len(q.columns) > 1 || (table != "essence" && table != "metadata") {
+		col := q.columns[len(q.columns)-1]
+		return nil, &SyntaxError{Query: q.text, Column: col.column, Msg: fmt.Sprintf("expected essence or metadata to be selected but found %q", col.name)}
+	}
+
+	match, err := compileCondition(q, q.where, joinField(table, false))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]NodeResult, 0)
+	for _, result := range m.joinNodes(table) {
+		if match(result) {
+			out = append(out, result)
+		}
+	}
+
+	return out, nil
+}
+
+// joinNodes joins every partition to the nodes of the essence or metadata table.
+// The nodes of each partition are in the order they are found in the file.
+func (m MXFNode) joinNodes(table string) []NodeResult {
+	out := make([]NodeResult, 0)
+	for _, part := range m.Partitions {
+		nodes := part.Essence
+		if table == "metadata" {
+			nodes = searchNodes(part.HeaderMetadata, func(*Node) bool { return true })
+		}
+
+		joined := make([]NodeResult, len(nodes))
+		for i, node := range nodes {
+			joined[i] = NodeResult{Partition: part, Node: node}
+		}
+
+		// order the nodes by their position in the file
+		slices.SortStableFunc(joined, func(a, b NodeResult) int {
+			return a.Node.Key.Start - b.Node.Key.Start
+		})
+		out = append(out, joined...)
+	}
+
+	return out
+}
+
+// joinField returns the searchable fields of a partition and its nodes,
+// where table is the table of nodes the partition is joined to.
+// Fields prefixed with the table are node fields, and fields prefixed
+// with partition are partition fields.
+//
+// Unprefixed fields are partition fields when the partitions are searched, as with SearchNodes.
+// When the nodes are the table, as in MXFNode.Query, the ul, label, offset and sniff fields are node fields.
+// The partition fields are then used, then the node properties.
+func joinField(table string, nodeTable bool) func(name string) (queryField[NodeResult], bool) {
+	return func(name string) (queryField[NodeResult], bool) {
+		prefix, fieldName, prefixed := strings.Cut(name, ".")
+		switch {
+		case prefixed && strings.EqualFold(prefix, table):
+			field, ok := nodeField(fieldName)
+
+			return resultField(field, func(r NodeResult) *Node { return r.Node }), ok
+		case prefixed && (strings.EqualFold(prefix, "partition") || strings.EqualFold(prefix, "partitions")):
+			field, ok := partitionField(fieldName)
+
+			return resultField(field, func(r NodeResult) *PartitionNode { return r.Partition }), ok
+		case nodeTable && nodeBuiltIn(name):
+			field, ok := nodeField(name)
+
+			return resultField(field, func(r NodeResult) *Node { return r.Node }), ok
+		}
+
+		if field, ok := partitionField(name); ok {
+			return resultField(field, func(r NodeResult) *PartitionNode { return r.Partition }), true
+		}
+
+		field, ok := nodeField(name)
+
+		return resultField(field, func(r NodeResult) *Node { return r.Node }), ok
+	}
+}
+
+// nodeBuiltIn checks if a field is one of the fields every node has,
+// rather than a decoded property of the node.
+func nodeBuiltIn(name string) bool {
+	return strings.EqualFold(name, "ul") || strings.EqualFold(name, "label") || strings.EqualFold(name, "offset") ||
+		(len(name) > 6 && strings.EqualFold(name[:6], "sniff:"))
+}
+
+// resultField converts the field of a node or partition
+// into the field of a NodeResult.
+func resultField[T any](field queryField[T], item func(NodeResult) T) queryField[NodeResult] {
+	out := queryField[NodeResult]{ul: field.ul, fold: field.fold}
+	if field.values != nil {
+		out.values = func(r NodeResult) []string { return field.values(item(r)) }
+	}
+	if field.ulOf != nil {
+		out.ulOf = func(r NodeResult) bool { return field.ulOf(item(r)) }
+	}
+
+	return out
+}
+
+// partitionFields are the searchable fields of a partition
+var partitionFields = map[string]queryField[*PartitionNode]{
+	"type": {fold: true, values: func(p *PartitionNode) []string { return []string{p.Props.PartitionType} }},
+	"essence": {values: func(p *PartitionNode) []string {
+		return []string{fmt.Sprintf("%v", len(p.Essence))}
+	}},
+	"metadata": {values: func(p *PartitionNode) []string {
+		return []string{fmt.Sprintf("%v", len(p.HeaderMetadata))}
+	}},
+	"status":             {fold: true, values: func(p *PartitionNode) []string { return []string{p.Props.Status} }},
+	"operationalpattern": {ul: true, values: func(p *PartitionNode) []string { return []string{p.Props.OperationalPattern} }},
+	"op":                 {ul: true, values: func(p *PartitionNode) []string { return []string{p.Props.OperationalPatternName} }},
+	"essencecontainer":   {ul: true, values: func(p *PartitionNode) []string { return p.Props.EssenceContainers }},
+	"essencecontainers":  {ul: true, values: func(p *PartitionNode) []string { return p.Props.EssenceContainers }},
+	"bodysid":            partitionNumber(func(p *PartitionNode) any { return p.Props.BodySID }),
+	"indexsid":           partitionNumber(func(p *PartitionNode) any { return p.Props.IndexSID }),
+	"kag":                partitionNumber(func(p *PartitionNode) any { return p.Props.KAGSize }),
+	"kagsize":            partitionNumber(func(p *PartitionNode) any { return p.Props.KAGSize }),
+	"thispartition":      partitionNumber(func(p *PartitionNode) any { return p.Props.ThisPartition }),
+	"previouspartition":  partitionNumber(func(p *PartitionNode) any { return p.Props.PreviousPartition }),
+	"footerpartition":    partitionNumber(func(p *PartitionNode) any { return p.Props.FooterPartition }),
+	"headerbytecount":    partitionNumber(func(p *PartitionNode) any { return p.Props.HeaderByteCount }),
+	"indexbytecount":     partitionNumber(func(p *PartitionNode) any { return p.Props.IndexByteCount }),
+	"bodyoffset":         partitionNumber(func(p *PartitionNode) any { return p.Props.BodyOffset }),
+	"majorversion":       partitionNumber(func(p *PartitionNode) any { return p.Props.MajorVersion }),
+	"minorversion":       partitionNumber(func(p *PartitionNode) any { return p.Props.MinorVersion }),
+	"position":           partitionNumber(func(p *PartitionNode) any { return p.PartitionPos }),
+	"offset":             partitionNumber(func(p *PartitionNode) any { return p.Key.Start }),
+}
+
+// partitionNumber is a numeric field of a partition
+func partitionNumber(field func(p *PartitionNode) any) queryField[*PartitionNode] {
+	return queryField[*PartitionNode]{values: func(p *PartitionNode) []string {
+		return []string{fmt.Sprintf("%v", field(p))}
+	}}
+}
+
+// partitionField returns the searchable fields of a partition
+func partitionField(name string) (queryField[*PartitionNode], bool) {
+	field, ok := partitionFields[strings.ToLower(name)]
+
+	return field, ok
+}
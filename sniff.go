@@ -1,6 +1,12 @@
 package mxftest
 
-import "context"
+import (
+	"cmp"
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+)
 
 // CType is used for declaring content types of a data type.
 // These are a separate type to make them easy to identify with autocomplete etc.
@@ -18,28 +24,81 @@ const (
 type DataIdentifier struct {
 	DataFunc    func([]byte) bool
 	ContentType CType
+	// Priority is the order the identifiers are checked in, the highest priority is checked first.
+	// Identifiers with the same priority are checked in the order they were added to the specifications.
+	Priority int
+	// Certainty is an optional score of how certain a match is, as a %.
+	// Without it a match is 100% certain.
+	Certainty func([]byte) float64
+	// ID is the identity of the identifier. Specifications that give identifiers
+	// with the same ID share one identifier, with the sniffers of every specification.
+	// Identifiers without an ID are never merged.
+	ID string
 }
 
-// Sniff checks a stream of bytes to find the data type.
-// it then performs any further sniff tests based on the type of data it finds,
-// if it finds any data types that match.
-// It stops searching after finding a data type that matches.
+/*
+Sniff checks a stream of bytes to find the data type.
+it then performs any further sniff tests based on the type of data it finds,
+if it finds any data types that match.
+It stops searching after finding a data type that matches.
+
+The identifiers are checked in priority order, then by content type and ID, so the
+same data type is found every time. Identifiers that are the same in all of these
+are checked in the order of their address, so the same identifier, and its sniffers,
+is found every time for a map of identifiers.
+Identifiers with the same ID are the same identifier, and have their sniffers merged.
+*/
 func Sniff(data []byte, sniffers map[*DataIdentifier][]Sniffer) map[string]*SniffResult {
+	ids := make([]*DataIdentifier, 0, len(sniffers))
+	for id := range sniffers {
+		ids = append(ids, id)
+	}
 
-	// do the datatype test first
-	// if its not that data type bin it off
+	slices.SortFunc(ids, func(a, b *DataIdentifier) int {
+		return cmp.Or(cmp.Compare(b.Priority, a.Priority), strings.Compare(string(a.ContentType), string(b.ContentType)),
+			strings.Compare(a.ID, b.ID), cmp.Compare(reflect.ValueOf(a).Pointer(), reflect.ValueOf(b).Pointer()))
+	})
+
+	tests := make([]SniffTest, 0, len(ids))
+	for _, id := range ids {
+		tests = addSniffTest(tests, SniffTest{DataID: *id, Sniffs: sniffers[id]})
+	}
+
+	out, _ := sniffData(data, tests, false)
+
+	return out
+}
+
+// sniffData runs the sniff tests in order, stopping at the first data type
+// that matches. If all is true, every identifier is checked and every
+// content type that matches is returned, in the order they were checked.
+func sniffData(data []byte, tests []SniffTest, all bool) (map[string]*SniffResult, []SniffResult) {
 	sniffRes := make(map[string]*SniffResult)
-	for dataType, sniffs := range sniffers {
+	var matches []SniffResult
 
-		dt := *dataType
+	for _, test := range tests {
+		dt := test.DataID
 		if !dt.DataFunc(data) {
 			// check the next datatype
 			continue
 		}
 
+		if all {
+			certainty := 100.0
+			if dt.Certainty != nil {
+				certainty = dt.Certainty(data)
+			}
+			matches = append(matches, SniffResult{Key: ContentTypeKey, Field: string(dt.ContentType), Data: dt.ContentType, Certainty: certainty})
+		}
+
+		// only the first data type to match is sniffed
+		if _, found := sniffRes[ContentTypeKey]; found {
+			continue
+		}
+
 		sniffRes[ContentTypeKey] = &SniffResult{Key: ContentTypeKey, Field: string(dt.ContentType)}
 
-		for _, sniff := range sniffs {
+		for _, sniff := range test.Sniffs {
 			snif := *sniff
 			res := snif(data)
 			// if there's something more than 0% certainty add it
@@ -50,11 +109,38 @@ func Sniff(data []byte, sniffers map[*DataIdentifier][]Sniffer) map[string]*Snif
 				sniffRes[res.Key] = &res
 			}
 		}
-		// return after the valid datatype was found?
-		return sniffRes
+
+		// return after the valid datatype was found
+		if !all {
+			break
+		}
+	}
+
+	return sniffRes, matches
+}
+
+// addSniffTest adds a sniff test to the tests, merging the sniffers
+// with any test that has the same identifier.
+func addSniffTest(tests []SniffTest, test SniffTest) []SniffTest {
+	pos := slices.IndexFunc(tests, func(t SniffTest) bool { return sameIdentifier(t.DataID, test.DataID) })
+	if pos < 0 {
+		tests = append(tests, SniffTest{DataID: test.DataID, Sniffs: make([]Sniffer, 0, len(test.Sniffs))})
+		pos = len(tests) - 1
+	}
+
+	for _, sniff := range test.Sniffs {
+		// check each sniffer and make sure its not duplicated for that data test
+		if !slices.Contains(tests[pos].Sniffs, sniff) {
+			tests[pos].Sniffs = append(tests[pos].Sniffs, sniff)
+		}
 	}
 
-	return sniffRes
+	return tests
+}
+
+// sameIdentifier checks if two identifiers have the same ID
+func sameIdentifier(a, b DataIdentifier) bool {
+	return a.ID != "" && a.ID == b.ID
 }
 
 // SniffTest contains an identifier
@@ -2,8 +2,12 @@ package mxftest
 
 import (
 	"fmt"
+	"math"
+	"os"
 	"testing"
 
+	"github.com/metarex-media/mrx-tool/klv"
+
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -64,3 +68,144 @@ func mockSniffer(key, out string, certain float64) Sniffer {
 
 	return &outFunc
 }
+
+func TestSniffOrder(t *testing.T) {
+	low, high := mockPasser(true, "low"), mockPasser(true, "high")
+	high.Priority = 10
+	alpha, beta := mockPasser(true, "alpha"), mockPasser(true, "beta")
+	low.ID, high.ID, alpha.ID = "low", "high", "alpha"
+	lowest, highest := mockPasser(true, "lowest"), mockPasser(true, "highest")
+	lowest.Priority, highest.Priority = math.MinInt, math.MaxInt
+	// identifiers that can only be told apart by their sniffers
+	twin, otherTwin := mockPasser(true, "twin"), mockPasser(true, "twin")
+	twins := map[*DataIdentifier][]Sniffer{twin: {mockSniffer("test", "first", 100)}, otherTwin: {mockSniffer("test", "second", 100)}}
+	twinExpected := Sniff([]byte{}, twins)["test"].Field
+
+	orders := []struct {
+		name     string
+		sniffers map[*DataIdentifier][]Sniffer
+		expected string
+	}{
+		{name: "a low and high priority identifier", sniffers: map[*DataIdentifier][]Sniffer{low: {mockSniffer("test", "low", 100)}, high: {mockSniffer("test", "high", 100)}}, expected: "high"},
+		{name: "two identifiers with the same priority", sniffers: map[*DataIdentifier][]Sniffer{beta: {mockSniffer("test", "beta", 100)}, alpha: {mockSniffer("test", "alpha", 100)}}, expected: "alpha"},
+		{name: "the lowest and highest priority identifiers", sniffers: map[*DataIdentifier][]Sniffer{lowest: {mockSniffer("test", "lowest", 100)}, highest: {mockSniffer("test", "highest", 100)}}, expected: "highest"},
+	}
+
+	twinFound := make(map[string]int)
+	for range 50 {
+		res := Sniff([]byte{}, twins)
+		twinFound[res[ContentTypeKey].Field+res["test"].Field]++
+	}
+
+	Convey("Checking the sniff function finds the same identifier every time", t, func() {
+		Convey("sniffing 50 times with two identifiers with the same priority, content type and no ID", func() {
+			Convey("The same sniff results are found every time", func() {
+				So(twinExpected, ShouldBeIn, []string{"first", "second"})
+				So(twinFound, ShouldResemble, map[string]int{"twin" + twinExpected: 50})
+			})
+		})
+	})
+
+	for _, order := range orders {
+		found := make(map[string]int)
+		for range 50 {
+			res := Sniff([]byte{}, order.sniffers)
+			found[res[ContentTypeKey].Field+res["test"].Field]++
+		}
+
+		Convey("Checking the sniff function finds the same data type every time", t, func() {
+			Convey(fmt.Sprintf("sniffing 50 times with %s that both match the data", order.name), func() {
+				Convey(fmt.Sprintf("%s is found every time, with its sniff results", order.expected), func() {
+					So(found, ShouldResemble, map[string]int{order.expected + order.expected: 50})
+				})
+			})
+		})
+	}
+
+	// the same identifiers given by several specifications
+	first, second := mockSniffer("first", "pass", 100), mockSniffer("second", "pass", 100)
+	base, _ := generateSpecifications(
+		*NewSpecification(WithSniffTest(SniffTest{DataID: *low, Sniffs: []Sniffer{first}})),
+		*NewSpecification(WithSniffTest(SniffTest{DataID: *alpha, Sniffs: []Sniffer{first}})),
+		*NewSpecification(WithSniffTest(SniffTest{DataID: *low, Sniffs: []Sniffer{first, second}})),
+		*NewSpecification(WithSniffTest(SniffTest{DataID: *high, Sniffs: []Sniffer{second}})),
+	)
+
+	Convey("Checking the sniff tests of specifications are merged and ordered by priority", t, func() {
+		Convey("generating the specifications with the same identifier given twice", func() {
+			Convey("the identifier is only sniffed once, with both sniffers, after the high priority identifier", func() {
+				So(len(base.sniffTests), ShouldEqual, 3)
+				So(base.sniffTests[0].DataID.ContentType, ShouldEqual, CType("high"))
+				So(base.sniffTests[1].DataID.ContentType, ShouldEqual, CType("low"))
+				So(base.sniffTests[1].Sniffs, ShouldResemble, []Sniffer{first, second})
+				So(base.sniffTests[2].DataID.ContentType, ShouldEqual, CType("alpha"))
+			})
+		})
+	})
+
+	// identifiers made from the same function literal, without an ID
+	passer, failer := mockPasser(true, "same"), mockPasser(false, "same")
+	unmerged, _ := generateSpecifications(
+		*NewSpecification(WithSniffTest(SniffTest{DataID: *failer, Sniffs: []Sniffer{first}})),
+		*NewSpecification(WithSniffTest(SniffTest{DataID: *passer, Sniffs: []Sniffer{second}})),
+	)
+	unmergedRes, _ := sniffData([]byte{}, unmerged.sniffTests, false)
+
+	Convey("Checking identifiers without an ID are not merged", t, func() {
+		Convey("generating the specifications with a failing and passing identifier from the same function literal", func() {
+			Convey("both identifiers are kept, and the passing identifier is sniffed", func() {
+				So(len(unmerged.sniffTests), ShouldEqual, 2)
+				So(unmergedRes[ContentTypeKey].Field, ShouldEqual, "same")
+				So(unmergedRes, ShouldContainKey, "second")
+				So(unmergedRes, ShouldNotContainKey, "first")
+			})
+		})
+	})
+
+	alpha.Certainty = func([]byte) float64 { return 40 }
+	tests := []SniffTest{{DataID: *high, Sniffs: []Sniffer{first}}, {DataID: *mockPasser(false, demoDataKeyFail)}, {DataID: *alpha, Sniffs: []Sniffer{second}}}
+	res, matches := sniffData([]byte{}, tests, true)
+	_, noMatches := sniffData([]byte{}, tests, false)
+
+	Convey("Checking every content type that matches can be recorded", t, func() {
+		Convey("sniffing data with two identifiers that match and one that does not", func() {
+			Convey("both matches are recorded with their certainty, and only the first is sniffed", func() {
+				So(matches, ShouldResemble, []SniffResult{{Key: ContentTypeKey, Field: "high", Data: "high", Certainty: 100},
+					{Key: ContentTypeKey, Field: "alpha", Data: "alpha", Certainty: 40}})
+				So(res[ContentTypeKey].Field, ShouldEqual, "high")
+				So(res, ShouldContainKey, "first")
+				So(res, ShouldNotContainKey, "second")
+				So(noMatches, ShouldBeNil)
+			})
+		})
+	})
+
+	spec := *NewSpecification(WithAllContentTypes(),
+		WithSniffTest(SniffTest{DataID: *low, Sniffs: []Sniffer{first}}))
+	highSpec := *NewSpecification(WithSniffTest(SniffTest{DataID: *high, Sniffs: []Sniffer{second}}))
+	doc, docErr := os.Open("./testdata/demoReports/goodISXD.mxf")
+	ast, genErr := MakeAST(doc, make(chan *klv.KLV, 1000), 10, func() Specifications {
+		base, _ := generateSpecifications(spec, highSpec)
+		return base
+	}())
+	doc.Close()
+
+	Convey("Checking every content type of the essence is recorded when making an AST", t, func() {
+		Convey("generating an AST of goodISXD.mxf with two identifiers that match all the data", func() {
+			Convey("every essence node has both content types, with the high priority type sniffed", func() {
+				So(docErr, ShouldBeNil)
+				So(genErr, ShouldBeNil)
+
+				for _, part := range ast.Partitions {
+					for _, ess := range part.Essence {
+						So(ess.ContentTypes, ShouldResemble, []SniffResult{{Key: ContentTypeKey, Field: "high", Data: "high", Certainty: 100},
+							{Key: ContentTypeKey, Field: "low", Data: "low", Certainty: 100}})
+						So(ess.Sniffs[ContentTypeKey].Field, ShouldEqual, "high")
+						So(ess.Sniffs, ShouldContainKey, "second")
+						So(ess.Sniffs, ShouldNotContainKey, "first")
+					}
+				}
+			})
+		})
+	})
+}
@@ -0,0 +1,54 @@
+// Package layout contains the partition layout checks
+// that are shared by the specification packages.
+package layout
+
+import (
+	mxftest "github.com/metarex-media/mxf-test"
+)
+
+// GenericPositions returns the positions of the generic stream partitions,
+// along with the positions they are expected to be at, which are the
+// last partitions before the footer partition and the random index pack.
+func GenericPositions(mxf *mxftest.MXFNode) (positions, expected []int, err error) {
+	genericParts, err := mxf.Search("select * from partitions where type = " + mxftest.GenericStreamPartition)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	positions = make([]int, len(genericParts))
+	for i, gp := range genericParts {
+		positions[i] = gp.PartitionPos
+	}
+
+	endPos := len(mxf.Partitions)
+	for _, end := range []string{mxftest.FooterPartition, mxftest.RIPPartition} {
+		parts, err := mxf.Search("select * from partitions where type = " + mxftest.QuoteValue(end))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(parts) != 0 {
+			endPos--
+		}
+	}
+
+	expected = make([]int, len(positions))
+	for j := range expected {
+		expected[j] = endPos - len(expected) + j
+	}
+
+	return positions, expected, nil
+}
+
+// essenceQuery finds the partitions that contain essence
+var essenceQuery = mxftest.MustCompileQuery("select * from partitions where essence > 0 and not (type = " +
+	mxftest.GenericStreamPartition + " or type = " + mxftest.RIPPartition + ")")
+
+// EssencePartitions returns the partitions that contain
+// essence, the generic stream partitions are not included.
+func EssencePartitions(mxf *mxftest.MXFNode) []*mxftest.PartitionNode {
+	// the query is compiled, so no error is returned
+	parts, _ := essenceQuery.SearchMXF(mxf)
+
+	return parts
+}
@@ -0,0 +1,80 @@
+// Package spectest contains the helpers that are shared
+// by the tests of the specification packages.
+package spectest
+
+import (
+	"bytes"
+	"os"
+	"strings"
+
+	mxftest "github.com/metarex-media/mxf-test"
+	"gopkg.in/yaml.v3"
+)
+
+// IndexTable is an index table segment KLV, that only
+// contains an IndexSID of 2, for building test files.
+var IndexTable = []byte{0x06, 0x0e, 0x2b, 0x34, 0x02, 0x53, 0x01, 0x01, 0x0d, 0x01, 0x02, 0x01, 0x01, 0x10, 0x01, 0x00, 0x08,
+	0x3f, 0x06, 0x00, 0x04, 0x00, 0x00, 0x00, 0x02}
+
+// Run tests an MXF file against the specifications and returns the report.
+func Run(mxf []byte, specs mxftest.Specifications) (mxftest.Report, error) {
+	var buf bytes.Buffer
+	if err := mxftest.MRXTest(bytes.NewReader(mxf), &buf, specs); err != nil {
+		return mxftest.Report{}, err
+	}
+
+	var report mxftest.Report
+	err := yaml.Unmarshal(buf.Bytes(), &report)
+
+	return report, err
+}
+
+// RunFile is Run for the MXF file at path.
+func RunFile(path string, specs mxftest.Specifications) (mxftest.Report, error) {
+	mxf, err := os.ReadFile(path)
+	if err != nil {
+		return mxftest.Report{}, err
+	}
+
+	return Run(mxf, specs)
+}
+
+// FailedClauses returns the clauses of the failed tests in the report
+// from a specification document, e.g. "5.3,shall,3"
+func FailedClauses(report mxftest.Report, doc string) []string {
+	clauses := make([]string, 0)
+	for _, section := range report.Tests {
+		for _, test := range section.Tests {
+			if !strings.HasPrefix(test.Message, doc) {
+				continue
+			}
+
+			for _, check := range test.Checks {
+				if check.Pass {
+					continue
+				}
+
+				fields := strings.SplitN(test.Message, ",", 4)
+				clauses = append(clauses, strings.Join(fields[1:3], ",")+","+strings.Split(fields[3], ":")[0])
+				break
+			}
+		}
+	}
+
+	return clauses
+}
+
+// TestCount returns the number of tests in the report
+// from a specification document, e.g. ST378:2004
+func TestCount(report mxftest.Report, doc string) int {
+	count := 0
+	for _, section := range report.Tests {
+		for _, test := range section.Tests {
+			if strings.HasPrefix(test.Message, doc) {
+				count++
+			}
+		}
+	}
+
+	return count
+}
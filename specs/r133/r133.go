@@ -0,0 +1,324 @@
+/*
+package r133 contains the specifications of subtitles carried in MXF,
+as defined in EBU R 133.
+
+The subtitles are EBU-TT (TTML) documents carried in generic stream partitions,
+alongside any font and image ancillary resources. Each stream is described in the
+header metadata by a text based set, which is linked to a static track with a
+descriptive metadata segment.
+*/
+package r133
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	mxftest "github.com/metarex-media/mxf-test"
+	"github.com/metarex-media/mxf-test/specs/internal/layout"
+	"github.com/metarex-media/mxf-test/xmlhandle"
+	mxf2go "github.com/metarex-media/mxf-to-go"
+	. "github.com/onsi/gomega"
+)
+
+// R133Doc is the document the specs for
+// subtitles in MXF are found in and used for these tests.
+const R133Doc = "EBUR133:2012"
+
+const (
+	// TTMLNamespace is the namespace of the root element of the TTML documents
+	TTMLNamespace = "http://www.w3.org/ns/ttml"
+	// TTMLMIMEType is the MIME type of the TTML documents in the text based set
+	TTMLMIMEType = "application/ttml+xml"
+
+	// the sniff keys used for the xml essence
+	rootSniff      = "/*"
+	namespaceSniff = "namespace-uri(/*)"
+)
+
+// ResourceMIMETypes are the MIME types of the font and image
+// ancillary resources that can be carried alongside the subtitles.
+var ResourceMIMETypes = []string{
+	"application/x-font-opentype", "application/x-font-truetype", "font/otf", "font/ttf",
+	"image/png",
+}
+
+// Specifications returns the EBU R 133 specifications.
+// The specifications are only run on files with a text based set
+// describing TTML, so they can be included for every file.
+func Specifications(sc mxftest.SniffContext) mxftest.Specifications {
+	return *mxftest.NewSpecification(
+		mxftest.WithSniffTest(mxftest.SniffTest{DataID: xmlhandle.DataIdentifier, Sniffs: []mxftest.Sniffer{xmlhandle.PathSniffer(sc, rootSniff), xmlhandle.PathSniffer(sc, namespaceSniff)}}),
+		mxftest.WithStructureTag(subtitleTag),
+		mxftest.WithStructureTests(checkTimedText, checkPositions, checkResources, checkStreamLinkage, checkFrameworkLinkage, checkTextBasedSets),
+	)
+}
+
+// textBasedSet is the decoded text based set
+// of a generic stream
+type textBasedSet struct {
+	node     *mxftest.Node
+	mime     string
+	language string
+	streamID uint32
+	scheme   bool
+}
+
+// subtitleTag checks for a text based set that describes TTML
+func subtitleTag(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		_, sets, err := textBasedSets(doc, mxf)
+
+		ttml := 0
+		for _, set := range sets {
+			if set.mime == TTMLMIMEType {
+				ttml++
+			}
+		}
+
+		t.Test("Checking that the header metadata describes a TTML generic stream", mxftest.NewSpecificationDetails(R133Doc, "5.1", "shall", 1),
+			t.Expect(err).Shall(BeNil()),
+			t.Expect(ttml).ShallNot(Equal(0)),
+		)
+	}
+}
+
+func checkTimedText(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		_, sets, err := textBasedSets(doc, mxf)
+		t.Test("Checking that the text based sets can be decoded", mxftest.NewSpecificationDetails(R133Doc, "5.1", "shall", 1),
+			t.Expect(err).Shall(BeNil()),
+		)
+
+		streams := genericStreams(mxf)
+		for _, set := range sets {
+			if set.mime != TTMLMIMEType {
+				continue
+			}
+
+			nonTTML := 0
+			namespaces := make(map[string]bool)
+			for _, ess := range streams[set.streamID] {
+				content, ok := ess.Sniffs[mxftest.ContentTypeKey]
+				if !ok || content == nil || content.Field != string(xmlhandle.Content) {
+					nonTTML++
+					continue
+				}
+
+				if root, ok := ess.Sniffs[rootSniff]; !ok || root == nil || root.Field != "tt" {
+					nonTTML++
+				}
+
+				ns := ""
+				if snif, ok := ess.Sniffs[namespaceSniff]; ok && snif != nil {
+					ns = snif.Field
+				}
+				namespaces[ns] = true
+			}
+
+			t.Test(fmt.Sprintf("Checking the generic stream %v only contains TTML documents", set.streamID), mxftest.NewSpecificationDetails(R133Doc, "5.1", "shall", 1),
+				t.Expect(len(streams[set.streamID])).ShallNot(Equal(0), fmt.Sprintf("no documents found for generic stream %v", set.streamID)),
+				t.Expect(nonTTML).Shall(Equal(0), fmt.Sprintf("%v documents without a tt root element found", nonTTML)),
+			)
+
+			delete(namespaces, TTMLNamespace)
+			t.Test(fmt.Sprintf("Checking the TTML documents of generic stream %v use the TTML namespace of %s", set.streamID, TTMLNamespace), mxftest.NewSpecificationDetails(R133Doc, "5.1", "shall", 2),
+				t.Expect(namespaces).Shall(BeEmpty(), fmt.Sprintf("namespaces of %q found", slices.Sorted(maps.Keys(namespaces)))),
+			)
+		}
+	}
+}
+
+// checkPositions checks the generic stream partitions
+// are the last partitions before the footer.
+func checkPositions(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		positions, expected, err := layout.GenericPositions(mxf)
+
+		t.Test("Checking that the generic partition positions match the expected positions at the end of the file", mxftest.NewSpecificationDetails(R133Doc, "5.1", "shall", 3),
+			t.Expect(err).Shall(BeNil()),
+			t.Expect(positions).Shall(Equal(expected)),
+		)
+	}
+}
+
+// checkResources checks the ancillary resources
+// are fonts or images.
+func checkResources(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		_, sets, err := textBasedSets(doc, mxf)
+		t.Test("Checking that the text based sets can be decoded", mxftest.NewSpecificationDetails(R133Doc, "5.2", "shall", 1),
+			t.Expect(err).Shall(BeNil()),
+		)
+
+		streams := genericStreams(mxf)
+		for _, set := range sets {
+			if set.mime == TTMLMIMEType {
+				continue
+			}
+
+			t.Test(fmt.Sprintf("Checking the ancillary resource of generic stream %v is a font or image", set.streamID), mxftest.NewSpecificationDetails(R133Doc, "5.2", "shall", 1),
+				t.Expect(ResourceMIMETypes).Shall(ContainElement(set.mime), fmt.Sprintf("unsupported MIME type of %s", set.mime)),
+			)
+
+			t.Test(fmt.Sprintf("Checking the ancillary resource of generic stream %v contains data", set.streamID), mxftest.NewSpecificationDetails(R133Doc, "5.2", "shall", 2),
+				t.Expect(len(streams[set.streamID])).ShallNot(Equal(0), fmt.Sprintf("no data found for generic stream %v", set.streamID)),
+			)
+		}
+	}
+}
+
+// checkStreamLinkage checks every generic stream has a text
+// based set, and every text based set has a generic stream.
+func checkStreamLinkage(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		_, sets, err := textBasedSets(doc, mxf)
+		described := make(map[uint32]bool)
+		for _, set := range sets {
+			described[set.streamID] = true
+		}
+
+		streams := genericStreams(mxf)
+		for _, sid := range slices.Sorted(maps.Keys(streams)) {
+			t.Test(fmt.Sprintf("Checking the generic stream %v is described by a text based set", sid), mxftest.NewSpecificationDetails(R133Doc, "5.3", "shall", 1),
+				t.Expect(err).Shall(BeNil()),
+				t.Expect(described[sid]).Shall(BeTrue(), fmt.Sprintf("no text based set found for generic stream %v", sid)),
+			)
+		}
+
+		for _, set := range sets {
+			_, ok := streams[set.streamID]
+			t.Test(fmt.Sprintf("Checking the text based set for generic stream %v has a generic stream partition", set.streamID), mxftest.NewSpecificationDetails(R133Doc, "5.3", "shall", 2),
+				t.Expect(ok).Shall(BeTrue(), fmt.Sprintf("no generic stream partition found with the stream ID %v", set.streamID)),
+			)
+		}
+	}
+}
+
+// checkFrameworkLinkage checks the text based sets are
+// found through the static track of the header metadata.
+func checkFrameworkLinkage(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		part, sets, err := textBasedSets(doc, mxf)
+		if part == nil {
+			return
+		}
+
+		staticTracks, trackErr := part.Search("select * from metadata where UL = " + mxf2go.GStaticTrackUL[13:])
+		t.Test("Checking that a static track is present in the header metadata", mxftest.NewSpecificationDetails(R133Doc, "5.3", "shall", 3),
+			t.Expect(err).Shall(BeNil()),
+			t.Expect(trackErr).Shall(BeNil()),
+			t.Expect(len(staticTracks)).ShallNot(Equal(0), "no static tracks found"),
+		)
+
+		linked := make(map[*mxftest.Node]bool)
+		var linkErr error
+		for _, track := range staticTracks {
+			frameworks, err := track.Search("select * where UL = " + mxf2go.GTextBasedFrameworkUL[13:])
+			if err != nil {
+				linkErr = err
+				break
+			}
+
+			for _, framework := range frameworks {
+				for _, child := range framework.Children {
+					linked[child] = true
+				}
+			}
+		}
+
+		for _, set := range sets {
+			t.Test(fmt.Sprintf("Checking the text based set for generic stream %v is referenced by a text based framework of a static track", set.streamID), mxftest.NewSpecificationDetails(R133Doc, "5.3", "shall", 3),
+				t.Expect(linkErr).Shall(BeNil()),
+				t.Expect(linked[set.node]).Shall(BeTrue(), "text based set is not linked to a static track"),
+			)
+		}
+	}
+}
+
+// checkTextBasedSets checks the required properties
+// of the text based sets.
+func checkTextBasedSets(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		_, sets, err := textBasedSets(doc, mxf)
+		t.Test("Checking that the text based sets can be decoded", mxftest.NewSpecificationDetails(R133Doc, "5.3", "shall", 4),
+			t.Expect(err).Shall(BeNil()),
+		)
+
+		ids := make(map[uint32]int)
+		for _, set := range sets {
+			ids[set.streamID]++
+
+			t.Test(fmt.Sprintf("Checking the text based set for generic stream %v has the required properties", set.streamID), mxftest.NewSpecificationDetails(R133Doc, "5.3", "shall", 4),
+				t.Expect(set.streamID).ShallNot(Equal(uint32(0)), "no generic stream ID found"),
+				t.Expect(set.mime).ShallNot(BeEmpty(), "no MIME type found"),
+				t.Expect(set.language).ShallNot(BeEmpty(), "no language code found"),
+				t.Expect(set.scheme).Shall(BeTrue(), "no payload scheme ID found"),
+			)
+		}
+
+		for _, sid := range slices.Sorted(maps.Keys(ids)) {
+			count := ids[sid]
+			t.Test(fmt.Sprintf("Checking generic stream %v is described by a single text based set", sid), mxftest.NewSpecificationDetails(R133Doc, "5.3", "shall", 5),
+				t.Expect(count).Shall(Equal(1), fmt.Sprintf("%v text based sets found", count)),
+			)
+		}
+	}
+}
+
+// textBasedSets returns the decoded generic stream text based sets
+// of the latest header metadata in the file, and the partition they were found in.
+func textBasedSets(doc io.ReadSeeker, mxf *mxftest.MXFNode) (*mxftest.PartitionNode, []textBasedSet, error) {
+	var metadata *mxftest.PartitionNode
+	for _, part := range mxf.Partitions {
+		if len(part.HeaderMetadata) > 0 {
+			metadata = part
+		}
+	}
+
+	if metadata == nil {
+		return nil, nil, nil
+	}
+
+	nodes, err := metadata.Search("select * from metadata where UL = " + mxf2go.GGenericStreamTextBasedSetUL[13:])
+	if err != nil {
+		return metadata, nil, err
+	}
+
+	sets := make([]textBasedSet, 0, len(nodes))
+	for _, n := range nodes {
+		decoded, err := mxftest.DecodeGroupNode(doc, n, metadata.Props.Primer)
+		if err != nil {
+			return metadata, sets, err
+		}
+
+		set := textBasedSet{node: n}
+		if mime, ok := decoded["TextMIMEMediaType"].(string); ok {
+			// ignore any MIME parameters e.g. ;charset=utf-8
+			set.mime = strings.ToLower(strings.TrimSpace(strings.Split(mime, ";")[0]))
+		}
+		set.language, _ = decoded["RFC5646TextLanguageCode"].(string)
+		set.streamID, _ = decoded["GenericStreamID"].(uint32)
+		_, set.scheme = decoded["TextBasedMetadataPayloadSchemeID"]
+
+		sets = append(sets, set)
+	}
+
+	return metadata, sets, nil
+}
+
+// genericStreams returns the essence of the generic
+// stream partitions, mapped by their stream ID
+func genericStreams(mxf *mxftest.MXFNode) map[uint32][]*mxftest.Node {
+	streams := make(map[uint32][]*mxftest.Node)
+	for _, part := range mxf.Partitions {
+		if part.Props.PartitionType != mxftest.GenericStreamPartition {
+			continue
+		}
+		streams[part.Props.BodySID] = append(streams[part.Props.BodySID], part.Essence...)
+	}
+
+	return streams
+}
@@ -0,0 +1,59 @@
+package r133
+
+import (
+	"fmt"
+	"testing"
+
+	mxftest "github.com/metarex-media/mxf-test"
+	"github.com/metarex-media/mxf-test/specs/internal/spectest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestR133(t *testing.T) {
+
+	// the test files are the veryBadISXD demo file, with the generic streams
+	// replaced with an EBU-TT document, an OpenType font and a PNG image.
+	report, testErr := spectest.RunFile("./testdata/subtitles.mxf", Specifications(mxftest.NewSniffContext()))
+	Convey("Checking the R133 specifications pass for valid subtitle files", t, func() {
+		Convey("testing ./testdata/subtitles.mxf, which has EBU-TT subtitles with a font and image", func() {
+			Convey("The tests all pass", func() {
+				So(testErr, ShouldBeNil)
+				So(spectest.FailedClauses(report, R133Doc), ShouldBeEmpty)
+				So(report.TestPass, ShouldBeTrue)
+			})
+		})
+	})
+
+	inputs := []string{"./testdata/badNamespace.mxf", "./testdata/notTTML.mxf", "./testdata/unlinked.mxf", "./testdata/badResource.mxf"}
+	descs := []string{"an EBU-TT document without the TTML namespace", "an XHTML document instead of TTML",
+		"a text based set that does not match the generic stream ID", "an ancillary resource without a font or image MIME type"}
+	clauses := []string{"5.1,shall,2", "5.1,shall,1", "5.3,shall,1", "5.2,shall,1"}
+
+	for i, input := range inputs {
+		report, testErr := spectest.RunFile(input, Specifications(mxftest.NewSniffContext()))
+
+		Convey("Checking the R133 specifications fail for invalid subtitle files", t, func() {
+			Convey(fmt.Sprintf("testing %s, which has %s", input, descs[i]), func() {
+				Convey(fmt.Sprintf("The tests fail with clause %s", clauses[i]), func() {
+					So(testErr, ShouldBeNil)
+					So(report.TestPass, ShouldBeFalse)
+					So(spectest.FailedClauses(report, R133Doc), ShouldContain, clauses[i])
+				})
+			})
+		})
+	}
+
+	// files without TTML are skipped
+	for _, mxf := range []string{"../../testdata/demoReports/goodISXD.mxf", "../../testdata/demoReports/veryBadISXD.mxf"} {
+		report, testErr := spectest.RunFile(mxf, Specifications(mxftest.NewSniffContext()))
+
+		Convey("Checking the R133 specifications are only run on files with subtitles", t, func() {
+			Convey(fmt.Sprintf("testing %s, which has no TTML generic streams", mxf), func() {
+				Convey("No tests fail", func() {
+					So(testErr, ShouldBeNil)
+					So(spectest.FailedClauses(report, R133Doc), ShouldBeEmpty)
+				})
+			})
+		})
+	}
+}
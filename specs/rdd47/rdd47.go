@@ -0,0 +1,381 @@
+/*
+package rdd47 contains the specifications of ISXD, the Isochronous
+Stream of XML Documents, as defined in RDD 47.
+
+The tests are versioned against the edition of the document,
+each test message starts with [RDD47Doc] so the results can be traced
+back to the clause of the edition that was used.
+*/
+package rdd47
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	mxftest "github.com/metarex-media/mxf-test"
+	"github.com/metarex-media/mxf-test/specs/internal/layout"
+	"github.com/metarex-media/mxf-test/xmlhandle"
+	mxf2go "github.com/metarex-media/mxf-to-go"
+	. "github.com/onsi/gomega"
+)
+
+// Edition is the edition of RDD 47 the specifications are written against.
+const Edition = "2018"
+
+// RDD47Doc is the document the specs for
+// ISXD are found in and used for these tests.
+const RDD47Doc = "RDD47:" + Edition
+
+const (
+	// DataEssenceCoding is the ISXD data essence coding label
+	// of the ISXD descriptor.
+	DataEssenceCoding = "060e2b34.04010105.0e090606.00000000"
+	// ContainerLabel is the ISXD frame wrapped essence container label.
+	ContainerLabel = "060e2b34.04010105.0e090607.01010103"
+	// FrameWrappedKey is the frame wrapped ISXD data element key,
+	// bytes 14 and 16 are the element count and number.
+	FrameWrappedKey = "060e2b34.01020105.0e090502.017f017f"
+	// ClipWrappedKey is the clip wrapped ISXD data element key,
+	// bytes 14 and 16 are the element count and number.
+	ClipWrappedKey = "060e2b34.01020105.0e090502.017f027f"
+
+	// the sniff keys used for the xml essence
+	rootSniff      = "/*"
+	namespaceSniff = "namespace-uri(/*)"
+)
+
+// Specifications returns the ISXD specifications.
+// The specifications are only run on files with an ISXD descriptor,
+// so they can be included for every file.
+func Specifications(sc mxftest.SniffContext) mxftest.Specifications {
+	return *mxftest.NewSpecification(
+		mxftest.WithSniffTest(mxftest.SniffTest{DataID: xmlhandle.DataIdentifier, Sniffs: []mxftest.Sniffer{xmlhandle.PathSniffer(sc, rootSniff), xmlhandle.PathSniffer(sc, namespaceSniff)}}),
+		mxftest.WithNodeTags(mxftest.NodeTest{UL: mxf2go.GISXDUL[13:], Test: isxdTag}),
+		mxftest.WithNodeTests(mxftest.NodeTest{UL: mxf2go.GISXDUL[13:], Test: checkDescriptor}),
+		mxftest.WithPartitionTests(
+			mxftest.PartitionTest{PartitionType: mxftest.Header, Test: checkContainerLabel},
+			mxftest.PartitionTest{PartitionType: mxftest.Header, Test: checkStaticTrack},
+			mxftest.PartitionTest{PartitionType: mxftest.GenericBody, Test: checkGenericPartition},
+		),
+		mxftest.WithStructureTests(checkGenericPositions, checkWrapping, checkXMLContent, checkNamespaces),
+	)
+}
+
+// isxdTag checks for the ISXD descriptor, which is all
+// that is needed to identify an ISXD file
+func isxdTag(_ io.ReadSeeker, isxdDesc *mxftest.Node, _ map[string]string) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		t.Test("Checking that the ISXD descriptor is present in the header metadata", mxftest.NewSpecificationDetails(RDD47Doc, "9.2", "shall", 1),
+			t.Expect(isxdDesc).ShallNot(BeNil()),
+		)
+	}
+}
+
+// checkDescriptor checks the fields of the ISXD descriptor
+func checkDescriptor(doc io.ReadSeeker, isxdDesc *mxftest.Node, primer map[string]string) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		isxd, err := mxftest.DecodeGroupNodeAs[mxf2go.GISXDStruct](doc, isxdDesc, primer)
+		t.Test("Checking that the ISXD descriptor can be decoded with every required field", mxftest.NewSpecificationDetails(RDD47Doc, "9.3", "shall", 1),
+			t.Expect(err).Shall(BeNil()),
+		)
+
+		if err != nil {
+			return
+		}
+
+		coding, _ := mxf2go.EncodeTAUID(isxd.DataEssenceCoding)
+		t.Test("Checking that the data essence coding field of the ISXD descriptor is the ISXD label "+DataEssenceCoding, mxftest.NewSpecificationDetails(RDD47Doc, "9.3", "shall", 2),
+			t.Expect(mxftest.FullNameMask(coding)).Shall(Equal(DataEssenceCoding)),
+		)
+
+		t.Test("Checking that the sample rate field of the ISXD descriptor is a valid rate", mxftest.NewSpecificationDetails(RDD47Doc, "9.3", "shall", 3),
+			t.Expect(isxd.SampleRate.Denominator).ShallNot(Equal(int32(0)), "sample rate denominator is 0"),
+		)
+
+		t.Test("Checking that the namespace URI field of the ISXD descriptor is not empty", mxftest.NewSpecificationDetails(RDD47Doc, "9.3", "shall", 4),
+			t.Expect(isxd.NamespaceURIUTF8).ShallNot(BeEmpty(), "no namespace URI found"),
+		)
+	}
+}
+
+func checkContainerLabel(_ io.ReadSeeker, header *mxftest.PartitionNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		t.Test("Checking that the ISXD essence container label is declared in the partition pack", mxftest.NewSpecificationDetails(RDD47Doc, "7.2", "shall", 1),
+			t.Expect(header.Props.EssenceContainers).Shall(ContainElement(ContainerLabel), fmt.Sprintf("%v not found in the essence containers", ContainerLabel)),
+		)
+	}
+}
+
+var (
+	// staticTrackQuery finds the static tracks of the header metadata
+	staticTrackQuery = mxftest.MustCompileQuery("select * from metadata where UL = " + mxf2go.GStaticTrackUL[13:])
+	// sequenceQuery finds the sequences of a track
+	sequenceQuery = mxftest.MustCompileQuery("select * where UL = " + mxf2go.GSequenceUL[13:])
+)
+
+// checkStaticTrack checks the generic streams are described
+// in the header metadata by a single static track.
+func checkStaticTrack(_ io.ReadSeeker, header *mxftest.PartitionNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		genericParts, err := header.Parent.Search("select * from partitions where type = " + mxftest.GenericStreamPartition)
+		t.Test("Checking that the generic stream partitions can be found", mxftest.NewSpecificationDetails(RDD47Doc, "5.4", "shall", 1),
+			t.Expect(err).Shall(BeNil()),
+		)
+
+		// only run if there's any generic essence
+		if len(genericParts) == 0 {
+			return
+		}
+
+		staticTracks, err := staticTrackQuery.SearchPartition(header)
+		t.Test("Checking that a single static track is present in the header metadata", mxftest.NewSpecificationDetails(RDD47Doc, "5.4", "shall", 1),
+			t.Expect(err).Shall(BeNil()),
+			t.Expect(len(staticTracks)).Shall(Equal(1), fmt.Sprintf("%v static tracks found", len(staticTracks))),
+		)
+
+		if len(staticTracks) != 1 {
+			return
+		}
+
+		sequence, err := sequenceQuery.SearchNode(staticTracks[0])
+		t.Test("Checking that the static track points to a single sequence", mxftest.NewSpecificationDetails(RDD47Doc, "5.4", "shall", 2),
+			t.Expect(err).Shall(BeNil()),
+			t.Expect(len(sequence)).Shall(Equal(1), fmt.Sprintf("%v sequences found", len(sequence))),
+		)
+
+		if len(sequence) != 1 {
+			return
+		}
+
+		t.Test("Checking that the static track sequence has a component for each generic stream partition", mxftest.NewSpecificationDetails(RDD47Doc, "5.4", "shall", 2),
+			t.Expect(len(sequence[0].Children)).Shall(Equal(len(genericParts)), fmt.Sprintf("%v components found for %v generic stream partitions", len(sequence[0].Children), len(genericParts))),
+		)
+	}
+}
+
+func checkGenericPartition(doc io.ReadSeeker, part *mxftest.PartitionNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		packKLV, err := mxftest.NodeToKLV(doc, &mxftest.Node{Key: part.Key, Length: part.Length, Value: part.Value})
+		t.Test("Checking that the generic stream partition pack can be read", mxftest.NewSpecificationDetails(RDD47Doc, "7.5", "shall", 1),
+			t.Expect(err).Shall(BeNil()),
+		)
+
+		if err != nil {
+			return
+		}
+
+		pack := mxftest.PartitionExtract(packKLV)
+		t.Test("Checking that the generic stream partition has no header metadata or index table", mxftest.NewSpecificationDetails(RDD47Doc, "7.5", "shall", 1),
+			t.Expect(pack.HeaderByteCount).Shall(Equal(uint64(0)), "header metadata byte count not 0"),
+			t.Expect(pack.IndexByteCount).Shall(Equal(uint64(0)), "index byte count not 0"),
+			t.Expect(pack.IndexSID).Shall(Equal(uint32(0)), "index SID not 0"),
+		)
+
+		t.Test("Checking the partition key is the generic stream partition key of "+mxf2go.GGenericStreamPartitionUL[13:], mxftest.NewSpecificationDetails(RDD47Doc, "7.5", "shall", 2),
+			t.Expect(mxftest.FullNameMask(packKLV.Key, 5)).Shall(Equal(mxf2go.GGenericStreamPartitionUL[13:])),
+		)
+
+		invalidKeys := 0
+		var extractErr error
+		for _, e := range part.Essence {
+			ess, err := mxftest.NodeToKLV(doc, &mxftest.Node{Key: e.Key})
+			if err != nil {
+				extractErr = err
+				break
+			}
+
+			if !mxftest.IsGenericStreamKey(ess.Key) {
+				invalidKeys++
+			}
+		}
+
+		t.Test("Checking the essence keys of the generic stream partition are generic stream data element keys", mxftest.NewSpecificationDetails(RDD47Doc, "7.5", "shall", 3),
+			t.Expect(extractErr).Shall(BeNil()),
+			t.Expect(invalidKeys).Shall(Equal(0), fmt.Sprintf("%v other essence keys found", invalidKeys)),
+		)
+	}
+}
+
+// checkGenericPositions checks the generic stream partitions
+// are the last partitions before the footer.
+func checkGenericPositions(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		positions, expected, err := layout.GenericPositions(mxf)
+
+		t.Test("Checking that the generic stream partitions are at the end of the file", mxftest.NewSpecificationDetails(RDD47Doc, "5.4", "shall", 3),
+			t.Expect(err).Shall(BeNil()),
+			t.Expect(positions).Shall(Equal(expected)),
+		)
+	}
+}
+
+// checkWrapping checks the ISXD essence is either frame
+// or clip wrapped, then checks the layout of the wrapping.
+func checkWrapping(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		wrappings := make(map[string]bool)
+		clipCount := make(map[uint32]int)
+
+		for _, part := range layout.EssencePartitions(mxf) {
+			badKeys := 0
+			clipWrapped := false
+			breakPoint := -1
+			pattern := part.Props.EssenceOrder
+			var extractErr error
+
+			for i, e := range part.Essence {
+				ess, err := mxftest.NodeToKLV(doc, &mxftest.Node{Key: e.Key})
+				if err != nil {
+					extractErr = err
+					break
+				}
+
+				wrap := wrapping(ess.Key)
+				switch wrap {
+				case "":
+					badKeys++
+				case mxftest.ClipWrapped:
+					clipWrapped = true
+					clipCount[part.Props.BodySID]++
+				}
+				wrappings[wrap] = true
+
+				// every content package of frame wrapped
+				// essence follows the same order
+				if breakPoint == -1 && len(pattern) > 0 && mxftest.FullNameMask(ess.Key) != pattern[i%len(pattern)] {
+					breakPoint = e.Key.Start
+				}
+			}
+
+			t.Test(fmt.Sprintf("Checking that only ISXD essence keys are found in the %s partition at offset %v", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(RDD47Doc, "7.5", "shall", 4),
+				t.Expect(extractErr).Shall(BeNil()),
+				t.Expect(badKeys).Shall(Equal(0), fmt.Sprintf("%v other essence keys found", badKeys)),
+			)
+
+			if !clipWrapped {
+				t.Test(fmt.Sprintf("Checking that the content packages of the %s partition at offset %v are regular throughout the essence stream", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(RDD47Doc, "7.5", "shall", 5),
+					t.Expect(breakPoint).Shall(Equal(-1), fmt.Sprintf("irregular key found at byte offset %v", breakPoint)),
+				)
+			}
+		}
+
+		delete(wrappings, "")
+		t.Test("Checking that the ISXD essence is either frame wrapped or clip wrapped", mxftest.NewSpecificationDetails(RDD47Doc, "7.5", "shall", 6),
+			t.Expect(len(wrappings)).Shall(BeNumerically("<=", 1), "frame wrapped and clip wrapped essence found"),
+		)
+
+		for _, sid := range slices.Sorted(maps.Keys(clipCount)) {
+			count := clipCount[sid]
+			t.Test(fmt.Sprintf("Checking that the clip wrapped essence container with body SID %v has a single ISXD KLV", sid), mxftest.NewSpecificationDetails(RDD47Doc, "7.5", "shall", 7),
+				t.Expect(count).Shall(Equal(1), fmt.Sprintf("%v clip wrapped KLVs found", count)),
+			)
+		}
+	}
+}
+
+// checkXMLContent checks the ISXD essence
+// is XML with a single root element.
+func checkXMLContent(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		nonXMLCount := 0
+		var xmlSearchErr error
+		for _, part := range layout.EssencePartitions(mxf) {
+			nonXML, err := part.Search(fmt.Sprintf("select * from essence where sniff:%s <> %s", mxftest.ContentTypeKey, xmlhandle.Content))
+			if err != nil {
+				xmlSearchErr = err
+				break
+			}
+			nonXMLCount += len(nonXML)
+		}
+
+		t.Test("Checking only XML data is contained in the ISXD essence", mxftest.NewSpecificationDetails(RDD47Doc, "5.3", "shall", 1),
+			t.Expect(xmlSearchErr).Shall(BeNil()),
+			t.Expect(nonXMLCount).Shall(Equal(0), fmt.Sprintf("%v non XML entries found", nonXMLCount)),
+		)
+
+		roots, rootErr := sniffed(mxf, rootSniff)
+		t.Test("Checking every XML document has the same root element", mxftest.NewSpecificationDetails(RDD47Doc, "5.3", "shall", 2),
+			t.Expect(rootErr).Shall(BeNil()),
+			t.Expect(len(roots)).Shall(BeNumerically("<=", 1), fmt.Sprintf("%v XML roots of %v found, wanted 1", len(roots), roots)),
+		)
+	}
+}
+
+// checkNamespaces checks the namespace of every XML document matches
+// the namespace given in the ISXD descriptor.
+func checkNamespaces(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		headers, searchErr := mxf.Search("select * from partitions where metadata <> 0")
+		if len(headers) == 0 {
+			// no header metadata so there is no descriptor to compare against
+			return
+		}
+
+		// use the latest header metadata in the file
+		header := headers[len(headers)-1]
+		isxdDesc, isxdErr := header.Search("select * from metadata where UL = " + mxf2go.GISXDUL[13:])
+
+		t.Test("Checking that a single ISXD descriptor is present in the latest header metadata", mxftest.NewSpecificationDetails(RDD47Doc, "9.2", "shall", 2),
+			t.Expect(searchErr).Shall(BeNil()),
+			t.Expect(isxdErr).Shall(BeNil()),
+			t.Expect(len(isxdDesc)).Shall(Equal(1), fmt.Sprintf("%v ISXD descriptors found", len(isxdDesc))),
+		)
+
+		if len(isxdDesc) != 1 {
+			return
+		}
+
+		// a missing namespace is reported by the descriptor tests
+		isxdDecode, err := mxftest.DecodeGroupNodeAs[isxdNamespace](doc, isxdDesc[0], header.Props.Primer)
+		ns := isxdDecode.Namespace
+		if err != nil || ns == "" {
+			return
+		}
+
+		namespaces, nsErr := sniffed(mxf, namespaceSniff)
+		delete(namespaces, ns)
+		t.Test(fmt.Sprintf("Checking that the namespace URI field of %s matches the namespace of the XML documents across the file", ns), mxftest.NewSpecificationDetails(RDD47Doc, "5.3", "shall", 3),
+			t.Expect(nsErr).Shall(BeNil()),
+			t.Expect(namespaces).Shall(BeEmpty(), fmt.Sprintf("namespaces of %q do not match %s", slices.Sorted(maps.Keys(namespaces)), ns)),
+		)
+	}
+}
+
+// isxdNamespace is the namespace of the ISXD descriptor
+type isxdNamespace struct {
+	Namespace string `mxf:"NamespaceURIUTF8"`
+}
+
+// wrapping returns the wrapping of an ISXD data element key.
+// An empty string is returned if the key is not an ISXD key.
+func wrapping(key []byte) string {
+	switch mxftest.FullNameMask(key, 13, 15) {
+	case FrameWrappedKey:
+		return mxftest.FrameWrapped
+	case ClipWrappedKey:
+		return mxftest.ClipWrapped
+	default:
+		return ""
+	}
+}
+
+// sniffed returns the values of a sniff key found in the XML
+// essence of the file. XML without the sniffed value is
+// recorded as an empty string.
+func sniffed(mxf *mxftest.MXFNode, sniffKey string) (map[string]bool, error) {
+	found, err := mxf.Query(fmt.Sprintf("select distinct sniff:%s from essence where not (type = %s or type = %s) and sniff:%s = %s",
+		sniffKey, mxftest.GenericStreamPartition, mxftest.RIPPartition,
+		mxftest.ContentTypeKey, xmlhandle.Content))
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]bool)
+	for _, row := range found.Rows {
+		values[row[0]] = true
+	}
+
+	return values, nil
+}
@@ -0,0 +1,68 @@
+package rdd47
+
+import (
+	"fmt"
+	"testing"
+
+	mxftest "github.com/metarex-media/mxf-test"
+	"github.com/metarex-media/mxf-test/specs/internal/spectest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRDD47(t *testing.T) {
+
+	report, testErr := spectest.RunFile("./testdata/isxd.mxf", Specifications(mxftest.NewSniffContext()))
+	Convey("Checking the RDD 47 specifications pass for a valid ISXD file", t, func() {
+		Convey("testing ./testdata/isxd.mxf, which is frame wrapped ISXD", func() {
+			Convey("The tests all pass", func() {
+				So(testErr, ShouldBeNil)
+				So(report.TestPass, ShouldBeTrue)
+				So(spectest.FailedClauses(report, RDD47Doc), ShouldBeEmpty)
+			})
+		})
+	})
+
+	inputs := []string{"./testdata/badNamespace.mxf", "./testdata/badRoot.mxf", "./testdata/clipWrapped.mxf",
+		"./testdata/irregularKeys.mxf", "./testdata/badCoding.mxf"}
+	descs := []string{"an XML document with a different namespace to the descriptor", "an XML document with a different root element",
+		"clip wrapped essence split across several KLVs", "an irregular content package", "an incorrect data essence coding label"}
+	clauses := []string{"5.3,shall,3", "5.3,shall,2", "7.5,shall,7", "7.5,shall,5", "9.3,shall,2"}
+
+	for i, input := range inputs {
+		report, testErr := spectest.RunFile(input, Specifications(mxftest.NewSniffContext()))
+
+		Convey("Checking the RDD 47 specifications fail for invalid ISXD files", t, func() {
+			Convey(fmt.Sprintf("testing %s, which has %s", input, descs[i]), func() {
+				Convey(fmt.Sprintf("The tests fail with clause %s", clauses[i]), func() {
+					So(testErr, ShouldBeNil)
+					So(report.TestPass, ShouldBeFalse)
+					So(spectest.FailedClauses(report, RDD47Doc), ShouldContain, clauses[i])
+				})
+			})
+		})
+	}
+
+	for _, mxf := range []string{"../../testdata/demoReports/badISXD.mxf", "../../testdata/demoReports/veryBadISXD.mxf"} {
+		report, testErr := spectest.RunFile(mxf, Specifications(mxftest.NewSniffContext()))
+
+		Convey("Checking the RDD 47 specifications fail for the demo ISXD files", t, func() {
+			Convey(fmt.Sprintf("testing %s, which has non ISXD essence", mxf), func() {
+				Convey("The tests fail", func() {
+					So(testErr, ShouldBeNil)
+					So(report.TestPass, ShouldBeFalse)
+				})
+			})
+		})
+	}
+
+	report, testErr = spectest.RunFile("../../example/testdata/gpsdemo.mxf", Specifications(mxftest.NewSniffContext()))
+	Convey("Checking the RDD 47 specifications fail for ISXD files without XML", t, func() {
+		Convey("testing ../../example/testdata/gpsdemo.mxf, which has JSON essence", func() {
+			Convey("The tests fail with clause 5.3,shall,1", func() {
+				So(testErr, ShouldBeNil)
+				So(report.TestPass, ShouldBeFalse)
+				So(spectest.FailedClauses(report, RDD47Doc), ShouldContain, "5.3,shall,1")
+			})
+		})
+	})
+}
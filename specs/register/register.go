@@ -0,0 +1,167 @@
+/*
+package register contains the register conformance checks of the header metadata,
+using the group definitions of the SMPTE registers that are found in mxf2go.
+
+Every decoded metadata set is checked for its required properties,
+which are generated from the properties that are not optional in the mxf2go group definitions,
+that its property values decode to the type and length given in the register without being repeated,
+and that it only contains properties that are defined for that group.
+
+The tests are reported against the register, with the group name
+as the clause, so a missing property of the preface is reported as
+"ST395:2014,Preface,shall,1".
+*/
+package register
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/metarex-media/mrx-tool/klv"
+	mxftest "github.com/metarex-media/mxf-test"
+	mxf2go "github.com/metarex-media/mxf-to-go"
+	. "github.com/onsi/gomega"
+)
+
+// RegisterDoc is the register of groups
+// that the header metadata is checked against.
+const RegisterDoc = "ST395:2014"
+
+// Specifications returns the register conformance specifications,
+// which check every set of the header metadata against its group
+// definition in the register.
+func Specifications() mxftest.Specifications {
+	return *mxftest.NewSpecification(
+		mxftest.WithStructureTests(checkRegister),
+	)
+}
+
+// groupReport is the result of comparing a group against the register
+type groupReport struct {
+	// the register name of the group
+	name string
+	// required properties that were not found
+	missing []string
+	// properties that did not decode to their register type and length, or were repeated
+	invalid []string
+	// properties not defined for the group
+	undefined []string
+}
+
+// requiredProperties returns the properties of a group
+// that are not optional in its definition.
+func requiredProperties(group mxf2go.GroupID) []string {
+	required := make([]string, 0)
+	for _, prop := range group.Group {
+		if !prop.IsOpt {
+			required = append(required, prop.UL)
+		}
+	}
+	slices.Sort(required)
+
+	return required
+}
+
+// validateGroup compares a group KLV against the register definition
+// of the group, the ul is the UL of the group as given by the metadata Node.
+// False is returned if the group is not in the register.
+func validateGroup(ul string, group *klv.KLV, primer map[string]string) (groupReport, bool, error) {
+	definition, ok := mxftest.GroupLookUp(group.Key)
+	if !ok {
+		return groupReport{}, false, nil
+	}
+
+	report := groupReport{name: definition.Name, missing: make([]string, 0),
+		invalid: make([]string, 0), undefined: make([]string, 0)}
+
+	result, err := mxftest.DecodeGroupResult(group, primer)
+	present := make(map[string]bool)
+	for name := range result.Values {
+		present[name] = true
+	}
+
+	for _, issue := range result.Issues {
+		switch issue.Type {
+		case mxftest.UnknownProperty, mxftest.MissingPrimerTag:
+			report.undefined = append(report.undefined, issue.String())
+		default:
+			// the property is present, even if it is not valid
+			present[issue.Name] = true
+			report.invalid = append(report.invalid, issue.String())
+		}
+	}
+
+	for _, prop := range requiredProperties(definition) {
+		if !present[prop] {
+			report.missing = append(report.missing, prop)
+		}
+	}
+
+	return report, true, err
+}
+
+func checkRegister(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		for _, part := range mxf.Partitions {
+			for _, set := range metadataNodes(part) {
+				// the primer is not a local set
+				if set.Properties.UL() == mxf2go.GPrimerPackUL[13:] {
+					continue
+				}
+
+				setKLV, err := mxftest.NodeToKLV(doc, set)
+				if err != nil {
+					t.Test(fmt.Sprintf("Checking the metadata set at offset %v can be read", set.Key.Start), mxftest.NewSpecificationDetails(RegisterDoc, "Groups", "shall", 1),
+						t.Expect(err).Shall(BeNil()),
+					)
+					continue
+				}
+
+				report, ok, decErr := validateGroup(set.Properties.UL(), setKLV, part.Props.Primer)
+				if !ok {
+					continue
+				}
+
+				t.Test(fmt.Sprintf("Checking the %s at offset %v has the required properties", report.name, set.Key.Start), mxftest.NewSpecificationDetails(RegisterDoc, report.name, "shall", 1),
+					t.Expect(report.missing).Shall(BeEmpty(), fmt.Sprintf("missing properties %v", report.missing)),
+				)
+
+				t.Test(fmt.Sprintf("Checking the properties of the %s at offset %v decode to their register type and length", report.name, set.Key.Start), mxftest.NewSpecificationDetails(RegisterDoc, report.name, "shall", 2),
+					t.Expect(decErr).Shall(BeNil()),
+					t.Expect(report.invalid).Shall(BeEmpty(), fmt.Sprintf("invalid properties %v", strings.Join(report.invalid, ", "))),
+				)
+
+				t.Test(fmt.Sprintf("Checking the %s at offset %v only contains properties defined for the group", report.name, set.Key.Start), mxftest.NewSpecificationDetails(RegisterDoc, report.name, "shall", 3),
+					t.Expect(report.undefined).Shall(BeEmpty(), fmt.Sprintf("undefined properties %v", report.undefined)),
+				)
+			}
+		}
+	}
+}
+
+// metadataNodes returns every metadata set of a partition,
+// including the children of the sets.
+func metadataNodes(part *mxftest.PartitionNode) []*mxftest.Node {
+	seen := make(map[*mxftest.Node]bool)
+	out := make([]*mxftest.Node, 0)
+
+	var walk func(n *mxftest.Node)
+	walk = func(n *mxftest.Node) {
+		if n == nil || seen[n] {
+			return
+		}
+		seen[n] = true
+		out = append(out, n)
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+
+	for _, n := range part.HeaderMetadata {
+		walk(n)
+	}
+
+	return out
+}
@@ -0,0 +1,99 @@
+package register
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/metarex-media/mrx-tool/klv"
+	"github.com/metarex-media/mxf-test/specs/internal/spectest"
+	mxf2go "github.com/metarex-media/mxf-to-go"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRegister(t *testing.T) {
+	for _, mxf := range []string{"../../testdata/demoReports/goodISXD.mxf", "../../testdata/demoReports/veryBadISXD.mxf", "../../example/testdata/gpsdemo.mxf"} {
+		report, testErr := spectest.RunFile(mxf, Specifications())
+		// the unique clauses, as a clause fails for every partition
+		clauses := slices.Compact(slices.Sorted(slices.Values(spectest.FailedClauses(report, RegisterDoc))))
+
+		Convey("Checking the header metadata of the demo files against the register", t, func() {
+			Convey(fmt.Sprintf("testing %s", mxf), func() {
+				Convey("No register clauses fail", func() {
+					So(testErr, ShouldBeNil)
+					So(report.TestPass, ShouldBeTrue)
+					So(clauses, ShouldBeEmpty)
+				})
+			})
+		})
+	}
+}
+
+var isxdPrimer = map[string]string{
+	"3e01": "060e2b34.01010103.04030302.00000000", // DataEssenceCoding
+	"3001": "060e2b34.01010101.04060101.00000000", // SampleRate
+	"3004": "060e2b34.01010102.06010104.01020000", // ContainerFormat
+	"3c0a": "060e2b34.01010101.01011502.00000000", // InstanceID
+	"ffe0": "060e2b34.01010105.0e090400.00000000", // NamespaceURIUTF8
+	"3203": "060e2b34.01010101.04010502.02000000", // StoredWidth
+}
+
+// isxdGroup builds an ISXD descriptor from the local tags and their values
+func isxdGroup(props ...any) *klv.KLV {
+	var value []byte
+	for i := 0; i < len(props); i += 2 {
+		value = append(value, []byte(props[i].(string))...)
+		value = binary.BigEndian.AppendUint16(value, uint16(len(props[i+1].([]byte))))
+		value = append(value, props[i+1].([]byte)...)
+	}
+
+	return &klv.KLV{Key: []byte{0x06, 0x0e, 0x2b, 0x34, 0x02, 0x53, 0x01, 0x05, 0x0e, 0x09, 0x05, 0x02, 0x00, 0x00, 0x00, 0x00},
+		Length: []byte{0x83, 0, 0, byte(len(value))}, Value: value}
+}
+
+func TestValidateGroup(t *testing.T) {
+	label, uuid, rational := make([]byte, 16), make([]byte, 16), []byte{0, 0, 0, 25, 0, 0, 0, 1}
+	namespace := []byte("http://example.com/isxd")
+
+	groups := []*klv.KLV{
+		isxdGroup("\x3e\x01", label, "\x30\x01", rational, "\x30\x04", label, "\x3c\x0a", uuid, "\xff\xe0", namespace),
+		isxdGroup("\x3e\x01", label, "\x30\x01", rational, "\x30\x04", label, "\x3c\x0a", uuid),
+		isxdGroup("\x3e\x01", label, "\x30\x01", rational[:4], "\x30\x04", label, "\x3c\x0a", uuid, "\xff\xe0", namespace),
+		isxdGroup("\x3e\x01", label, "\x30\x01", rational, "\x30\x04", label, "\x3c\x0a", uuid, "\xff\xe0", namespace, "\x32\x03", []byte{0, 0, 0, 1}),
+		isxdGroup("\x3e\x01", label, "\x30\x01", rational, "\x30\x04", label, "\x3c\x0a", uuid, "\xff\xe0", namespace, "\x80\x01", []byte{1}),
+	}
+	descs := []string{"all the required properties", "no namespace URI", "a 4 byte sample rate",
+		"a stored width property", "a local tag that is not in the primer"}
+	expected := []groupReport{
+		{name: "ISXD", missing: []string{}, invalid: []string{}, undefined: []string{}},
+		{name: "ISXD", missing: []string{"NamespaceURIUTF8"}, invalid: []string{}, undefined: []string{}},
+		{name: "ISXD", missing: []string{}, invalid: []string{"SampleRate: length mismatch, 4 bytes instead of 8"}, undefined: []string{}},
+		{name: "ISXD", missing: []string{}, invalid: []string{}, undefined: []string{isxdPrimer["3203"] + ": unknown property, not defined for the ISXD group"}},
+		{name: "ISXD", missing: []string{}, invalid: []string{}, undefined: []string{"8001: tag missing from the primer"}},
+	}
+
+	for i, group := range groups {
+		report, ok, err := validateGroup(mxf2go.GISXDUL[13:], group, isxdPrimer)
+
+		Convey("Checking groups are validated against the register", t, func() {
+			Convey(fmt.Sprintf("validating an ISXD descriptor with %s", descs[i]), func() {
+				Convey(fmt.Sprintf("The report matches %v", expected[i]), func() {
+					So(err, ShouldBeNil)
+					So(ok, ShouldBeTrue)
+					So(report, ShouldResemble, expected[i])
+				})
+			})
+		})
+	}
+
+	_, ok, err := validateGroup("", &klv.KLV{Key: make([]byte, 16)}, isxdPrimer)
+	Convey("Checking groups are validated against the register", t, func() {
+		Convey("validating a group that is not in the register", func() {
+			Convey("The group is not validated", func() {
+				So(err, ShouldBeNil)
+				So(ok, ShouldBeFalse)
+			})
+		})
+	})
+}
@@ -0,0 +1,324 @@
+/*
+package st2067_5 contains the constraints of IMF track files,
+as defined in ST 2067-5.
+
+IMF track files are OP1a files with a single essence container,
+the specifications are only run on files with an OP1a operational
+pattern and an IMF essence container label.
+*/
+package st2067_5
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	mxftest "github.com/metarex-media/mxf-test"
+	"github.com/metarex-media/mxf-test/specs/internal/layout"
+	mxf2go "github.com/metarex-media/mxf-to-go"
+	. "github.com/onsi/gomega"
+)
+
+// ST2067_5Doc is the document the specs for
+// IMF track files are found in and used for these tests.
+const ST2067_5Doc = "ST2067-5:2013"
+
+// EssenceContainers are the essence container labels of IMF track files,
+// the version byte of the labels is masked with 7f and the final bytes,
+// which give the wrapping, are not included.
+var EssenceContainers = map[string]string{
+	"JPEG 2000 pictures":   "060e2b34.0401017f.0d010301.020c",
+	"broadcast wave audio": "060e2b34.0401017f.0d010301.0206",
+	"timed text":           "060e2b34.0401017f.0d010301.0213",
+	"ProRes pictures":      "060e2b34.0401017f.0d010301.021c",
+	"IAB audio":            "060e2b34.0401017f.0d010301.021d",
+	"ISXD data":            "060e2b34.0401017f.0e090607.0101",
+}
+
+// RequiredProperties are the properties that shall be present in
+// the essence descriptor of the track file, mapped by the descriptor UL.
+// Every descriptor must contain the properties of the "" key.
+var RequiredProperties = map[string][]string{
+	"":                               {"SampleRate", "ContainerFormat"},
+	mxf2go.GCDCIDescriptorUL[13:]:    {"StoredWidth", "StoredHeight", "FrameLayout", "ImageAspectRatio", "PictureCompression"},
+	mxf2go.GRGBADescriptorUL[13:]:    {"StoredWidth", "StoredHeight", "FrameLayout", "ImageAspectRatio", "PictureCompression"},
+	mxf2go.GWAVEPCMDescriptorUL[13:]: {"AudioSampleRate", "ChannelCount", "QuantizationBits", "BlockAlign", "AverageBytesPerSecond"},
+	mxf2go.GISXDUL[13:]:              {"DataEssenceCoding", "NamespaceURIUTF8"},
+}
+
+// DisallowedSets are the sets that shall not be found
+// in the header metadata of a track file.
+var DisallowedSets = map[string]string{
+	"multiple descriptor": mxf2go.GMultipleDescriptorUL[13:],
+	"essence group":       mxf2go.GEssenceGroupUL[13:],
+	"network locator":     mxf2go.GNetworkLocatorUL[13:],
+	"text locator":        mxf2go.GTextLocatorUL[13:],
+}
+
+// Specifications returns the IMF track file specifications.
+// The specifications are only run on IMF track files,
+// so they can be included for every file.
+func Specifications() mxftest.Specifications {
+	return *mxftest.NewSpecification(
+		mxftest.WithStructureTag(trackFileTag),
+		mxftest.WithStructureTests(checkEssenceContainer, checkHeaderPartition, checkIndexTables, checkBodyPartitions,
+			checkDescriptor, checkDisallowedSets),
+	)
+}
+
+// trackFileTag checks the file is OP1a with an IMF essence container
+func trackFileTag(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		headerParts, err := mxf.Search("select * from partitions where type = " + mxftest.HeaderPartition)
+		opName := ""
+		imfContainer := false
+		if len(headerParts) == 1 {
+			opName = headerParts[0].Props.OperationalPatternName
+			for _, ec := range headerParts[0].Props.EssenceContainers {
+				if imfEssenceContainer(ec) {
+					imfContainer = true
+				}
+			}
+		}
+
+		t.Test("Checking that the operational pattern is OP1a, with an IMF essence container", mxftest.NewSpecificationDetails(ST2067_5Doc, "5.1", "shall", 1),
+			t.Expect(err).Shall(BeNil()),
+			t.Expect(strings.HasPrefix(opName, "MXFOP1a")).Shall(BeTrue()),
+			t.Expect(imfContainer).Shall(BeTrue()),
+		)
+	}
+}
+
+func checkEssenceContainer(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		bodySIDs := make(map[uint32]bool)
+		for _, part := range layout.EssencePartitions(mxf) {
+			bodySIDs[part.Props.BodySID] = true
+		}
+
+		t.Test("Checking that the track file contains a single essence container", mxftest.NewSpecificationDetails(ST2067_5Doc, "5.2", "shall", 1),
+			t.Expect(len(bodySIDs)).Shall(Equal(1), fmt.Sprintf("%v essence containers found", len(bodySIDs))),
+		)
+
+		for _, part := range mxf.Partitions {
+			if part.Props.PartitionType == mxftest.RIPPartition {
+				continue
+			}
+
+			t.Test(fmt.Sprintf("Checking the %s partition at offset %v declares a single essence container label", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST2067_5Doc, "5.2", "shall", 2),
+				t.Expect(len(part.Props.EssenceContainers)).Shall(Equal(1), fmt.Sprintf("%v essence container labels found", len(part.Props.EssenceContainers))),
+			)
+		}
+	}
+}
+
+func checkHeaderPartition(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		headerParts, err := mxf.Search("select * from partitions where type = " + mxftest.HeaderPartition)
+		t.Test("Checking that the header partition can be found", mxftest.NewSpecificationDetails(ST2067_5Doc, "5.3", "shall", 1),
+			t.Expect(err).Shall(BeNil()),
+			t.Expect(len(headerParts)).Shall(Equal(1), fmt.Sprintf("%v header partitions found", len(headerParts))),
+		)
+
+		if len(headerParts) != 1 {
+			return
+		}
+
+		t.Test("Checking that the header partition is closed and complete", mxftest.NewSpecificationDetails(ST2067_5Doc, "5.3", "shall", 1),
+			t.Expect(headerParts[0].Props.Status).Shall(Equal(mxftest.ClosedComplete)),
+		)
+	}
+}
+
+func checkIndexTables(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		indexCount := 0
+		for _, part := range mxf.Partitions {
+			if part.IndexTable == nil {
+				continue
+			}
+			indexCount++
+
+			t.Test(fmt.Sprintf("Checking the index table of the %s partition at offset %v is in its own partition", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST2067_5Doc, "5.4", "shall", 1),
+				t.Expect(len(part.Essence)).Shall(Equal(0), fmt.Sprintf("%v essence KLVs found in the partition", len(part.Essence))),
+				t.Expect(len(part.HeaderMetadata)).Shall(Equal(0), "header metadata found in the partition"),
+			)
+		}
+
+		t.Test("Checking that the track file contains an index table", mxftest.NewSpecificationDetails(ST2067_5Doc, "5.4", "shall", 2),
+			t.Expect(indexCount).ShallNot(Equal(0), "no index tables found"),
+		)
+	}
+}
+
+// checkBodyPartitions checks every body partition contains the same
+// number of edit units, the final partition may be shorter.
+func checkBodyPartitions(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		parts := layout.EssencePartitions(mxf)
+		if len(parts) < 2 {
+			return
+		}
+
+		duration := editUnits(parts[0])
+		for i, part := range parts {
+			units := editUnits(part)
+			if i == len(parts)-1 {
+				t.Test(fmt.Sprintf("Checking the final %s partition at offset %v does not exceed the partition duration of %v edit units", part.Props.PartitionType, part.Key.Start, duration), mxftest.NewSpecificationDetails(ST2067_5Doc, "5.3", "shall", 2),
+					t.Expect(units).Shall(BeNumerically("<=", duration)),
+				)
+				continue
+			}
+
+			t.Test(fmt.Sprintf("Checking the %s partition at offset %v has the partition duration of %v edit units", part.Props.PartitionType, part.Key.Start, duration), mxftest.NewSpecificationDetails(ST2067_5Doc, "5.3", "shall", 2),
+				t.Expect(units).Shall(Equal(duration)),
+			)
+		}
+	}
+}
+
+func checkDescriptor(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		parts, err := mxf.Search("select * from partitions where metadata <> 0")
+		t.Test("Checking that the partitions with header metadata can be found", mxftest.NewSpecificationDetails(ST2067_5Doc, "5.5", "shall", 1),
+			t.Expect(err).Shall(BeNil()),
+		)
+
+		for _, part := range parts {
+			sourcePackages, err := part.Search("select * from metadata where UL = " + mxf2go.GSourcePackageUL[13:])
+
+			// the essence descriptor is the source package child
+			// that describes the essence container
+			var descriptor *mxftest.Node
+			var decoded map[string]any
+			var decodeErr error
+			for _, sp := range sourcePackages {
+				for _, child := range sp.Children {
+					if child == nil {
+						continue
+					}
+
+					childDecode, err := mxftest.DecodeGroupNode(doc, child, part.Props.Primer)
+					if err != nil {
+						decodeErr = err
+						continue
+					}
+
+					if _, ok := childDecode["ContainerFormat"]; ok {
+						descriptor, decoded = child, childDecode
+					}
+				}
+			}
+
+			t.Test(fmt.Sprintf("Checking the header metadata of the %s partition at offset %v has an essence descriptor", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST2067_5Doc, "5.5", "shall", 1),
+				t.Expect(err).Shall(BeNil()),
+				t.Expect(decodeErr).Shall(BeNil()),
+				t.Expect(descriptor).ShallNot(BeNil(), "no essence descriptor found"),
+			)
+
+			if descriptor == nil {
+				continue
+			}
+
+			missing := make([]string, 0)
+			required := slices.Concat(RequiredProperties[""], RequiredProperties[descriptor.Properties.UL()])
+			for _, prop := range required {
+				if _, ok := decoded[prop]; !ok {
+					missing = append(missing, prop)
+				}
+			}
+
+			t.Test(fmt.Sprintf("Checking the essence descriptor of the %s partition at offset %v has the required properties", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST2067_5Doc, "5.5", "shall", 1),
+				t.Expect(missing).Shall(BeEmpty(), fmt.Sprintf("missing properties %v", missing)),
+			)
+
+			_, duration := decoded["EssenceLength"]
+			t.Test(fmt.Sprintf("Checking the essence descriptor of the %s partition at offset %v has a container duration", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST2067_5Doc, "5.5", "should", 1),
+				t.Expect(duration).Should(BeTrue(), "no container duration found"),
+			)
+
+			if descriptor.Properties.UL() != mxf2go.GWAVEPCMDescriptorUL[13:] {
+				continue
+			}
+
+			// audio is labelled with multichannel audio sub descriptors
+			channels, soundfields := 0, 0
+			for _, sub := range descriptor.Children {
+				if sub == nil {
+					continue
+				}
+
+				switch sub.Properties.UL() {
+				case mxf2go.GAudioChannelLabelSubDescriptorUL[13:]:
+					channels++
+				case mxf2go.GSoundfieldGroupLabelSubDescriptorUL[13:]:
+					soundfields++
+				}
+			}
+
+			t.Test(fmt.Sprintf("Checking the audio descriptor of the %s partition at offset %v has MCA sub descriptors", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST2067_5Doc, "5.5", "shall", 2),
+				t.Expect(channels).ShallNot(Equal(0), "no audio channel label sub descriptors found"),
+				t.Expect(soundfields).ShallNot(Equal(0), "no soundfield group label sub descriptors found"),
+			)
+		}
+	}
+}
+
+func checkDisallowedSets(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		parts, err := mxf.Search("select * from partitions where metadata <> 0")
+		t.Test("Checking that the partitions with header metadata can be found", mxftest.NewSpecificationDetails(ST2067_5Doc, "5.5", "shall", 3),
+			t.Expect(err).Shall(BeNil()),
+		)
+
+		for _, part := range parts {
+			found := make([]string, 0)
+			var searchErr error
+			for _, name := range slices.Sorted(maps.Keys(DisallowedSets)) {
+				sets, err := part.Search("select * from metadata where UL = " + DisallowedSets[name])
+				if err != nil {
+					searchErr = err
+					break
+				}
+
+				if len(sets) != 0 {
+					found = append(found, name)
+				}
+			}
+
+			t.Test(fmt.Sprintf("Checking the header metadata of the %s partition at offset %v has no disallowed sets", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST2067_5Doc, "5.5", "shall", 3),
+				t.Expect(searchErr).Shall(BeNil()),
+				t.Expect(found).Shall(BeEmpty(), fmt.Sprintf("disallowed sets of %v found", found)),
+			)
+		}
+	}
+}
+
+// imfEssenceContainer checks if an essence container label
+// is one of the IMF essence containers
+func imfEssenceContainer(label string) bool {
+	if len(label) != 35 {
+		return false
+	}
+
+	// mask the version byte
+	masked := label[:15] + "7f" + label[17:]
+	for _, ec := range EssenceContainers {
+		if strings.HasPrefix(masked, ec) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// editUnits returns the number of content packages in a partition
+func editUnits(part *mxftest.PartitionNode) int {
+	if len(part.Props.EssenceOrder) == 0 {
+		return 0
+	}
+
+	return len(part.Essence) / len(part.Props.EssenceOrder)
+}
@@ -0,0 +1,131 @@
+package st2067_5
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/metarex-media/mrx-tool/klv"
+	mxftest "github.com/metarex-media/mxf-test"
+	"github.com/metarex-media/mxf-test/specs/internal/spectest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// runIMF returns the report and the clauses of the failed shall tests
+func runIMF(mxf []byte) (mxftest.Report, []string, error) {
+	report, err := spectest.Run(mxf, Specifications())
+	failed := slices.DeleteFunc(spectest.FailedClauses(report, ST2067_5Doc), func(clause string) bool {
+		return !strings.Contains(clause, ",shall,")
+	})
+
+	return report, failed, err
+}
+
+// trackFile builds a track file from the goodISXD demo file, with the essence split
+// into body partitions of the given sizes and an index table in its own partition.
+func trackFile(good []byte, ast *mxftest.MXFNode, splits ...int) ([]byte, error) {
+	body, footer := ast.Partitions[1], ast.Partitions[2]
+	bodyPack := good[body.Key.Start:body.Value.End]
+
+	var track []byte
+	track = append(track, good[:body.Key.Start]...)
+
+	essence := body.Essence
+	bodyOffset := uint64(0)
+	for _, split := range splits {
+		pack := bytes.Clone(bodyPack)
+		binary.BigEndian.PutUint64(pack[body.Value.Start-body.Key.Start+52:], bodyOffset)
+		track = append(track, pack...)
+
+		for _, e := range essence[:split] {
+			track = append(track, good[e.Key.Start:e.Value.End]...)
+			bodyOffset += uint64(e.Value.End - e.Key.Start)
+		}
+		essence = essence[split:]
+	}
+
+	// an index partition with no essence
+	indexPack := bytes.Clone(bodyPack)
+	indexValue := body.Value.Start - body.Key.Start
+	binary.BigEndian.PutUint64(indexPack[indexValue+40:], uint64(len(spectest.IndexTable)))
+	binary.BigEndian.PutUint32(indexPack[indexValue+48:], 2)
+	binary.BigEndian.PutUint64(indexPack[indexValue+52:], 0)
+	binary.BigEndian.PutUint32(indexPack[indexValue+60:], 0)
+	track = append(track, indexPack...)
+	track = append(track, spectest.IndexTable...)
+
+	track = append(track, good[footer.Key.Start:ast.Partitions[3].Key.Start]...)
+
+	// fix the partition pointers and status, then write the RIP
+	var repaired bytes.Buffer
+	_, err := mxftest.Repair(bytes.NewReader(track), &repaired)
+
+	return repaired.Bytes(), err
+}
+
+func TestTrackFile(t *testing.T) {
+
+	good, readErr := os.ReadFile("../../testdata/demoReports/goodISXD.mxf")
+	ast, astErr := mxftest.MakeAST(bytes.NewReader(good), make(chan *klv.KLV, 1000), 10, *mxftest.NewSpecification())
+
+	single, singleErr := trackFile(good, ast, 24)
+	even, evenErr := trackFile(good, ast, 12, 12)
+	short, shortErr := trackFile(good, ast, 12, 8, 4)
+	uneven, unevenErr := trackFile(good, ast, 10, 14)
+
+	inputs := [][]byte{single, even, short, uneven, good}
+	buildErrs := []error{singleErr, evenErr, shortErr, unevenErr, nil}
+	descs := []string{"a single body partition", "two body partitions of 12 edit units", "body partitions of 12, 8 and 4 edit units",
+		"body partitions of 10 and 14 edit units", "an open header partition and no index table"}
+	expectedFails := [][]string{{}, {}, {"5.3,shall,2"}, {"5.3,shall,2"}, {"5.3,shall,1", "5.4,shall,2"}}
+
+	for i, input := range inputs {
+		_, failed, testErr := runIMF(input)
+
+		Convey("Checking the IMF track file specifications for ISXD track files", t, func() {
+			Convey(fmt.Sprintf("testing a track file with %s", descs[i]), func() {
+				Convey(fmt.Sprintf("The tests fail with the clauses %v", expectedFails[i]), func() {
+					So(readErr, ShouldBeNil)
+					So(astErr, ShouldBeNil)
+					So(buildErrs[i], ShouldBeNil)
+					So(testErr, ShouldBeNil)
+					So(failed, ShouldResemble, expectedFails[i])
+				})
+			})
+		})
+	}
+
+	// files with other essence containers are not tested
+	notIMF := bytes.Clone(good)
+	copy(notIMF[ast.Partitions[0].Value.Start+88:], []byte{0x06, 0x0e, 0x2b, 0x34, 0x04, 0x01, 0x01, 0x03, 0x0d, 0x01, 0x03, 0x01, 0x02, 0x7f, 0x01, 0x00})
+	report, failed, testErr := runIMF(notIMF)
+	Convey("Checking the IMF track file specifications only run on IMF track files", t, func() {
+		Convey("testing a file without an IMF essence container in the header partition", func() {
+			Convey("No IMF tests fail", func() {
+				So(testErr, ShouldBeNil)
+				So(failed, ShouldBeEmpty)
+				So(report.TestPass, ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestIMFEssenceContainer(t *testing.T) {
+	labels := []string{"060e2b34.04010105.0e090607.01010103", "060e2b34.0401010d.0d010301.020c0100",
+		"060e2b34.04010101.0d010301.02060100", "060e2b34.04010103.0d010301.027f0100", "not a label"}
+	expected := []bool{true, true, true, false, false}
+
+	for i, label := range labels {
+		Convey("Checking IMF essence container labels are identified", t, func() {
+			Convey(fmt.Sprintf("checking the label %s", label), func() {
+				Convey(fmt.Sprintf("The label is an IMF essence container: %v", expected[i]), func() {
+					So(imfEssenceContainer(label), ShouldEqual, expected[i])
+				})
+			})
+		})
+	}
+}
@@ -0,0 +1,390 @@
+// package st377 contains the baseline MXF file structure
+// specifications of ST 377-1, that every MXF file is expected to pass.
+package st377
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	mxftest "github.com/metarex-media/mxf-test"
+	mxf2go "github.com/metarex-media/mxf-to-go"
+	. "github.com/onsi/gomega"
+)
+
+// ST377Doc is the document the specs for
+// the MXF file format are found in and used for these tests.
+const ST377Doc = "ST377-1:2019"
+
+// Specifications returns the baseline ST 377-1 specifications,
+// which check the partition layout, partition packs, header metadata
+// and random index pack of an MXF file.
+func Specifications() mxftest.Specifications {
+	return *mxftest.NewSpecification(
+		mxftest.WithStructureTests(checkPartitionPacks, checkPartitionPointers, checkFooter, checkHeaderFooterMetadata,
+			checkPartitionStatus, checkKAG, checkMetadataSets, checkRIP),
+	)
+}
+
+// partitionPacks returns the partitions of the mxf file, with their decoded partition packs.
+// The RIP is not included.
+func partitionPacks(doc io.ReadSeeker, mxf *mxftest.MXFNode) ([]*mxftest.PartitionNode, []mxftest.Partition, error) {
+	parts := make([]*mxftest.PartitionNode, 0)
+	packs := make([]mxftest.Partition, 0)
+
+	for _, part := range mxf.Partitions {
+		if part.Props.PartitionType == mxftest.RIPPartition {
+			continue
+		}
+
+		packKLV, err := mxftest.NodeToKLV(doc, &mxftest.Node{Key: part.Key, Length: part.Length, Value: part.Value})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		parts = append(parts, part)
+		packs = append(packs, mxftest.PartitionExtract(packKLV))
+	}
+
+	return parts, packs, nil
+}
+
+// fileStart is the byte offset of the header partition, any
+// run in before the header is not included in the partition offsets.
+func fileStart(mxf *mxftest.MXFNode) int {
+	if len(mxf.Partitions) == 0 {
+		return 0
+	}
+
+	return mxf.Partitions[0].Key.Start
+}
+
+// checkPartitionPacks checks the partition packs can be read, which the
+// other partition pack tests skip if they can not be read.
+func checkPartitionPacks(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		parts, _, err := partitionPacks(doc, mxf)
+
+		t.Test("Checking that the partition packs can be read", mxftest.NewSpecificationDetails(ST377Doc, "7.1", "shall", 1),
+			t.Expect(err).Shall(BeNil()),
+			t.Expect(len(parts)).ShallNot(Equal(0), "no partitions found"),
+		)
+	}
+}
+
+func checkPartitionPointers(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		parts, packs, err := partitionPacks(doc, mxf)
+		if err != nil {
+			return
+		}
+
+		base := fileStart(mxf)
+		var previous uint64
+		for i, part := range parts {
+			this := uint64(part.Key.Start - base)
+
+			t.Test(fmt.Sprintf("Checking the ThisPartition value of the %s partition at offset %v is the byte offset of the partition", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST377Doc, "7.1", "shall", 1),
+				t.Expect(packs[i].ThisPartition).Shall(Equal(this), fmt.Sprintf("ThisPartition of %v does not match the byte offset of %v", packs[i].ThisPartition, this)),
+			)
+
+			t.Test(fmt.Sprintf("Checking the PreviousPartition value of the %s partition at offset %v is the byte offset of the previous partition", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST377Doc, "7.1", "shall", 2),
+				t.Expect(packs[i].PreviousPartition).Shall(Equal(previous), fmt.Sprintf("PreviousPartition of %v does not match the byte offset of %v", packs[i].PreviousPartition, previous)),
+			)
+
+			previous = this
+		}
+	}
+}
+
+func checkFooter(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		parts, packs, err := partitionPacks(doc, mxf)
+		footerParts, footErr := mxf.Search("select * from partitions where type = " + mxftest.FooterPartition)
+
+		t.Test("Checking that a single footer partition is present", mxftest.NewSpecificationDetails(ST377Doc, "6.1", "should", 1),
+			t.Expect(footErr).Shall(BeNil()),
+			t.Expect(len(footerParts)).Should(Equal(1), fmt.Sprintf("%v footer partitions found", len(footerParts))),
+		)
+
+		// the partitions are needed to check the footer position
+		if err != nil || len(footerParts) != 1 {
+			return
+		}
+
+		footerOffset := uint64(footerParts[0].Key.Start - fileStart(mxf))
+
+		t.Test("Checking that the footer partition is the last partition in the file", mxftest.NewSpecificationDetails(ST377Doc, "6.1", "shall", 1),
+			t.Expect(parts[len(parts)-1]).Shall(BeIdenticalTo(footerParts[0])),
+		)
+
+		for i, part := range parts {
+			// the footer partition may be unknown when the partition is written
+			if part != footerParts[0] && packs[i].FooterPartition == 0 {
+				continue
+			}
+
+			t.Test(fmt.Sprintf("Checking the FooterPartition value of the %s partition at offset %v is the byte offset of the footer partition", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST377Doc, "7.1", "shall", 3),
+				t.Expect(packs[i].FooterPartition).Shall(Equal(footerOffset), fmt.Sprintf("FooterPartition of %v does not match the byte offset of %v", packs[i].FooterPartition, footerOffset)),
+			)
+		}
+	}
+}
+
+func checkHeaderFooterMetadata(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		headerParts, headErr := mxf.Search("select * from partitions where type = " + mxftest.HeaderPartition)
+		footerParts, footErr := mxf.Search("select * from partitions where type = " + mxftest.FooterPartition)
+
+		t.Test("Checking that a single header partition is present", mxftest.NewSpecificationDetails(ST377Doc, "6.1", "shall", 1),
+			t.Expect(headErr).Shall(BeNil()),
+			t.Expect(footErr).Shall(BeNil()),
+			t.Expect(len(headerParts)).Shall(Equal(1), fmt.Sprintf("%v header partitions found", len(headerParts))),
+		)
+
+		if len(headerParts) != 1 {
+			return
+		}
+		header := headerParts[0]
+
+		switch header.Props.Status {
+		case mxftest.OpenIncomplete, mxftest.OpenComplete:
+			// the final metadata has to be found in a closed partition
+			closedMetadata := 0
+			for _, part := range mxf.Partitions {
+				if len(part.HeaderMetadata) > 0 && (part.Props.Status == mxftest.ClosedComplete || part.Props.Status == mxftest.ClosedIncomplete) {
+					closedMetadata++
+				}
+			}
+
+			t.Test("Checking that a closed partition contains header metadata when the header partition is open", mxftest.NewSpecificationDetails(ST377Doc, "7.2", "should", 1),
+				t.Expect(closedMetadata).ShallNot(Equal(0), "no closed partitions with header metadata found"),
+			)
+		default:
+			if len(footerParts) == 1 && len(footerParts[0].HeaderMetadata) > 0 {
+				t.Test("Checking that the header metadata of the closed header partition matches the footer partition header metadata", mxftest.NewSpecificationDetails(ST377Doc, "7.2", "should", 2),
+					t.Expect(metadataSets(footerParts[0])).Should(Equal(metadataSets(header))),
+				)
+			}
+		}
+	}
+}
+
+func checkPartitionStatus(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		parts, _, err := partitionPacks(doc, mxf)
+		if err != nil || len(parts) == 0 {
+			return
+		}
+
+		t.Test("Checking that the first partition is the header partition", mxftest.NewSpecificationDetails(ST377Doc, "6.1", "shall", 1),
+			t.Expect(parts[0].Props.PartitionType).Shall(Equal(mxftest.HeaderPartition)),
+		)
+
+		complete := false
+		for _, part := range parts {
+			if part.Props.PartitionType == mxftest.GenericStreamPartition {
+				continue
+			}
+
+			t.Test(fmt.Sprintf("Checking the %s partition at offset %v has a valid partition status", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST377Doc, "7.2", "shall", 1),
+				t.Expect(part.Props.Status).Shall(BeElementOf(mxftest.OpenIncomplete, mxftest.ClosedIncomplete, mxftest.OpenComplete, mxftest.ClosedComplete)),
+			)
+
+			// once complete metadata has been written
+			// later metadata can not be incomplete
+			if len(part.HeaderMetadata) > 0 {
+				isComplete := part.Props.Status == mxftest.ClosedComplete || part.Props.Status == mxftest.OpenComplete
+				if complete {
+					t.Test(fmt.Sprintf("Checking the %s partition at offset %v is complete, as it follows complete header metadata", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST377Doc, "7.2", "shall", 2),
+						t.Expect(isComplete).Shall(BeTrue(), fmt.Sprintf("partition status of %s", part.Props.Status)),
+					)
+				}
+				complete = complete || isComplete
+			}
+
+			if part.Props.PartitionType == mxftest.FooterPartition {
+				t.Test("Checking that the footer partition is closed", mxftest.NewSpecificationDetails(ST377Doc, "7.2", "shall", 3),
+					t.Expect(part.Props.Status).Shall(BeElementOf(mxftest.ClosedIncomplete, mxftest.ClosedComplete)),
+				)
+			}
+		}
+	}
+}
+
+func checkKAG(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		parts, packs, err := partitionPacks(doc, mxf)
+		if err != nil {
+			return
+		}
+
+		base := fileStart(mxf)
+		for i, part := range parts {
+			kag := int(packs[i].SizeKAG)
+			// a KAG of 1 is no alignment
+			if kag <= 1 {
+				continue
+			}
+
+			// the first KLV of the header metadata, index table and essence
+			// is expected to be on the grid
+			aligned := make([]*mxftest.Node, 0)
+			if len(part.HeaderMetadata) > 0 {
+				aligned = append(aligned, part.HeaderMetadata[0])
+			}
+			if part.IndexTable != nil {
+				aligned = append(aligned, part.IndexTable)
+			}
+			if len(part.Essence) > 0 {
+				aligned = append(aligned, part.Essence[0])
+			}
+
+			misaligned := make([]int, 0)
+			for _, node := range aligned {
+				if (node.Key.Start-base)%kag != 0 {
+					misaligned = append(misaligned, node.Key.Start)
+				}
+			}
+
+			t.Test(fmt.Sprintf("Checking the contents of the %s partition at offset %v are aligned to the KAG of %v", part.Props.PartitionType, part.Key.Start, kag), mxftest.NewSpecificationDetails(ST377Doc, "6.5", "shall", 1),
+				t.Expect(misaligned).Shall(BeEmpty(), fmt.Sprintf("KLVs not aligned to the KAG at byte offsets %v", misaligned)),
+			)
+		}
+	}
+}
+
+func checkMetadataSets(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		parts, packs, err := partitionPacks(doc, mxf)
+		if err != nil {
+			return
+		}
+
+		for i, part := range parts {
+			if packs[i].HeaderByteCount == 0 {
+				continue
+			}
+
+			primers, primerErr := part.Search("select * from metadata where UL = " + mxf2go.GPrimerPackUL[13:])
+			t.Test(fmt.Sprintf("Checking the header metadata of the %s partition at offset %v has a primer pack", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST377Doc, "9.2", "shall", 1),
+				t.Expect(primerErr).Shall(BeNil()),
+				t.Expect(len(primers)).Shall(Equal(1), fmt.Sprintf("%v primer packs found", len(primers))),
+			)
+
+			prefaces, prefErr := part.Search("select * from metadata where UL = " + mxf2go.GPrefaceUL[13:])
+			t.Test(fmt.Sprintf("Checking the header metadata of the %s partition at offset %v has a preface", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST377Doc, "9.5", "shall", 1),
+				t.Expect(prefErr).Shall(BeNil()),
+				t.Expect(len(prefaces)).Shall(Equal(1), fmt.Sprintf("%v prefaces found", len(prefaces))),
+			)
+
+			// check the instance ID of every set
+			ids := make(map[string]int)
+			for _, set := range metadataNodes(part) {
+				gp, ok := set.Properties.(mxftest.GroupProperties)
+				if !ok || gp.UUID == (mxf2go.TUUID{}) {
+					continue
+				}
+				ids[gp.ID()]++
+			}
+
+			duplicates := make([]string, 0)
+			for id, count := range ids {
+				if count > 1 {
+					duplicates = append(duplicates, id)
+				}
+			}
+			slices.Sort(duplicates)
+
+			t.Test(fmt.Sprintf("Checking the InstanceUID of every set in the %s partition at offset %v is unique", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST377Doc, "9.3", "shall", 1),
+				t.Expect(duplicates).Shall(BeEmpty(), fmt.Sprintf("repeated InstanceUIDs of %v", duplicates)),
+			)
+		}
+	}
+}
+
+func checkRIP(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		ripParts, ripErr := mxf.Search("select * from partitions where type = " + mxftest.RIPPartition)
+
+		t.Test("Checking that a random index pack is present", mxftest.NewSpecificationDetails(ST377Doc, "12", "should", 1),
+			t.Expect(ripErr).Shall(BeNil()),
+			t.Expect(len(ripParts)).Should(Equal(1), fmt.Sprintf("%v random index packs found", len(ripParts))),
+		)
+
+		if len(ripParts) != 1 {
+			return
+		}
+
+		rip := ripParts[0]
+		ripKLV, err := mxftest.NodeToKLV(doc, &mxftest.Node{Key: rip.Key, Length: rip.Length, Value: rip.Value})
+		if err != nil {
+			t.Test("Checking that the random index pack can be read", mxftest.NewSpecificationDetails(ST377Doc, "12", "shall", 1),
+				t.Expect(err).Shall(BeNil()),
+			)
+			return
+		}
+
+		parts, packs, err := partitionPacks(doc, mxf)
+		expected := make([]mxftest.RIP, len(parts))
+		base := fileStart(mxf)
+		for i, part := range parts {
+			expected[i] = mxftest.RIP{Sid: packs[i].BodySID, ByteOffset: uint64(part.Key.Start - base)}
+		}
+		entries, overallLength := mxftest.RIPExtract(ripKLV)
+
+		t.Test("Checking that the random index pack is the last KLV in the file", mxftest.NewSpecificationDetails(ST377Doc, "12", "shall", 1),
+			t.Expect(mxf.Partitions[len(mxf.Partitions)-1]).Shall(BeIdenticalTo(rip)),
+			t.Expect(len(rip.Essence)).Shall(Equal(0), fmt.Sprintf("%v KLVs found after the random index pack", len(rip.Essence))),
+		)
+
+		t.Test("Checking that the random index pack lists the body SID and byte offset of every partition", mxftest.NewSpecificationDetails(ST377Doc, "12", "shall", 2),
+			t.Expect(err).Shall(BeNil()),
+			t.Expect(entries).Shall(Equal(expected)),
+		)
+
+		t.Test("Checking that the random index pack overall length is the length of the pack", mxftest.NewSpecificationDetails(ST377Doc, "12", "shall", 3),
+			t.Expect(overallLength).Shall(Equal(uint32(ripKLV.TotalLength()))),
+		)
+	}
+}
+
+// metadataNodes returns every header metadata set of a partition once,
+// including the children.
+func metadataNodes(part *mxftest.PartitionNode) []*mxftest.Node {
+	seen := make(map[*mxftest.Node]bool)
+	out := make([]*mxftest.Node, 0)
+
+	var walk func(n *mxftest.Node)
+	walk = func(n *mxftest.Node) {
+		if n == nil || seen[n] {
+			return
+		}
+		seen[n] = true
+		out = append(out, n)
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+
+	for _, n := range part.HeaderMetadata {
+		walk(n)
+	}
+
+	return out
+}
+
+// metadataSets returns the UL and InstanceUID of every metadata set
+// in a partition, in a sorted order for comparing partitions.
+func metadataSets(part *mxftest.PartitionNode) []string {
+	sets := make([]string, 0)
+	for _, n := range metadataNodes(part) {
+		if n.Properties == nil {
+			continue
+		}
+		sets = append(sets, n.Properties.UL()+":"+n.Properties.ID())
+	}
+	slices.Sort(sets)
+
+	return sets
+}
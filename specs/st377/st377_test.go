@@ -0,0 +1,80 @@
+package st377
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	mxftest "github.com/metarex-media/mxf-test"
+	"github.com/metarex-media/mxf-test/specs/internal/spectest"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/yaml.v3"
+)
+
+func TestST377(t *testing.T) {
+
+	mxfToTest := []string{"../../testdata/demoReports/goodISXD.mxf",
+		"../../testdata/demoReports/veryBadISXD.mxf", "../../testdata/demoReports/badISXD.mxf"}
+	for _, mxf := range mxfToTest {
+		doc, docErr := os.Open(mxf)
+		var buf bytes.Buffer
+		testErr := mxftest.MRXTest(doc, &buf, Specifications())
+
+		var report mxftest.Report
+		repErr := yaml.Unmarshal(buf.Bytes(), &report)
+
+		Convey("Checking the ST 377-1 specifications pass for valid MXF file structures", t, func() {
+			Convey(fmt.Sprintf("testing %s, which has a valid file structure", mxf), func() {
+				Convey("The tests all pass", func() {
+					So(docErr, ShouldBeNil)
+					So(testErr, ShouldBeNil)
+					So(repErr, ShouldBeNil)
+					So(report.TestPass, ShouldBeTrue)
+				})
+			})
+		})
+	}
+
+	good, readErr := os.ReadFile("../../testdata/demoReports/goodISXD.mxf")
+	// break the file in several ways
+	// the body partition value starts at 2519
+	badPointer := bytes.Clone(good)
+	badPointer[2519+15] = 12
+	// footer pointer of the footer partition, which has a value starting at 11314
+	badFooter := bytes.Clone(good)
+	badFooter[11314+31] = 1
+	// remove the RIP from the end of the file
+	noRIP := bytes.Clone(good[:13793])
+	// change the RIP body SID of the body partition
+	badRIP := bytes.Clone(good)
+	badRIP[13793+20+15] = 7
+
+	inputs := [][]byte{badPointer, badFooter, noRIP, badRIP}
+	descs := []string{"an incorrect ThisPartition value", "an incorrect FooterPartition value", "no random index pack", "an incorrect random index pack"}
+	failed := []string{"7.1,shall,1", "7.1,shall,3", "12,should,1", "12,shall,2"}
+
+	for i, input := range inputs {
+		report, testErr := spectest.Run(input, Specifications())
+
+		// repair the file and run the test again
+		var repaired bytes.Buffer
+		changes, repairErr := mxftest.Repair(bytes.NewReader(input), &repaired)
+		repairedReport, repairedTestErr := spectest.Run(repaired.Bytes(), Specifications())
+
+		Convey("Checking the ST 377-1 specifications fail for invalid MXF file structures", t, func() {
+			Convey(fmt.Sprintf("testing a file with %s, then testing the repaired file", descs[i]), func() {
+				Convey(fmt.Sprintf("Only the %s test fails for the invalid file and the tests pass for the repaired file", failed[i]), func() {
+					So(readErr, ShouldBeNil)
+					So(testErr, ShouldBeNil)
+					So(report.TestPass, ShouldBeFalse)
+					So(spectest.FailedClauses(report, ST377Doc), ShouldResemble, []string{failed[i]})
+					So(repairErr, ShouldBeNil)
+					So(changes, ShouldNotBeEmpty)
+					So(repairedTestErr, ShouldBeNil)
+					So(repairedReport.TestPass, ShouldBeTrue)
+				})
+			})
+		})
+	}
+}
@@ -0,0 +1,152 @@
+// package st378 contains the specifications of the MXF
+// Operational Pattern 1a (OP1a), as defined in ST 378.
+package st378
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	mxftest "github.com/metarex-media/mxf-test"
+	mxf2go "github.com/metarex-media/mxf-to-go"
+	. "github.com/onsi/gomega"
+)
+
+// ST378Doc is the document the specs for
+// OP1a are found in and used for these tests.
+const ST378Doc = "ST378:2004"
+
+// Specifications returns the OP1a specifications.
+// The specifications are only run on files with an OP1a
+// operational pattern, so they can be included for every file.
+func Specifications() mxftest.Specifications {
+	return *mxftest.NewSpecification(
+		mxftest.WithStructureTag(op1aTag),
+		mxftest.WithStructureTests(checkMaterialPackage, checkFilePackages, checkInterleaving),
+	)
+}
+
+// op1aTag checks the operational pattern of the header partition is OP1a
+func op1aTag(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		headerParts, err := mxf.Search("select * from partitions where type = " + mxftest.HeaderPartition)
+		opName := ""
+		if len(headerParts) == 1 {
+			opName = headerParts[0].Props.OperationalPatternName
+		}
+
+		t.Test("Checking that the operational pattern is OP1a", mxftest.NewSpecificationDetails(ST378Doc, "5", "shall", 1),
+			t.Expect(err).Shall(BeNil()),
+			t.Expect(strings.HasPrefix(opName, "MXFOP1a")).Shall(BeTrue()),
+		)
+	}
+}
+
+func checkMaterialPackage(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		parts, err := mxf.Search("select * from partitions where metadata <> 0")
+		t.Test("Checking that the partitions with header metadata can be found", mxftest.NewSpecificationDetails(ST378Doc, "7.1", "shall", 1),
+			t.Expect(err).Shall(BeNil()),
+		)
+
+		for _, part := range parts {
+			materialPackages, err := part.Search("select * from metadata where UL = " + mxf2go.GMaterialPackageUL[13:])
+			t.Test(fmt.Sprintf("Checking the header metadata of the %s partition at offset %v has a single material package", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST378Doc, "7.1", "shall", 1),
+				t.Expect(err).Shall(BeNil()),
+				t.Expect(len(materialPackages)).Shall(Equal(1), fmt.Sprintf("%v material packages found", len(materialPackages))),
+			)
+		}
+	}
+}
+
+func checkFilePackages(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		containers := essenceContainers(mxf)
+
+		parts, err := mxf.Search("select * from partitions where metadata <> 0")
+		t.Test("Checking that the partitions with header metadata can be found", mxftest.NewSpecificationDetails(ST378Doc, "7.2", "shall", 1),
+			t.Expect(err).Shall(BeNil()),
+		)
+
+		for _, part := range parts {
+			sourcePackages, err := part.Search("select * from metadata where UL = " + mxf2go.GSourcePackageUL[13:])
+
+			// file packages are source packages that describe an essence container
+			filePackages := 0
+			var decodeErr error
+			for _, sp := range sourcePackages {
+				for _, child := range sp.Children {
+					if child == nil {
+						continue
+					}
+
+					decoded, err := mxftest.DecodeGroupNode(doc, child, part.Props.Primer)
+					if err != nil {
+						decodeErr = err
+						continue
+					}
+
+					if _, ok := decoded["ContainerFormat"]; ok {
+						filePackages++
+						break
+					}
+				}
+			}
+
+			t.Test(fmt.Sprintf("Checking the header metadata of the %s partition at offset %v has a single file package for each essence container", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST378Doc, "7.2", "shall", 1),
+				t.Expect(err).Shall(BeNil()),
+				t.Expect(decodeErr).Shall(BeNil()),
+				t.Expect(filePackages).Shall(Equal(len(containers)), fmt.Sprintf("%v file packages found for %v essence containers", filePackages, len(containers))),
+			)
+		}
+	}
+}
+
+func checkInterleaving(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		for _, part := range mxf.Partitions {
+			if part.Props.PartitionType == mxftest.GenericStreamPartition || len(part.Essence) == 0 {
+				continue
+			}
+
+			// every content package has the same
+			// essence elements in the same order
+			pattern := part.Props.EssenceOrder
+			breakPoint := -1
+			var extractErr error
+			for i, e := range part.Essence {
+				ess, err := mxftest.NodeToKLV(doc, &mxftest.Node{Key: e.Key})
+				if err != nil {
+					extractErr = err
+					break
+				}
+
+				if mxftest.FullNameMask(ess.Key) != pattern[i%len(pattern)] {
+					breakPoint = e.Key.Start
+					break
+				}
+			}
+
+			incomplete := len(part.Essence)%len(pattern) != 0
+			t.Test(fmt.Sprintf("Checking the essence of the %s partition at offset %v is interleaved as complete content packages", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST378Doc, "8.1", "shall", 1),
+				t.Expect(extractErr).Shall(BeNil()),
+				t.Expect(breakPoint).Shall(Equal(-1), fmt.Sprintf("irregular essence key found at byte offset %v", breakPoint)),
+				t.Expect(incomplete).Shall(BeFalse(), fmt.Sprintf("%v essence elements is not a multiple of the content package of %v elements", len(part.Essence), len(pattern))),
+			)
+		}
+	}
+}
+
+// essenceContainers returns the body SIDs of the partitions with
+// essence, the generic stream partitions are not included.
+func essenceContainers(mxf *mxftest.MXFNode) map[uint32]bool {
+	containers := make(map[uint32]bool)
+	for _, part := range mxf.Partitions {
+		if part.Props.PartitionType == mxftest.GenericStreamPartition || part.Props.PartitionType == mxftest.RIPPartition || len(part.Essence) == 0 {
+			continue
+		}
+		containers[part.Props.BodySID] = true
+	}
+
+	return containers
+}
@@ -0,0 +1,75 @@
+package st378
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/metarex-media/mrx-tool/klv"
+	mxftest "github.com/metarex-media/mxf-test"
+	"github.com/metarex-media/mxf-test/specs/internal/spectest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOP1a(t *testing.T) {
+
+	mxfToTest := []string{"../../testdata/demoReports/goodISXD.mxf",
+		"../../testdata/demoReports/veryBadISXD.mxf", "../../testdata/demoReports/badISXD.mxf"}
+	for _, mxf := range mxfToTest {
+		doc, docErr := os.ReadFile(mxf)
+		report, testErr := spectest.Run(doc, Specifications())
+		count := spectest.TestCount(report, ST378Doc)
+
+		Convey("Checking the OP1a specifications pass for valid OP1a files", t, func() {
+			Convey(fmt.Sprintf("testing %s, which is an OP1a file", mxf), func() {
+				Convey("The OP1a tests are run and they all pass", func() {
+					So(docErr, ShouldBeNil)
+					So(testErr, ShouldBeNil)
+					So(count, ShouldBeGreaterThan, 0)
+					So(report.TestPass, ShouldBeTrue)
+				})
+			})
+		})
+	}
+
+	good, readErr := os.ReadFile("../../testdata/demoReports/goodISXD.mxf")
+	ast, astErr := mxftest.MakeAST(bytes.NewReader(good), make(chan *klv.KLV, 1000), 10, *mxftest.NewSpecification())
+
+	// change the key of the second essence element
+	// to break up the content package
+	badInterleave := bytes.Clone(good)
+	badInterleave[ast.Partitions[1].Essence[1].Key.Start+15] = 0x02
+
+	report, testErr := spectest.Run(badInterleave, Specifications())
+	count := spectest.TestCount(report, ST378Doc)
+	Convey("Checking the OP1a specifications fail for badly interleaved essence", t, func() {
+		Convey("testing an OP1a file with an essence key that breaks the content package order", func() {
+			Convey("The OP1a tests are run and fail", func() {
+				So(readErr, ShouldBeNil)
+				So(astErr, ShouldBeNil)
+				So(testErr, ShouldBeNil)
+				So(count, ShouldBeGreaterThan, 0)
+				So(report.TestPass, ShouldBeFalse)
+			})
+		})
+	})
+
+	// change the operational pattern of every partition pack to OP-Atom
+	opAtom := bytes.Clone(good)
+	for _, part := range ast.Partitions[:3] {
+		copy(opAtom[part.Value.Start+64:], []byte{0x06, 0x0e, 0x2b, 0x34, 0x04, 0x01, 0x01, 0x02, 0x0d, 0x01, 0x02, 0x01, 0x10, 0x00, 0x00, 0x00})
+	}
+
+	report, testErr = spectest.Run(opAtom, Specifications())
+	count = spectest.TestCount(report, ST378Doc)
+	Convey("Checking the OP1a specifications only run on OP1a files", t, func() {
+		Convey("testing an OP-Atom file", func() {
+			Convey("No OP1a tests are run", func() {
+				So(testErr, ShouldBeNil)
+				So(count, ShouldEqual, 0)
+				So(report.TestPass, ShouldBeTrue)
+			})
+		})
+	})
+}
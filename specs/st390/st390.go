@@ -0,0 +1,122 @@
+// package st390 contains the specifications of the MXF
+// Operational Pattern Atom (OP-Atom), as defined in ST 390.
+package st390
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	mxftest "github.com/metarex-media/mxf-test"
+	"github.com/metarex-media/mxf-test/specs/internal/layout"
+	. "github.com/onsi/gomega"
+)
+
+// ST390Doc is the document the specs for
+// OP-Atom are found in and used for these tests.
+const ST390Doc = "ST390:2011"
+
+// Specifications returns the OP-Atom specifications.
+// The specifications are only run on files with an OP-Atom
+// operational pattern, so they can be included for every file.
+func Specifications() mxftest.Specifications {
+	return *mxftest.NewSpecification(
+		mxftest.WithStructureTag(opAtomTag),
+		mxftest.WithStructureTests(checkEssenceTrack, checkClipWrapped, checkIndexTables, checkFooter),
+	)
+}
+
+// opAtomTag checks the operational pattern of the header partition is OP-Atom
+func opAtomTag(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		headerParts, err := mxf.Search("select * from partitions where type = " + mxftest.HeaderPartition)
+		opName := ""
+		if len(headerParts) == 1 {
+			opName = headerParts[0].Props.OperationalPatternName
+		}
+
+		t.Test("Checking that the operational pattern is OP-Atom", mxftest.NewSpecificationDetails(ST390Doc, "5", "shall", 1),
+			t.Expect(err).Shall(BeNil()),
+			t.Expect(strings.HasPrefix(opName, "MXFOPAtom")).Shall(BeTrue()),
+		)
+	}
+}
+
+func checkEssenceTrack(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		bodySIDs := make(map[uint32]bool)
+		keys := make(map[string]bool)
+		for _, part := range layout.EssencePartitions(mxf) {
+			bodySIDs[part.Props.BodySID] = true
+			for _, e := range part.Essence {
+				keys[e.Properties.UL()] = true
+			}
+		}
+
+		t.Test("Checking that the file contains a single essence container", mxftest.NewSpecificationDetails(ST390Doc, "6.1", "shall", 1),
+			t.Expect(len(bodySIDs)).Shall(Equal(1), fmt.Sprintf("%v essence containers found", len(bodySIDs))),
+		)
+
+		t.Test("Checking that the essence container contains a single essence track", mxftest.NewSpecificationDetails(ST390Doc, "6.1", "shall", 2),
+			t.Expect(len(keys)).Shall(Equal(1), fmt.Sprintf("%v essence element keys found", len(keys))),
+		)
+	}
+}
+
+func checkClipWrapped(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		essenceCount := 0
+		for _, part := range layout.EssencePartitions(mxf) {
+			essenceCount += len(part.Essence)
+		}
+
+		t.Test("Checking that the essence is clip wrapped as a single KLV", mxftest.NewSpecificationDetails(ST390Doc, "6.2", "shall", 1),
+			t.Expect(essenceCount).Shall(Equal(1), fmt.Sprintf("%v essence KLVs found", essenceCount)),
+		)
+	}
+}
+
+func checkIndexTables(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		indexCount := 0
+		for _, part := range mxf.Partitions {
+			if part.IndexTable == nil {
+				continue
+			}
+			indexCount++
+
+			t.Test(fmt.Sprintf("Checking the index table of the %s partition at offset %v is not in a partition with essence", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST390Doc, "6.3", "shall", 2),
+				t.Expect(len(part.Essence)).Shall(Equal(0), fmt.Sprintf("%v essence KLVs found in the partition", len(part.Essence))),
+			)
+
+			t.Test(fmt.Sprintf("Checking the index table of the %s partition at offset %v has an IndexSID", part.Props.PartitionType, part.Key.Start), mxftest.NewSpecificationDetails(ST390Doc, "6.3", "shall", 3),
+				t.Expect(part.Props.IndexSID).ShallNot(Equal(uint32(0))),
+			)
+		}
+
+		t.Test("Checking that the file contains an index table", mxftest.NewSpecificationDetails(ST390Doc, "6.3", "shall", 1),
+			t.Expect(indexCount).ShallNot(Equal(0), "no index tables found"),
+		)
+	}
+}
+
+func checkFooter(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		footerParts, err := mxf.Search("select * from partitions where type = " + mxftest.FooterPartition)
+
+		t.Test("Checking that a footer partition is present", mxftest.NewSpecificationDetails(ST390Doc, "6.4", "shall", 1),
+			t.Expect(err).Shall(BeNil()),
+			t.Expect(len(footerParts)).Shall(Equal(1), fmt.Sprintf("%v footer partitions found", len(footerParts))),
+		)
+
+		if len(footerParts) != 1 {
+			return
+		}
+
+		footer := footerParts[0]
+		t.Test("Checking that the footer partition is closed and complete, with header metadata", mxftest.NewSpecificationDetails(ST390Doc, "6.4", "shall", 2),
+			t.Expect(footer.Props.Status).Shall(Equal(mxftest.ClosedComplete)),
+			t.Expect(len(footer.HeaderMetadata)).ShallNot(Equal(0), "no header metadata found in the footer partition"),
+		)
+	}
+}
@@ -0,0 +1,80 @@
+package st390
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/metarex-media/mrx-tool/klv"
+	mxftest "github.com/metarex-media/mxf-test"
+	"github.com/metarex-media/mxf-test/specs/internal/spectest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var opAtomUL = []byte{0x06, 0x0e, 0x2b, 0x34, 0x04, 0x01, 0x01, 0x02, 0x0d, 0x01, 0x02, 0x01, 0x10, 0x00, 0x00, 0x00}
+
+func TestOPAtom(t *testing.T) {
+
+	good, readErr := os.ReadFile("../../testdata/demoReports/goodISXD.mxf")
+	ast, astErr := mxftest.MakeAST(bytes.NewReader(good), make(chan *klv.KLV, 1000), 10, *mxftest.NewSpecification())
+	header, body, footer := ast.Partitions[0], ast.Partitions[1], ast.Partitions[2]
+
+	// an OP1a file is not tested
+	report, testErr := spectest.Run(good, Specifications())
+	count := spectest.TestCount(report, ST390Doc)
+	Convey("Checking the OP-Atom specifications only run on OP-Atom files", t, func() {
+		Convey("testing an OP1a file", func() {
+			Convey("No OP-Atom tests are run", func() {
+				So(readErr, ShouldBeNil)
+				So(astErr, ShouldBeNil)
+				So(testErr, ShouldBeNil)
+				So(count, ShouldEqual, 0)
+				So(report.TestPass, ShouldBeTrue)
+			})
+		})
+	})
+
+	// label the frame wrapped file as OP-Atom
+	frameWrapped := bytes.Clone(good)
+	for _, part := range []*mxftest.PartitionNode{header, body, footer} {
+		copy(frameWrapped[part.Value.Start+64:], opAtomUL)
+	}
+
+	// build an OP-Atom file, with a single clip wrapped essence element
+	// and an index table in the footer partition.
+	var atom []byte
+	atom = append(atom, frameWrapped[:body.Key.Start]...)
+	atom = append(atom, frameWrapped[body.Key.Start:body.Essence[0].Value.End]...)
+	footerStart := len(atom)
+	atom = append(atom, frameWrapped[footer.Key.Start:ast.Partitions[3].Key.Start]...)
+	atom = append(atom, spectest.IndexTable...)
+	footerValue := footerStart + footer.Value.Start - footer.Key.Start
+	// set the index byte count and index SID
+	copy(atom[footerValue+40:], []byte{0, 0, 0, 0, 0, 0, 0, byte(len(spectest.IndexTable))})
+	copy(atom[footerValue+48:], []byte{0, 0, 0, 2})
+
+	// fix the partition pointers and the RIP
+	var repaired bytes.Buffer
+	_, repairErr := mxftest.Repair(bytes.NewReader(atom), &repaired)
+
+	inputs := [][]byte{repaired.Bytes(), frameWrapped}
+	descs := []string{"a clip wrapped OP-Atom file, with an index table in the footer", "a frame wrapped OP-Atom file, with no index table"}
+	expectedPass := []bool{true, false}
+
+	for i, input := range inputs {
+		report, testErr := spectest.Run(input, Specifications())
+		count := spectest.TestCount(report, ST390Doc)
+
+		Convey("Checking the OP-Atom specifications run on OP-Atom files", t, func() {
+			Convey(fmt.Sprintf("testing %s", descs[i]), func() {
+				Convey(fmt.Sprintf("The OP-Atom tests are run and the pass status is %v", expectedPass[i]), func() {
+					So(repairErr, ShouldBeNil)
+					So(testErr, ShouldBeNil)
+					So(count, ShouldBeGreaterThan, 0)
+					So(report.TestPass, ShouldEqual, expectedPass[i])
+				})
+			})
+		})
+	}
+}
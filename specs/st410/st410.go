@@ -0,0 +1,205 @@
+// package st410 contains the specifications of the MXF
+// generic stream partitions, as defined in ST 410.
+package st410
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	mxftest "github.com/metarex-media/mxf-test"
+	. "github.com/onsi/gomega"
+)
+
+// ST410Doc is the document the specs for
+// generic streams are found in and used for these tests.
+const ST410Doc = "ST410:2008"
+
+// Specifications returns the generic stream specifications.
+// The tests are only run on generic stream partitions,
+// so files without generic streams are unaffected.
+func Specifications() mxftest.Specifications {
+	return *mxftest.NewSpecification(
+		mxftest.WithPartitionTests(
+			mxftest.PartitionTest{PartitionType: mxftest.GenericBody, Test: checkGenericPartitionPack},
+			mxftest.PartitionTest{PartitionType: mxftest.GenericBody, Test: checkDataElementKeys},
+		),
+		mxftest.WithStructureTests(checkStreamIDs, checkHeaderLinkage),
+	)
+}
+
+func checkGenericPartitionPack(doc io.ReadSeeker, part *mxftest.PartitionNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		packKLV, err := mxftest.NodeToKLV(doc, &mxftest.Node{Key: part.Key, Length: part.Length, Value: part.Value})
+		t.Test("Checking that the generic stream partition pack can be read", mxftest.NewSpecificationDetails(ST410Doc, "6.2", "shall", 1),
+			t.Expect(err).Shall(BeNil()),
+		)
+
+		if err != nil {
+			return
+		}
+		pack := mxftest.PartitionExtract(packKLV)
+
+		t.Test("Checking that the header byte count of the generic stream partition is 0", mxftest.NewSpecificationDetails(ST410Doc, "6.2", "shall", 2),
+			t.Expect(pack.HeaderByteCount).Shall(Equal(uint64(0)), "header metadata byte count not 0"),
+		)
+
+		t.Test("Checking that the index byte count and index SID of the generic stream partition are 0", mxftest.NewSpecificationDetails(ST410Doc, "6.2", "shall", 3),
+			t.Expect(pack.IndexByteCount).Shall(Equal(uint64(0)), "index byte count not 0"),
+			t.Expect(pack.IndexSID).Shall(Equal(uint32(0)), "index SID not 0"),
+		)
+
+		t.Test("Checking that the generic stream partition has a stream ID", mxftest.NewSpecificationDetails(ST410Doc, "6.2", "shall", 4),
+			t.Expect(pack.BodySID).ShallNot(Equal(uint32(0)), "body SID is 0"),
+		)
+	}
+}
+
+func checkDataElementKeys(doc io.ReadSeeker, part *mxftest.PartitionNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		var extractErr error
+		invalidKeys := make([]string, 0)
+		flags := make([]mxftest.GenericStreamKey, 0)
+		for _, e := range part.Essence {
+			// only read the key
+			ess, err := mxftest.NodeToKLV(doc, &mxftest.Node{Key: e.Key})
+			if err != nil {
+				extractErr = err
+				break
+			}
+
+			gsk, ok := mxftest.GenericStreamKeyExtract(ess.Key)
+			if !ok {
+				invalidKeys = append(invalidKeys, mxftest.FullNameMask(ess.Key))
+				continue
+			}
+
+			if !slices.Contains(flags, gsk) {
+				flags = append(flags, gsk)
+			}
+		}
+
+		t.Test("Checking that the essence keys of the generic stream partition are generic stream data element keys", mxftest.NewSpecificationDetails(ST410Doc, "7.2", "shall", 1),
+			t.Expect(extractErr).Shall(BeNil()),
+			t.Expect(invalidKeys).Shall(BeEmpty(), fmt.Sprintf("invalid generic stream keys of %v found", invalidKeys)),
+		)
+
+		for _, gsk := range flags {
+			t.Test("Checking that the byte order and wrapping flags of the generic stream data element key are valid", mxftest.NewSpecificationDetails(ST410Doc, "7.2", "shall", 2),
+				t.Expect(gsk.ByteOrder).ShallNot(Equal(mxftest.ByteOrderInvalid)),
+				t.Expect(gsk.Wrapping).ShallNot(Equal(mxftest.WrappingInvalid)),
+			)
+		}
+
+		t.Test("Checking that the generic stream partition uses a single set of data element key flags", mxftest.NewSpecificationDetails(ST410Doc, "7.2", "should", 1),
+			t.Expect(len(flags)).Should(BeNumerically("<=", 1), fmt.Sprintf("%v different data element key flags found", len(flags))),
+		)
+	}
+}
+
+func checkStreamIDs(_ io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		// find the stream IDs used by anything that is not
+		// a generic stream
+		otherIDs := make(map[uint32]bool)
+		for _, part := range mxf.Partitions {
+			if part.Props.IndexSID != 0 {
+				otherIDs[part.Props.IndexSID] = true
+			}
+
+			if part.Props.PartitionType != mxftest.GenericStreamPartition && part.Props.BodySID != 0 {
+				otherIDs[part.Props.BodySID] = true
+			}
+		}
+
+		for _, part := range mxf.Partitions {
+			if part.Props.PartitionType != mxftest.GenericStreamPartition {
+				continue
+			}
+
+			t.Test(fmt.Sprintf("Checking the stream ID of the generic stream partition at offset %v is unique to the generic stream", part.Key.Start), mxftest.NewSpecificationDetails(ST410Doc, "6.3", "shall", 1),
+				t.Expect(otherIDs[part.Props.BodySID]).Shall(BeFalse(), fmt.Sprintf("stream ID %v is used by other partition contents", part.Props.BodySID)),
+			)
+		}
+	}
+}
+
+func checkHeaderLinkage(doc io.ReadSeeker, mxf *mxftest.MXFNode) func(t mxftest.Test) {
+	return func(t mxftest.Test) {
+		genericParts, err := mxf.Search("select * from partitions where type = " + mxftest.GenericStreamPartition)
+		t.Test("Checking that the generic stream partitions can be found", mxftest.NewSpecificationDetails(ST410Doc, "6.3", "shall", 2),
+			t.Expect(err).Shall(BeNil()),
+		)
+
+		if len(genericParts) == 0 {
+			return
+		}
+
+		// use the latest header metadata in the file
+		var metadata *mxftest.PartitionNode
+		for _, part := range mxf.Partitions {
+			if len(part.HeaderMetadata) > 0 {
+				metadata = part
+			}
+		}
+
+		t.Test("Checking that the file has header metadata to describe the generic streams", mxftest.NewSpecificationDetails(ST410Doc, "6.3", "shall", 2),
+			t.Expect(metadata).ShallNot(BeNil(), "no header metadata found"),
+		)
+
+		if metadata == nil {
+			return
+		}
+
+		// find every generic stream ID referenced in the metadata
+		linked, decodeErr := streamIDs(doc, metadata)
+		for _, part := range genericParts {
+			t.Test(fmt.Sprintf("Checking the stream ID of the generic stream partition at offset %v is referenced in the header metadata", part.Key.Start), mxftest.NewSpecificationDetails(ST410Doc, "6.3", "shall", 2),
+				t.Expect(decodeErr).Shall(BeNil()),
+				t.Expect(linked[part.Props.BodySID]).Shall(BeTrue(), fmt.Sprintf("stream ID %v not found in the header metadata", part.Props.BodySID)),
+			)
+		}
+	}
+}
+
+// streamIDs returns the GenericStreamID values found in the header metadata
+// of a partition
+func streamIDs(doc io.ReadSeeker, part *mxftest.PartitionNode) (map[uint32]bool, error) {
+	ids := make(map[uint32]bool)
+	seen := make(map[*mxftest.Node]bool)
+
+	var walk func(n *mxftest.Node) error
+	walk = func(n *mxftest.Node) error {
+		if n == nil || seen[n] {
+			return nil
+		}
+		seen[n] = true
+
+		if _, ok := n.Properties.(mxftest.GroupProperties); ok && n.Properties.UL() != "060e2b34.027f0101.0d010201.01050100" {
+			decoded, err := mxftest.DecodeGroupNode(doc, n, part.Props.Primer)
+			if err != nil {
+				return err
+			}
+
+			if id, ok := decoded["GenericStreamID"].(uint32); ok {
+				ids[id] = true
+			}
+		}
+
+		for _, child := range n.Children {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for _, n := range part.HeaderMetadata {
+		if err := walk(n); err != nil {
+			return ids, err
+		}
+	}
+
+	return ids, nil
+}
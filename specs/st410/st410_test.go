@@ -0,0 +1,64 @@
+package st410
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/metarex-media/mrx-tool/klv"
+	mxftest "github.com/metarex-media/mxf-test"
+	"github.com/metarex-media/mxf-test/specs/internal/spectest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestST410(t *testing.T) {
+
+	gsKey := []byte{0x06, 0x0e, 0x2b, 0x34, 0x01, 0x01, 0x01, 0x0c, 0x0d, 0x01, 0x05, 0x09, 0x01, 0x00, 0x00, 0x00}
+
+	mxfToTest := []string{"../../testdata/demoReports/veryBadISXD.mxf", "../../example/testdata/gpsdemo.mxf"}
+	for _, mxf := range mxfToTest {
+		doc, readErr := os.ReadFile(mxf)
+		ast, astErr := mxftest.MakeAST(bytes.NewReader(doc), make(chan *klv.KLV, 1000), 10, *mxftest.NewSpecification())
+
+		// the metarex manifest key is not a generic stream key
+		// so update the keys to be valid
+		valid := bytes.Clone(doc)
+		for _, part := range ast.Partitions {
+			if part.Props.PartitionType != mxftest.GenericStreamPartition {
+				continue
+			}
+
+			for _, e := range part.Essence {
+				if !mxftest.IsGenericStreamKey(valid[e.Key.Start:e.Key.End]) {
+					copy(valid[e.Key.Start:], gsKey)
+				}
+			}
+		}
+
+		// set the final generic stream ID to match the essence body SID
+		sharedSID := bytes.Clone(valid)
+		genericParts, searchErr := ast.Search("select * from partitions where type = " + mxftest.QuoteValue(mxftest.GenericStreamPartition))
+		copy(sharedSID[genericParts[len(genericParts)-1].Value.Start+60:], []byte{0, 0, 0, 1})
+
+		inputs := [][]byte{valid, doc, sharedSID}
+		descs := []string{"valid generic streams", "generic streams with manifest keys", "a generic stream ID that is used by the essence"}
+		expectedPass := []bool{true, false, false}
+
+		for i, input := range inputs {
+			report, testErr := spectest.Run(input, Specifications())
+
+			Convey("Checking the ST 410 specifications for generic stream partitions", t, func() {
+				Convey(fmt.Sprintf("testing %s with %s", mxf, descs[i]), func() {
+					Convey(fmt.Sprintf("The tests are run and the pass status is %v", expectedPass[i]), func() {
+						So(readErr, ShouldBeNil)
+						So(astErr, ShouldBeNil)
+						So(searchErr, ShouldBeNil)
+						So(testErr, ShouldBeNil)
+						So(report.TestPass, ShouldEqual, expectedPass[i])
+					})
+				})
+			})
+		}
+	}
+}
@@ -0,0 +1,40 @@
+module github.com/metarex-media/mxf-test/sqlexport
+
+go 1.23.1
+
+require (
+	github.com/metarex-media/mrx-tool v0.0.0-20240828154115-a35b70808514
+	github.com/metarex-media/mxf-test v0.0.0-00010101000000-000000000000
+	github.com/smartystreets/goconvey v1.8.1
+	gopkg.in/yaml.v3 v3.0.1
+	modernc.org/sqlite v1.33.1
+)
+
+require (
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/google/go-cmp v0.6.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/gopherjs/gopherjs v1.17.2 // indirect
+	github.com/hashicorp/golang-lru/v2 v2.0.7 // indirect
+	github.com/jtolds/gls v4.20.0+incompatible // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/metarex-media/mxf-to-go v0.0.0-20240828141327-5be4d71f75d8 // indirect
+	github.com/ncruces/go-strftime v0.1.9 // indirect
+	github.com/onsi/gomega v1.34.2 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	github.com/smarty/assertions v1.15.0 // indirect
+	golang.org/x/net v0.29.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
+	golang.org/x/sys v0.25.0 // indirect
+	golang.org/x/text v0.18.0 // indirect
+	modernc.org/gc/v3 v3.0.0-20240107210532-573471604cb6 // indirect
+	modernc.org/libc v1.55.3 // indirect
+	modernc.org/mathutil v1.6.0 // indirect
+	modernc.org/memory v1.8.0 // indirect
+	modernc.org/strutil v1.2.0 // indirect
+	modernc.org/token v1.1.0 // indirect
+)
+
+// the package is developed alongside the mxftest package, the
+// required version is updated when the package is released
+replace github.com/metarex-media/mxf-test => ../
@@ -0,0 +1,446 @@
+/*
+Package sqlexport writes the abstract syntax tree of MXF files to a SQLite database,
+so the files can be queried with SQL. The database is written with a pure go
+SQLite driver, so no cgo is required.
+
+Every file is given a row in the files table and its own file_id, which is used
+by every other table, so many files can be exported to the same database and queried together.
+
+e.g. finding the edit rate of every timeline track
+
+	select metadata.path, properties.value from metadata
+	join properties on properties.file_id = metadata.file_id and properties.node_id = metadata.node_id
+	where metadata.symbol = 'TimelineTrack' and properties.name = 'EditRate'
+
+The tables are:
+
+  - files - the name of each file and whether the tests passed, which is null without a report
+  - partitions - the partition pack of each partition and the count of its metadata and essence
+  - essence_containers - the essence container ULs of each partition
+  - metadata - every header metadata group, with its parent, path, UL, symbol and InstanceUID
+  - properties - the decoded properties of the metadata, with a row for each item of an array
+  - metadata_references - the strong references from each group to its children
+  - index_tables - the position of the index table of each partition
+  - essence - every essence KLV, with the partition and position in the partition
+  - sniffs - the sniff results of the essence
+  - tests - the result of every check of a test report
+  - skipped_tests - the tests of a report that were skipped
+
+Specifications can query the file with SQL as part of a test, by exporting the file to an in memory database.
+
+	db, err := sqlexport.Open(":memory:")
+	...
+	_, err = sqlexport.Export(db, "", mxf, nil)
+	...
+	rows, err := db.Query("select count(*) from metadata where symbol = 'StaticTrack'")
+*/
+package sqlexport
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	mxftest "github.com/metarex-media/mxf-test"
+	_ "modernc.org/sqlite" // the pure go SQLite driver
+)
+
+// schema is the layout of the exported database
+const schema = `
+CREATE TABLE IF NOT EXISTS files (
+	file_id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	pass INTEGER
+);
+CREATE TABLE IF NOT EXISTS partitions (
+	file_id INTEGER NOT NULL,
+	partition INTEGER NOT NULL,
+	type TEXT,
+	status TEXT,
+	operational_pattern TEXT,
+	op TEXT,
+	body_sid INTEGER,
+	index_sid INTEGER,
+	kag INTEGER,
+	this_partition INTEGER,
+	previous_partition INTEGER,
+	footer_partition INTEGER,
+	header_byte_count INTEGER,
+	index_byte_count INTEGER,
+	body_offset INTEGER,
+	major_version INTEGER,
+	minor_version INTEGER,
+	metadata_count INTEGER,
+	essence_count INTEGER,
+	byte_offset INTEGER,
+	byte_length INTEGER,
+	PRIMARY KEY (file_id, partition)
+);
+CREATE TABLE IF NOT EXISTS essence_containers (
+	file_id INTEGER NOT NULL,
+	partition INTEGER NOT NULL,
+	ul TEXT
+);
+CREATE TABLE IF NOT EXISTS metadata (
+	file_id INTEGER NOT NULL,
+	node_id INTEGER NOT NULL,
+	partition INTEGER NOT NULL,
+	parent_id INTEGER,
+	path TEXT,
+	ul TEXT,
+	symbol TEXT,
+	instance_uid TEXT,
+	byte_offset INTEGER,
+	byte_length INTEGER,
+	PRIMARY KEY (file_id, node_id)
+);
+CREATE TABLE IF NOT EXISTS properties (
+	file_id INTEGER NOT NULL,
+	node_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	position INTEGER NOT NULL,
+	value TEXT
+);
+CREATE TABLE IF NOT EXISTS metadata_references (
+	file_id INTEGER NOT NULL,
+	parent_id INTEGER NOT NULL,
+	child_id INTEGER NOT NULL,
+	position INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS index_tables (
+	file_id INTEGER NOT NULL,
+	partition INTEGER NOT NULL,
+	byte_offset INTEGER,
+	byte_length INTEGER
+);
+CREATE TABLE IF NOT EXISTS essence (
+	file_id INTEGER NOT NULL,
+	partition INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	ul TEXT,
+	symbol TEXT,
+	byte_offset INTEGER,
+	byte_length INTEGER
+);
+CREATE TABLE IF NOT EXISTS sniffs (
+	file_id INTEGER NOT NULL,
+	partition INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	sniff_key TEXT,
+	value TEXT,
+	content_type TEXT,
+	certainty REAL
+);
+CREATE TABLE IF NOT EXISTS tests (
+	file_id INTEGER NOT NULL,
+	section INTEGER NOT NULL,
+	header TEXT,
+	test INTEGER NOT NULL,
+	message TEXT,
+	check_position INTEGER NOT NULL,
+	pass INTEGER,
+	error_message TEXT
+);
+CREATE TABLE IF NOT EXISTS skipped_tests (
+	file_id INTEGER NOT NULL,
+	test_key TEXT,
+	description TEXT
+);
+CREATE INDEX IF NOT EXISTS metadata_ul ON metadata (ul);
+CREATE INDEX IF NOT EXISTS properties_node ON properties (file_id, node_id);
+CREATE INDEX IF NOT EXISTS properties_name ON properties (name, value);
+`
+
+// Open opens the SQLite database at path, creating the database
+// and its tables if they do not exist.
+// Use ":memory:" for a database that is only kept in memory.
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite only has one writer, and in memory
+	// databases are only found on the one connection
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating the database tables: %w", err)
+	}
+
+	return db, nil
+}
+
+/*
+Export writes the AST of an MXF file to a database made with Open,
+returning the file_id of the file. The file is exported in a single transaction,
+so nothing is written if there is an error.
+
+The name is stored in the files table to identify the file, e.g. its path.
+The report is optional, and is the report of the file
+from mxftest.MRXTest, which can be read with yaml.Unmarshal.
+*/
+func Export(db *sql.DB, name string, mxf *mxftest.MXFNode, report *mxftest.Report) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	// roll back is a no-op once the transaction is committed
+	defer tx.Rollback()
+
+	var pass any
+	if report != nil {
+		pass = report.TestPass
+	}
+
+	res, err := tx.Exec("INSERT INTO files (name, pass) VALUES (?, ?)", name, pass)
+	if err != nil {
+		return 0, err
+	}
+
+	fileID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	e := &exporter{tx: tx, fileID: fileID, stmts: make(map[string]*sql.Stmt), ids: make(map[*mxftest.Node]int64),
+		written: make(map[*mxftest.Node]bool), partition: -1}
+	defer e.close()
+
+	if err := mxftest.Walk(mxf, mxftest.Visitor{Enter: e.enter}); err != nil {
+		return 0, err
+	}
+
+	if report != nil {
+		if err := e.report(*report); err != nil {
+			return 0, err
+		}
+	}
+
+	return fileID, tx.Commit()
+}
+
+// exporter writes the items of an AST as they are walked
+type exporter struct {
+	tx     *sql.Tx
+	fileID int64
+	// the prepared statements by their query
+	stmts map[string]*sql.Stmt
+	// the node_id of each metadata node, and the nodes that have been written
+	ids     map[*mxftest.Node]int64
+	written map[*mxftest.Node]bool
+	// the positions of the current partition and essence
+	partition, essence int
+}
+
+// exec runs a query with the file_id as the first argument,
+// preparing the query the first time it is used.
+func (e *exporter) exec(query string, args ...any) error {
+	stmt, ok := e.stmts[query]
+	if !ok {
+		var err error
+		stmt, err = e.tx.Prepare(query)
+		if err != nil {
+			return err
+		}
+		e.stmts[query] = stmt
+	}
+
+	_, err := stmt.Exec(append([]any{e.fileID}, args...)...)
+
+	return err
+}
+
+func (e *exporter) close() {
+	for _, stmt := range e.stmts {
+		stmt.Close()
+	}
+}
+
+// enter writes an item of the AST
+func (e *exporter) enter(item mxftest.WalkItem) error {
+	switch item.Kind {
+	case mxftest.WalkPartition:
+		e.partition++
+		e.essence = 0
+
+		return e.writePartition(item.Partition)
+	case mxftest.WalkMetadata:
+		return e.writeMetadata(item)
+	case mxftest.WalkIndex:
+		return e.exec("INSERT INTO index_tables (file_id, partition, byte_offset, byte_length) VALUES (?, ?, ?, ?)",
+			e.partition, item.Node.Key.Start, item.Node.Value.End-item.Node.Key.Start)
+	case mxftest.WalkEssence:
+		defer func() { e.essence++ }()
+
+		return e.writeEssence(item.Node)
+	}
+
+	return nil
+}
+
+func (e *exporter) writePartition(p *mxftest.PartitionNode) error {
+	props := p.Props
+	err := e.exec(`INSERT INTO partitions (file_id, partition, type, status, operational_pattern, op,
+		body_sid, index_sid, kag, this_partition, previous_partition, footer_partition,
+		header_byte_count, index_byte_count, body_offset, major_version, minor_version,
+		metadata_count, essence_count, byte_offset, byte_length)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.partition, props.PartitionType, props.Status, props.OperationalPattern, props.OperationalPatternName,
+		props.BodySID, props.IndexSID, props.KAGSize, props.ThisPartition, props.PreviousPartition, props.FooterPartition,
+		props.HeaderByteCount, props.IndexByteCount, props.BodyOffset, props.MajorVersion, props.MinorVersion,
+		len(p.HeaderMetadata), len(p.Essence), p.Key.Start, p.Value.End-p.Key.Start)
+	if err != nil {
+		return err
+	}
+
+	for _, ul := range props.EssenceContainers {
+		if err := e.exec("INSERT INTO essence_containers (file_id, partition, ul) VALUES (?, ?, ?)", e.partition, ul); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeMetadata writes a metadata node, its properties and the
+// reference from its parent. Nodes referenced by more than one group
+// are only written once, with a reference from each group.
+func (e *exporter) writeMetadata(item mxftest.WalkItem) error {
+	n := item.Node
+	nodeID := e.id(n)
+
+	if parent, _ := item.Parent(); parent.Node != nil {
+		err := e.exec("INSERT INTO metadata_references (file_id, parent_id, child_id, position) VALUES (?, ?, ?, ?)",
+			e.id(parent.Node), nodeID, slices.Index(parent.Node.Children, n))
+		if err != nil {
+			return err
+		}
+	}
+
+	// the children have been written with the node
+	if e.written[n] {
+		return mxftest.SkipChildren
+	}
+	e.written[n] = true
+
+	return e.writeNode(n, nodeID)
+}
+
+// id returns the node_id of a metadata node
+func (e *exporter) id(n *mxftest.Node) int64 {
+	if id, ok := e.ids[n]; ok {
+		return id
+	}
+
+	e.ids[n] = int64(len(e.ids) + 1)
+
+	return e.ids[n]
+}
+
+func (e *exporter) writeNode(n *mxftest.Node, nodeID int64) error {
+	// the parent the node is threaded to, if it is referenced by more than one group
+	var parentID, instanceUID, symbol any
+	if parent := n.Parent(); parent != nil {
+		parentID = e.id(parent)
+	}
+
+	if id := n.Properties.ID(); strings.Trim(id, "0") != "" {
+		instanceUID = id
+	}
+
+	if key, err := hex.DecodeString(strings.ReplaceAll(n.Properties.UL(), ".", "")); err == nil {
+		if group, ok := mxftest.GroupLookUp(key); ok {
+			symbol = group.Name
+		}
+	}
+
+	err := e.exec(`INSERT INTO metadata (file_id, node_id, partition, parent_id, path, ul, symbol, instance_uid, byte_offset, byte_length)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		nodeID, e.partition, parentID, n.Path(), n.Properties.UL(), symbol, instanceUID, n.Key.Start, n.Value.End-n.Key.Start)
+	if err != nil {
+		return err
+	}
+
+	props := n.PropertyValues()
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		for pos, value := range props[name] {
+			if err := e.exec("INSERT INTO properties (file_id, node_id, name, position, value) VALUES (?, ?, ?, ?, ?)", nodeID, name, pos, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e *exporter) writeEssence(n *mxftest.Node) error {
+	var symbol any
+	if essence, ok := mxftest.EssenceLookUp(n.Properties.UL()); ok {
+		symbol = essence.Symbol
+	}
+
+	err := e.exec("INSERT INTO essence (file_id, partition, position, ul, symbol, byte_offset, byte_length) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		e.partition, e.essence, n.Properties.UL(), symbol, n.Key.Start, n.Value.End-n.Key.Start)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(n.Sniffs))
+	for key := range n.Sniffs {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		sniff := n.Sniffs[key]
+		if sniff == nil {
+			continue
+		}
+
+		err := e.exec("INSERT INTO sniffs (file_id, partition, position, sniff_key, value, content_type, certainty) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			e.partition, e.essence, key, sniff.Field, string(sniff.Data), sniff.Certainty)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// report writes the checks and skipped tests of a test report
+func (e *exporter) report(report mxftest.Report) error {
+	for s, section := range report.Tests {
+		for t, test := range section.Tests {
+			for c, check := range test.Checks {
+				var errMessage any
+				if check.ErrMessage != "" {
+					errMessage = check.ErrMessage
+				}
+
+				err := e.exec(`INSERT INTO tests (file_id, section, header, test, message, check_position, pass, error_message)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, s, section.Header, t, strings.TrimSpace(test.Message), c, check.Pass, errMessage)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, skipped := range report.SkippedTests {
+		if err := e.exec("INSERT INTO skipped_tests (file_id, test_key, description) VALUES (?, ?, ?)", skipped.TestKey, skipped.Desc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
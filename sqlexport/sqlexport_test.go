@@ -0,0 +1,133 @@
+package sqlexport
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metarex-media/mrx-tool/klv"
+	mxftest "github.com/metarex-media/mxf-test"
+	"github.com/metarex-media/mxf-test/specs/st377"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExport(t *testing.T) {
+	mxfFile := "../testdata/demoReports/goodISXD.mxf"
+
+	doc, docErr := os.Open(mxfFile)
+	ast, genErr := mxftest.MakeAST(doc, make(chan *klv.KLV, 1000), 10, *mxftest.NewSpecification())
+	doc.Seek(0, 0)
+
+	var buf bytes.Buffer
+	testErr := mxftest.MRXTest(doc, &buf, st377.Specifications())
+	doc.Close()
+
+	var report mxftest.Report
+	repErr := yaml.Unmarshal(buf.Bytes(), &report)
+
+	dbPath := filepath.Join(t.TempDir(), "mxf.db")
+	db, openErr := Open(dbPath)
+	// export the file twice, the second time with its test report
+	firstID, firstErr := Export(db, mxfFile, ast, nil)
+	secondID, secondErr := Export(db, mxfFile, ast, &report)
+
+	// nodes referenced by more than one group are only exported once
+	nodes := make(map[*mxftest.Node]bool)
+	essence := 0
+	walkErr := mxftest.Walk(ast, mxftest.Visitor{Enter: func(item mxftest.WalkItem) error {
+		switch item.Kind {
+		case mxftest.WalkMetadata:
+			nodes[item.Node] = true
+		case mxftest.WalkEssence:
+			essence++
+		}
+
+		return nil
+	}})
+
+	checks := 0
+	for _, section := range report.Tests {
+		for _, test := range section.Tests {
+			checks += len(test.Checks)
+		}
+	}
+
+	Convey("Checking an AST can be exported to SQLite", t, func() {
+		Convey(fmt.Sprintf("exporting %s twice to a new database", mxfFile), func() {
+			Convey("the file is written with a different file_id each time", func() {
+				So(docErr, ShouldBeNil)
+				So(genErr, ShouldBeNil)
+				So(walkErr, ShouldBeNil)
+				So(testErr, ShouldBeNil)
+				So(repErr, ShouldBeNil)
+				So(openErr, ShouldBeNil)
+				So(firstErr, ShouldBeNil)
+				So(secondErr, ShouldBeNil)
+				So(firstID, ShouldNotEqual, secondID)
+				So(checks, ShouldBeGreaterThan, 0)
+			})
+		})
+	})
+
+	type count struct {
+		query    string
+		expected any
+	}
+
+	counts := []count{
+		{query: "select count(*) from files", expected: 2},
+		{query: "select count(*) from files where pass is null", expected: 1},
+		{query: "select count(*) from partitions where file_id = ?", expected: len(ast.Partitions)},
+		{query: "select count(*) from partitions where file_id = ? and type = 'body'", expected: 1},
+		{query: "select count(*) from metadata where file_id = ?", expected: len(nodes)},
+		{query: "select count(*) from metadata where file_id = ? and path = '/partition[1]/metadata/Preface[1]'", expected: 1},
+		{query: "select count(*) from metadata where file_id = ? and parent_id is not null and parent_id not in (select node_id from metadata where file_id = ?)", expected: 0},
+		{query: "select count(*) from metadata_references where file_id = ? and child_id not in (select node_id from metadata where file_id = ?)", expected: 0},
+		{query: "select count(*) from essence where file_id = ?", expected: essence},
+		{query: "select count(*) from essence where file_id = ? and partition = 1", expected: 24},
+		{query: `select count(*) from metadata join properties on properties.file_id = metadata.file_id and properties.node_id = metadata.node_id
+			where metadata.file_id = ? and metadata.partition = 0 and metadata.symbol = 'TimelineTrack' and properties.name = 'EditRate' and properties.value = '24/1'`, expected: 3},
+		{query: "select count(*) from tests where file_id = ?", expected: checks},
+		{query: "select count(*) from tests where file_id = ? and pass = 0", expected: 0},
+	}
+
+	for _, c := range counts {
+		var args []any
+		for range bytes.Count([]byte(c.query), []byte("?")) {
+			args = append(args, secondID)
+		}
+
+		var found int
+		err := db.QueryRow(c.query, args...).Scan(&found)
+
+		Convey("Checking the exported tables can be queried with SQL", t, func() {
+			Convey(fmt.Sprintf("running %s", c.query), func() {
+				Convey(fmt.Sprintf("the count is %v", c.expected), func() {
+					So(err, ShouldBeNil)
+					So(found, ShouldEqual, c.expected)
+				})
+			})
+		})
+	}
+
+	closeErr := db.Close()
+	// reopening the database keeps the exported files
+	reopened, reopenErr := Open(dbPath)
+	var files int
+	filesErr := reopened.QueryRow("select count(*) from files").Scan(&files)
+	reopened.Close()
+
+	Convey("Checking exported files are kept in the database", t, func() {
+		Convey("closing and opening the database again", func() {
+			Convey("both files are still in the database", func() {
+				So(closeErr, ShouldBeNil)
+				So(reopenErr, ShouldBeNil)
+				So(filesErr, ShouldBeNil)
+				So(files, ShouldEqual, 2)
+			})
+		})
+	})
+}
@@ -0,0 +1,268 @@
+package mxftest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Table is the tabular result of a query,
+// with a row for every result and a column for every selected field.
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// Column returns the values of a column of the table,
+// the column name is matched without case.
+func (t Table) Column(name string) ([]string, bool) {
+	for i, col := range t.Columns {
+		if !strings.EqualFold(col, name) {
+			continue
+		}
+
+		values := make([]string, len(t.Rows))
+		for j, row := range t.Rows {
+			values[j] = row[i]
+		}
+
+		return values, true
+	}
+
+	return nil, false
+}
+
+/*
+Query returns a table of the fields of the children of a node,
+using the same fields and conditions as Search.
+
+e.g. select distinct ul, sniff:/* where sniff:/* <> tt
+
+The selected columns can be any field, or count(*) for the count of the results.
+Fields with several values have the values separated by commas.
+distinct removes any repeated rows, and group by gives a row for
+every unique set of values of the grouped fields.
+e.g. select ul, count(*) group by ul
+*/
+func (n Node) Query(searchfield string) (Table, error) {
+	q, err := parseQuery(searchfield)
+	if err != nil {
+		return Table{}, err
+	}
+
+	return n.tabulate(q)
+}
+
+// tabulate generates the table of a parsed query of the children of the node
+func (n Node) tabulate(q *query) (Table, error) {
+	if q.table != "" {
+		return Table{}, &SyntaxError{Query: q.text, Column: q.tableColumn, Msg: "nodes do not have tables, remove the from statement"}
+	}
+
+	return runQuery(q, searchNodes(n.Children, func(*Node) bool { return true }), nodeField)
+}
+
+/*
+Query returns a table of the fields of the essence or metadata of a partition,
+using the same tables, fields and conditions as Search.
+Every node of the table is queried, not just the top level nodes.
+
+e.g. select ul, count(*) from essence group by ul
+
+See Node.Query for the selected columns.
+*/
+func (p PartitionNode) Query(searchfield string) (Table, error) {
+	q, err := parseQuery(searchfield)
+	if err != nil {
+		return Table{}, err
+	}
+
+	return p.tabulate(q)
+}
+
+// tabulate generates the table of a parsed query of the essence or metadata of the partition
+func (p PartitionNode) tabulate(q *query) (Table, error) {
+	searchFields, index, err := p.table(q)
+	if err != nil {
+		return Table{}, err
+	}
+
+	if index != nil {
+		return runQuery(q, index.candidates(q.where), nodeField)
+	}
+
+	return runQuery(q, searchNodes(searchFields, func(*Node) bool { return true }), nodeField)
+}
+
+/*
+Query returns a table of the fields of the partitions,
+or the essence or metadata of every partition in the file.
+
+e.g. select type, count(*) from partitions group by type
+
+The partitions table uses the same fields as MXFNode.Search. The essence and metadata
+tables join the nodes to their partitions, using the same fields as SearchNodes,
+without the table prefix for the node fields. The ul, label, offset and sniff fields
+are the fields of the nodes, the fields of the partition can be prefixed with partition,
+e.g. partition.offset is the offset of the partition of the node.
+e.g. select distinct sniff:/* from essence where type = body
+
+See Node.Query for the selected columns.
+*/
+func (m MXFNode) Query(searchfield string) (Table, error) {
+	q, err := parseQuery(searchfield)
+	if err != nil {
+		return Table{}, err
+	}
+
+	return m.tabulate(q)
+}
+
+// tabulate generates the table of a parsed query of the partitions, essence or metadata of the file
+func (m MXFNode) tabulate(q *query) (Table, error) {
+	switch q.table {
+	case "partition", "partitions":
+		return runQuery(q, m.Partitions, partitionField)
+	case "essence", "metadata":
+		return runQuery(q, m.joinNodes(q.table), joinField(q.table, true))
+	case "":
+		return Table{}, &SyntaxError{Query: q.text, Column: len([]rune(q.text)) + 1, Msg: "expected a from statement with the partitions, essence or metadata table"}
+	default:
+		return Table{}, &SyntaxError{Query: q.text, Column: q.tableColumn, Msg: fmt.Sprintf("unknown table %q", q.table)}
+	}
+}
+
+// countColumn is the aggregate column for the count of results
+const countColumn = "count(*)"
+
+// runQuery generates the table of a query from the items that can be queried.
+func runQuery[T any](q *query, items []T, fields func(name string) (queryField[T], bool)) (Table, error) {
+	match, err := compileCondition(q, q.where, fields)
+	if err != nil {
+		return Table{}, err
+	}
+
+	resolve := func(cols []column) ([]queryField[T], error) {
+		out := make([]queryField[T], len(cols))
+		for i, col := range cols {
+			if col.name == "*" {
+				return nil, &SyntaxError{Query: q.text, Column: col.column, Msg: "* can not be selected in a query, select the fields instead"}
+			}
+
+			if strings.EqualFold(col.name, countColumn) {
+				continue
+			}
+
+			field, ok := fields(col.name)
+			if !ok {
+				return nil, &SyntaxError{Query: q.text, Column: col.column, Msg: fmt.Sprintf("unknown field %q", col.name)}
+			}
+			out[i] = field
+		}
+
+		return out, nil
+	}
+
+	selected, err := resolve(q.columns)
+	if err != nil {
+		return Table{}, err
+	}
+
+	grouped, err := resolve(q.groupBy)
+	if err != nil {
+		return Table{}, err
+	}
+
+	// check every column is a count, or is grouped when there are counts or groups
+	aggregate := q.groupBy != nil
+	for _, col := range q.columns {
+		aggregate = aggregate || strings.EqualFold(col.name, countColumn)
+	}
+
+	groupPos := make(map[string]int)
+	for i, col := range q.groupBy {
+		if strings.EqualFold(col.name, countColumn) {
+			return Table{}, &SyntaxError{Query: q.text, Column: col.column, Msg: "count(*) can not be grouped by"}
+		}
+		groupPos[strings.ToLower(col.name)] = i
+	}
+
+	for _, col := range q.columns {
+		if _, ok := groupPos[strings.ToLower(col.name)]; aggregate && !ok && !strings.EqualFold(col.name, countColumn) {
+			return Table{}, &SyntaxError{Query: q.text, Column: col.column, Msg: fmt.Sprintf("%q has to be in the group by statement", col.name)}
+		}
+	}
+
+	table := Table{Columns: make([]string, len(q.columns)), Rows: make([][]string, 0)}
+	for i, col := range q.columns {
+		table.Columns[i] = col.name
+	}
+
+	cell := func(field queryField[T], item T) string {
+		return strings.Join(field.values(item), ",")
+	}
+
+	// the position of each row, for distinct and grouped rows
+	rowPos := make(map[string]int)
+	counts := make([]int, 0)
+
+	for _, item := range items {
+		if !match(item) {
+			continue
+		}
+
+		var key []string
+		if aggregate {
+			for _, field := range grouped {
+				key = append(key, cell(field, item))
+			}
+		}
+
+		row := make([]string, len(q.columns))
+		for i, col := range q.columns {
+			switch {
+			case strings.EqualFold(col.name, countColumn):
+			case aggregate:
+				row[i] = key[groupPos[strings.ToLower(col.name)]]
+			default:
+				row[i] = cell(selected[i], item)
+			}
+		}
+
+		if !aggregate && !q.distinct {
+			table.Rows = append(table.Rows, row)
+			continue
+		}
+
+		if !aggregate {
+			key = row
+		}
+
+		rowKey := strings.Join(key, "\x00")
+		pos, ok := rowPos[rowKey]
+		if !ok {
+			pos = len(table.Rows)
+			rowPos[rowKey] = pos
+			table.Rows = append(table.Rows, row)
+			counts = append(counts, 0)
+		}
+		counts[pos]++
+	}
+
+	// a count with no groups always has a row
+	if aggregate && q.groupBy == nil && len(table.Rows) == 0 {
+		table.Rows = append(table.Rows, make([]string, len(q.columns)))
+		counts = append(counts, 0)
+	}
+
+	for i, col := range q.columns {
+		if !strings.EqualFold(col.name, countColumn) {
+			continue
+		}
+
+		for j := range table.Rows {
+			table.Rows[j][i] = fmt.Sprintf("%v", counts[j])
+		}
+	}
+
+	return table, nil
+}
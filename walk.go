@@ -0,0 +1,185 @@
+package mxftest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// The kinds of item visited by Walk
+const (
+	WalkMXF       = "mxf"
+	WalkPartition = "partition"
+	WalkMetadata  = "metadata"
+	WalkIndex     = "index"
+	WalkEssence   = "essence"
+)
+
+// WalkItem is an item of the AST visited by Walk.
+// Only one of MXF, Partition or Node is set, depending on the kind of item.
+type WalkItem struct {
+	// Kind is the kind of item, e.g. WalkPartition or WalkMetadata
+	Kind      string
+	MXF       *MXFNode
+	Partition *PartitionNode
+	Node      *Node
+	// Path is the path to the item from the MXF node, with the
+	// position of the item in its parent starting at 1, metadata
+	// is numbered among the groups of the same type as in SearchPath,
+	// e.g. /partition[1]/metadata/Preface[1]/ContentStorage[1]
+	Path string
+	// Parents are the items the item is found in,
+	// starting with the MXF node and ending with the direct parent.
+	Parents []WalkItem
+}
+
+// Parent returns the direct parent of the item,
+// false is returned for the MXF node which has no parent.
+func (w WalkItem) Parent() (WalkItem, bool) {
+	if len(w.Parents) == 0 {
+		return WalkItem{}, false
+	}
+
+	return w.Parents[len(w.Parents)-1], true
+}
+
+// Visitor contains the functions Walk calls for each item of the AST.
+// Either function can be nil.
+type Visitor struct {
+	// Enter is called before the children of the item are visited.
+	// Returning SkipChildren skips the children of the item,
+	// SkipAll stops the walk and any other error stops the walk and is
+	// returned by Walk.
+	Enter func(item WalkItem) error
+	// Leave is called after the children of the item are visited,
+	// or skipped. The errors are handled the same as Enter.
+	Leave func(item WalkItem) error
+}
+
+var (
+	// SkipChildren is returned by a Visitor to skip the children of an item
+	SkipChildren = errors.New("skip the children of this item")
+	// SkipAll is returned by a Visitor to stop the walk without an error
+	SkipAll = errors.New("skip everything remaining")
+)
+
+/*
+Walk visits every item of the AST depth first, calling the Enter function of the visitor
+before the children of an item are visited and Leave after.
+
+The items are visited in the order:
+
+  - the MXF node
+  - each partition, then for each partition
+  - the header metadata, following the strong references from parent to child
+  - the index table
+  - the essence
+
+Every item has a path and the chain of parents from the MXF node,
+so the items can be traced back up the tree.
+*/
+func Walk(mxf *MXFNode, visitor Visitor) error {
+	err := walkItem(WalkItem{Kind: WalkMXF, MXF: mxf, Path: "/"}, visitor)
+	if errors.Is(err, SkipAll) {
+		return nil
+	}
+
+	return err
+}
+
+// walkItem visits an item and then its children
+func walkItem(item WalkItem, visitor Visitor) error {
+	if visitor.Enter != nil {
+		err := visitor.Enter(item)
+		switch {
+		case errors.Is(err, SkipChildren):
+			return leave(item, visitor)
+		case err != nil:
+			return err
+		}
+	}
+
+	parents := append(slices.Clip(item.Parents), item)
+	for _, child := range walkChildren(item) {
+		child.Parents = slices.Clip(parents)
+		// the MXF path is the root "/"
+		if item.Kind != WalkMXF {
+			child.Path = item.Path + child.Path
+		}
+
+		if err := walkItem(child, visitor); err != nil {
+			return err
+		}
+	}
+
+	return leave(item, visitor)
+}
+
+// leave calls the leave function of the visitor
+func leave(item WalkItem, visitor Visitor) error {
+	if visitor.Leave == nil {
+		return nil
+	}
+
+	err := visitor.Leave(item)
+	if errors.Is(err, SkipChildren) {
+		return nil
+	}
+
+	return err
+}
+
+// walkChildren returns the children of an item, with the
+// path of each child relative to the item.
+func walkChildren(item WalkItem) []WalkItem {
+	children := make([]WalkItem, 0)
+
+	switch item.Kind {
+	case WalkMXF:
+		for i, part := range item.MXF.Partitions {
+			children = append(children, WalkItem{Kind: WalkPartition, Partition: part, Path: fmt.Sprintf("/partition[%v]", i+1)})
+		}
+	case WalkPartition:
+		part := item.Partition
+		children = append(children, metadataChildren(part.HeaderMetadata, "/metadata", item.Parents)...)
+
+		if part.IndexTable != nil {
+			children = append(children, WalkItem{Kind: WalkIndex, Node: part.IndexTable, Path: "/index"})
+		}
+
+		for i, ess := range part.Essence {
+			if ess != nil {
+				children = append(children, WalkItem{Kind: WalkEssence, Node: ess, Path: fmt.Sprintf("/essence[%v]", i+1)})
+			}
+		}
+	case WalkMetadata:
+		children = metadataChildren(item.Node.Children, "", append(slices.Clip(item.Parents), item))
+	}
+
+	return children
+}
+
+// metadataChildren returns the metadata nodes as walk items, skipping any
+// nodes that are already a parent, so references that loop are not followed.
+func metadataChildren(nodes []*Node, prefix string, parents []WalkItem) []WalkItem {
+	children := make([]WalkItem, 0, len(nodes))
+	for _, n := range nodes {
+		if n == nil || slices.ContainsFunc(parents, func(p WalkItem) bool { return p.Node == n }) {
+			continue
+		}
+
+		children = append(children, WalkItem{Kind: WalkMetadata, Node: n, Path: fmt.Sprintf("%s/%s[%v]", prefix, walkName(n), pathPosition(nodes, n))})
+	}
+
+	return children
+}
+
+// walkName is the name of a metadata node in a path,
+// which is the symbol of its group, or the UL if the group is unknown.
+func walkName(n *Node) string {
+	if group, ok := nodeGroup(n); ok {
+		return group.Name
+	}
+
+	return n.Properties.UL()
+}
@@ -0,0 +1,138 @@
+package mxftest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/metarex-media/mrx-tool/klv"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWalk(t *testing.T) {
+	doc, docErr := os.Open("./testdata/demoReports/goodISXD.mxf")
+	ast, genErr := MakeAST(doc, make(chan *klv.KLV, 1000), 10, *NewSpecification())
+	doc.Close()
+
+	kinds := make(map[string]int)
+	paths := make(map[string]WalkItem)
+	depth, maxDepth, balanced := 0, 0, true
+	walkErr := Walk(ast, Visitor{
+		Enter: func(item WalkItem) error {
+			kinds[item.Kind]++
+			paths[item.Path] = item
+			if len(item.Parents) != depth {
+				balanced = false
+			}
+			depth++
+			maxDepth = max(maxDepth, depth)
+
+			return nil
+		},
+		Leave: func(item WalkItem) error {
+			depth--
+			if len(item.Parents) != depth {
+				balanced = false
+			}
+
+			return nil
+		}})
+
+	var metadata, essence int
+	for _, part := range ast.Partitions {
+		metadata += len(searchNodes(part.HeaderMetadata, func(*Node) bool { return true }))
+		essence += len(part.Essence)
+	}
+
+	Convey("Checking every item of an AST is walked", t, func() {
+		Convey("walking goodISXD.mxf and counting the items by kind", func() {
+			Convey("every partition, metadata node and essence node is visited once, with the parents of each item", func() {
+				So(docErr, ShouldBeNil)
+				So(genErr, ShouldBeNil)
+				So(walkErr, ShouldBeNil)
+				So(kinds[WalkMXF], ShouldEqual, 1)
+				So(kinds[WalkPartition], ShouldEqual, len(ast.Partitions))
+				So(kinds[WalkMetadata], ShouldEqual, metadata)
+				So(kinds[WalkEssence], ShouldEqual, essence)
+				So(balanced, ShouldBeTrue)
+				So(depth, ShouldEqual, 0)
+				So(maxDepth, ShouldBeGreaterThan, 4)
+			})
+		})
+	})
+
+	Convey("Checking the paths of the walked items", t, func() {
+		Convey("walking goodISXD.mxf and finding the content storage by its path", func() {
+			Convey("the item is the content storage of the preface, with the preface as its parent", func() {
+				item, ok := paths["/partition[1]/metadata/Preface[1]/ContentStorage[1]"]
+				So(ok, ShouldBeTrue)
+				So(item.Kind, ShouldEqual, WalkMetadata)
+				So(item.Node, ShouldEqual, ast.Partitions[0].HeaderMetadata[1].Children[0])
+
+				parent, ok := item.Parent()
+				So(ok, ShouldBeTrue)
+				So(parent.Node, ShouldEqual, ast.Partitions[0].HeaderMetadata[1])
+				So(item.Parents[0].MXF, ShouldEqual, ast)
+				So(item.Parents[1].Partition, ShouldEqual, ast.Partitions[0])
+
+				_, ok = paths["/"].Parent()
+				So(ok, ShouldBeFalse)
+			})
+		})
+	})
+
+	stopErr := fmt.Errorf("stop")
+	walks := []struct {
+		name     string
+		enter    func(item WalkItem) error
+		expected map[string]int
+		err      error
+	}{
+		{name: "skipping the children of every partition", enter: func(item WalkItem) error {
+			if item.Kind == WalkPartition {
+				return SkipChildren
+			}
+			return nil
+		}, expected: map[string]int{WalkMXF: 1, WalkPartition: len(ast.Partitions)}},
+		{name: "skipping everything after the first partition", enter: func(item WalkItem) error {
+			if item.Kind == WalkPartition {
+				return SkipAll
+			}
+			return nil
+		}, expected: map[string]int{WalkMXF: 1, WalkPartition: 1}},
+		{name: "stopping with an error at the first metadata node", enter: func(item WalkItem) error {
+			if item.Kind == WalkMetadata {
+				return stopErr
+			}
+			return nil
+		}, expected: map[string]int{WalkMXF: 1, WalkPartition: 1, WalkMetadata: 1}, err: stopErr},
+	}
+
+	for _, walk := range walks {
+		entered := make(map[string]int)
+		left := 0
+		err := Walk(ast, Visitor{
+			Enter: func(item WalkItem) error {
+				entered[item.Kind]++
+				return walk.enter(item)
+			},
+			Leave: func(item WalkItem) error {
+				left++
+				return nil
+			}})
+
+		Convey("Checking walks can skip items", t, func() {
+			Convey(fmt.Sprintf("walking goodISXD.mxf %s", walk.name), func() {
+				Convey("only the expected items are visited", func() {
+					So(errors.Is(err, walk.err), ShouldBeTrue)
+					So(entered, ShouldResemble, walk.expected)
+					if walk.err == nil && !strings.Contains(walk.name, "everything") {
+						So(left, ShouldEqual, 1+len(ast.Partitions))
+					}
+				})
+			})
+		})
+	}
+}
@@ -43,7 +43,7 @@ func xMLIdentifier(data []byte) bool {
 }
 
 // DataIdentifier is the xml identifier function
-var DataIdentifier = mxftest.DataIdentifier{DataFunc: xMLIdentifier, ContentType: Content}
+var DataIdentifier = mxftest.DataIdentifier{DataFunc: xMLIdentifier, ContentType: Content, ID: "github.com/metarex-media/mxf-test/xmlhandle"}
 
 type contKey struct {
 	path, functionName string
@@ -80,6 +80,11 @@ func PathSniffer(sc mxftest.SniffContext, path string) mxftest.Sniffer {
 			out := xmlquery.FindOne(doc, "/*")
 
 			if out != nil {
+				// the namespace of prefixed roots e.g. <tt:tt xmlns:tt="...">
+				if out.NamespaceURI != "" {
+					return mxftest.SniffResult{Key: path, Field: out.NamespaceURI, Certainty: 100}
+				}
+
 				// loop through the attributes searching for xmlns
 				for _, attr := range out.Attr {
 					if attr.Name.Local == "xmlns" {
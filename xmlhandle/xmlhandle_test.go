@@ -11,7 +11,7 @@ import (
 )
 
 var (
-	goodFiles = []string{"./testdata/goodxml/cd_catalog.xml", "./testdata/goodxml/simple.xml", "./testdata/goodxml/ttml.xml"}
+	goodFiles = []string{"./testdata/goodxml/cd_catalog.xml", "./testdata/goodxml/simple.xml", "./testdata/goodxml/ttml.xml", "./testdata/goodxml/ebutt.xml"}
 	badFiles  = []string{"./testdata/badxml/bad.json", "./testdata/badxml/note_error.xml", "./testdata/badxml/simple.xml"}
 )
 
@@ -70,9 +70,9 @@ func TestSniffPath(t *testing.T) {
 	nameSpaceFinder := PathSniffer(sc, "namespace-uri(/*)")
 	nsf := *nameSpaceFinder
 
-	expectedNameSpace := []string{"", "example.com", "http://www.w3.org/ns/ttml"}
+	expectedNameSpace := []string{"", "example.com", "http://www.w3.org/ns/ttml", "http://www.w3.org/ns/ttml"}
 
-	expectedRoot := []string{"CATALOG", "breakfast_menu", "tt"}
+	expectedRoot := []string{"CATALOG", "breakfast_menu", "tt", "tt"}
 
 	for i, f := range goodFiles {
 		path, _ := filepath.Abs(f)